@@ -1,7 +1,10 @@
 package webservice
 
 import (
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/licenses"
+	"github.com/Scalingo/sclng-backend-test-v1/common/linguist"
 )
 
 // Conversions
@@ -9,46 +12,328 @@ import (
 //  Conversion I2E represents the conversion of types from interfaces to entities layers.
 
 // convertRepoListE2I converts a RepoList from entities to RepoList from interfaces
-func convertRepoListE2I(in entities.RepoList) []RepoItem {
+// fields is the sparse fieldset of the items (nil for all fields)
+func convertRepoListE2I(in entities.RepoList, fields []string) []RepoItem {
 	out := make([]RepoItem, len(in))
 	for i, v := range in {
-		out[i] = convertRepoItemE2I(v)
+		out[i] = convertRepoItemE2I(v, fields)
 	}
 	return out
 }
 
-func convertRepoItemE2I(in entities.RepoItem) RepoItem {
+// convertRepoPageE2I converts a RepoPage from entities to RepoList from interfaces
+// fields is the sparse fieldset of the items (nil for all fields)
+// The search hits of a full-text search are added to their items
+func convertRepoPageE2I(in entities.RepoPage, fields []string) RepoList {
+	items := convertRepoListE2I(in.Items, fields)
+	if in.Hits != nil {
+		for i := range items {
+			if hit, ok := in.Hits[items[i].ID]; ok {
+				score := hit.Score
+				items[i].Score = &score
+				items[i].Highlights = hit.Highlights
+			}
+		}
+	}
+	return RepoList{
+		Items:      items,
+		NextCursor: in.NextCursor,
+		Total:      in.Total,
+		Facets:     convertFacetsE2I(in.Facets),
+	}
+}
+
+// convertRepoItemE2I converts a RepoItem from entities to RepoItem from interfaces
+// fields is the sparse fieldset of the item (nil for all fields)
+func convertRepoItemE2I(in entities.RepoItem, fields []string) RepoItem {
 	return RepoItem{
-		ID:              in.ID,
-		Name:            in.Name,
-		FullName:        in.FullName,
-		Owner:           in.Owner,
-		HTMLUrl:         in.HTMLUrl,
-		Description:     in.Description,
-		LanguagesURL:    in.LanguagesURL,
-		CreatedAt:       in.CreatedAt,
-		UpdatedAt:       in.UpdatedAt,
-		Size:            in.Size,
-		Language:        in.Language,
-		Languages:       convertLanguagesE2I(in.Languages),
-		LicenseName:     in.LicenseName,
-		ForksCount:      in.ForksCount,
-		OpenIssuesCount: in.OpenIssuesCount,
-		WatchersCount:   in.WatchersCount,
-		AllowForking:    in.AllowForking,
-		HasIssues:       in.HasIssues,
-		HasProjects:     in.HasProjects,
-		HasDownloads:    in.HasDownloads,
-		HasWiki:         in.HasWiki,
-		HasPages:        in.HasPages,
-		HasDiscussions:  in.HasDiscussions,
-	}
-}
-
-func convertLanguagesE2I(in entities.Languages) Languages {
+		ID:               in.ID,
+		Name:             in.Name,
+		FullName:         in.FullName,
+		Owner:            in.Owner,
+		HTMLUrl:          in.HTMLUrl,
+		Description:      in.Description,
+		LanguagesURL:     in.LanguagesURL,
+		CreatedAt:        in.CreatedAt,
+		UpdatedAt:        in.UpdatedAt,
+		Size:             in.Size,
+		Language:         in.Language,
+		Languages:        convertLanguagesE2I(in.Languages, in.LanguagePercentages),
+		LanguageCount:    in.LanguageCount,
+		DominantLanguage: in.DominantLanguage,
+		LanguageDerived:  in.LanguageDerived,
+		LicenseName:      in.LicenseName,
+		LicenseKey:       in.LicenseKey,
+		LicenseFamily:    string(licenses.FamilyOf(in.LicenseKey)),
+		ForksCount:       in.ForksCount,
+		OpenIssuesCount:  in.OpenIssuesCount,
+		WatchersCount:    in.WatchersCount,
+		AllowForking:     in.AllowForking,
+		HasIssues:        in.HasIssues,
+		HasProjects:      in.HasProjects,
+		HasDownloads:     in.HasDownloads,
+		HasWiki:          in.HasWiki,
+		HasPages:         in.HasPages,
+		HasDiscussions:   in.HasDiscussions,
+		Quality:          convertQualityE2I(in.Quality),
+		fields:           fields,
+	}
+}
+
+// convertQualityE2I converts a Quality from entities to Quality from interfaces
+func convertQualityE2I(in entities.Quality) Quality {
+	out := Quality{Score: in.Score, Factors: make([]QualityFactor, len(in.Factors))}
+	for i, v := range in.Factors {
+		out.Factors[i] = QualityFactor{Name: v.Name, Weight: v.Weight, Value: v.Value, Points: v.Points}
+	}
+	return out
+}
+
+// convertLanguagesE2I converts a languages breakdown, with the percentage of the bytes of each language stored with it
+// (see db.WithLanguages)
+func convertLanguagesE2I(in entities.Languages, percentages map[string]float64) Languages {
 	out := make(Languages)
 	for k, v := range in {
-		out[k] = Language{Bytes: v}
+		out[k] = Language{Bytes: v, Percent: percentages[k], LanguageMeta: convertLanguageMetaE2I(k)}
+	}
+	return out
+}
+
+// convertFacetsE2I converts the Facets from entities to the facets from interfaces
+func convertFacetsE2I(in entities.Facets) map[string]map[string]int {
+	if in == nil {
+		return nil
+	}
+	out := make(map[string]map[string]int, len(in))
+	for field, counts := range in {
+		out[field] = counts
+	}
+	return out
+}
+
+// convertSuggestionsE2I converts a list of Suggestion from entities to Suggestions from interfaces
+func convertSuggestionsE2I(in []entities.Suggestion) Suggestions {
+	out := Suggestions{Suggestions: make([]Suggestion, len(in))}
+	for i, v := range in {
+		out.Suggestions[i] = Suggestion{Value: v.Value, Count: v.Count}
+	}
+	return out
+}
+
+// convertAggregateE2I converts the groups of an aggregation from entities to Aggregate from interfaces
+func convertAggregateE2I(groupBy string, in []entities.AggregateGroup) Aggregate {
+	out := Aggregate{GroupBy: groupBy, Groups: make([]AggregateGroup, len(in))}
+	for i, v := range in {
+		out.Groups[i] = AggregateGroup{Value: v.Value, Metrics: v.Metrics}
+	}
+	return out
+}
+
+// convertLanguageStatsE2I converts LanguageStats from entities to LanguageStats from interfaces
+func convertLanguageStatsE2I(in entities.LanguageStats) LanguageStats {
+	out := LanguageStats{
+		TotalBytes: in.TotalBytes,
+		Repos:      in.Repos,
+		Languages:  make([]LanguageStat, len(in.Languages)),
+	}
+	for i, v := range in.Languages {
+		out.Languages[i] = LanguageStat{
+			Language:     v.Language,
+			Bytes:        v.Bytes,
+			Share:        v.Share,
+			Repos:        v.Repos,
+			MeanShare:    v.MeanShare,
+			LanguageMeta: convertLanguageMetaE2I(v.Language),
+		}
+	}
+	return out
+}
+
+// convertLicenseStatsE2I converts LicenseStats from entities to LicenseStats from interfaces
+func convertLicenseStatsE2I(in entities.LicenseStats) LicenseStats {
+	out := LicenseStats{
+		Total:    in.Total,
+		Licenses: make([]LicenseCount, len(in.Licenses)),
+		Families: make([]FamilyCount, len(in.Families)),
+	}
+	for i, v := range in.Licenses {
+		out.Licenses[i] = LicenseCount(v)
+	}
+	for i, v := range in.Families {
+		out.Families[i] = FamilyCount(v)
+	}
+	return out
+}
+
+// convertLanguageCooccurrenceE2I converts LanguageCooccurrence from entities to LanguageCooccurrence from interfaces
+func convertLanguageCooccurrenceE2I(in entities.LanguageCooccurrence) LanguageCooccurrence {
+	out := LanguageCooccurrence{Repos: in.Repos, Pairs: make([]LanguagePair, len(in.Pairs))}
+	for i, v := range in.Pairs {
+		out.Pairs[i] = LanguagePair{Languages: [2]string{v.A, v.B}, Count: v.Count, Lift: v.Lift, Jaccard: v.Jaccard}
+	}
+	return out
+}
+
+// languageTopRepoFields is the sparse fieldset of the top repositories of a language
+var languageTopRepoFields = []string{"id", "name", "full_name", "owner", "html_url", "language", "watchers_count"}
+
+// convertLanguageListE2I converts a list of LanguageSummary from entities to LanguageList from interfaces
+func convertLanguageListE2I(in []entities.LanguageSummary) LanguageList {
+	out := LanguageList{Languages: make([]LanguageSummary, len(in))}
+	for i, v := range in {
+		out.Languages[i] = convertLanguageSummaryE2I(v)
+	}
+	return out
+}
+
+// convertLanguageSummaryE2I converts a LanguageSummary from entities to LanguageSummary from interfaces
+func convertLanguageSummaryE2I(in entities.LanguageSummary) LanguageSummary {
+	return LanguageSummary{
+		Name:         in.Name,
+		PrimaryRepos: in.PrimaryRepos,
+		Repos:        in.Repos,
+		Bytes:        in.Bytes,
+		AvgSize:      in.AvgSize,
+		LanguageMeta: convertLanguageMetaE2I(in.Name),
+	}
+}
+
+// convertLanguageMetaE2I returns the metadata of a language from the Linguist table (empty for unknown languages)
+func convertLanguageMetaE2I(name string) LanguageMeta {
+	language, ok := linguist.Lookup(name)
+	if !ok {
+		return LanguageMeta{}
+	}
+	return LanguageMeta{Color: language.Color, Type: string(language.Type), Family: language.Family}
+}
+
+// convertLanguageDetailE2I converts LanguageDetail from entities to LanguageDetail from interfaces
+func convertLanguageDetailE2I(in entities.LanguageDetail) LanguageDetail {
+	return LanguageDetail{
+		LanguageSummary: convertLanguageSummaryE2I(in.Summary),
+		TopRepositories: convertRepoListE2I(in.TopRepos, languageTopRepoFields),
+	}
+}
+
+// convertOwnersE2I converts a list of OwnerSummary from entities to Owners from interfaces
+func convertOwnersE2I(in []entities.OwnerSummary, total int) Owners {
+	out := Owners{Owners: make([]OwnerSummary, len(in)), Total: total}
+	for i, v := range in {
+		out.Owners[i] = OwnerSummary(v)
+	}
+	return out
+}
+
+// convertOwnerE2I converts Owner from entities to Owner from interfaces
+func convertOwnerE2I(in entities.Owner) Owner {
+	return Owner{
+		OwnerSummary: OwnerSummary(in.Summary),
+		Repositories: convertRepoListE2I(in.Items, nil),
+		Languages:    convertLanguageStatsE2I(in.Languages),
+	}
+}
+
+// convertSimilarReposE2I converts the similar repositories from entities to SimilarRepos from interfaces
+func convertSimilarReposE2I(filters usecases.SimilarFilters, in []entities.SimilarRepo) SimilarRepos {
+	out := SimilarRepos{
+		ID:            filters.ID,
+		LicenseWeight: filters.LicenseWeight,
+		SizeWeight:    filters.SizeWeight,
+		Repositories:  make([]SimilarRepo, len(in)),
+	}
+	for i, v := range in {
+		out.Repositories[i] = SimilarRepo{
+			Rank:               i + 1,
+			ID:                 v.Item.ID,
+			Name:               v.Item.Name,
+			FullName:           v.Item.FullName,
+			Owner:              v.Item.Owner,
+			HTMLUrl:            v.Item.HTMLUrl,
+			Language:           v.Item.Language,
+			LicenseKey:         v.Item.LicenseKey,
+			Size:               v.Item.Size,
+			Score:              v.Score,
+			LanguageSimilarity: v.LanguageSimilarity,
+		}
+	}
+	return out
+}
+
+// convertLeaderboardE2I converts the entries of a leaderboard from entities to Leaderboard from interfaces
+func convertLeaderboardE2I(filters usecases.LeaderboardFilters, in []entities.LeaderboardEntry) Leaderboard {
+	out := Leaderboard{Metric: filters.Metric, Repositories: make([]LeaderboardEntry, len(in))}
+	if filters.Language != nil {
+		out.Language = *filters.Language
+	}
+	for i, v := range in {
+		out.Repositories[i] = LeaderboardEntry{
+			Rank:     i + 1,
+			ID:       v.Item.ID,
+			Name:     v.Item.Name,
+			FullName: v.Item.FullName,
+			Owner:    v.Item.Owner,
+			HTMLUrl:  v.Item.HTMLUrl,
+			Language: v.Item.Language,
+			Value:    v.Value,
+		}
+	}
+	return out
+}
+
+// convertLanguageTrendsE2I converts LanguageTrends from entities to LanguageTrends from interfaces
+func convertLanguageTrendsE2I(in entities.LanguageTrends) LanguageTrends {
+	out := LanguageTrends{
+		Window:        in.Window.String(),
+		Start:         in.Start,
+		Repos:         in.Repos,
+		PreviousRepos: in.PreviousRepos,
+		Languages:     make([]LanguageTrend, len(in.Languages)),
+	}
+	for i, v := range in.Languages {
+		out.Languages[i] = LanguageTrend(v)
+	}
+	return out
+}
+
+// convertDistributionsE2I converts Distributions from entities to Distributions from interfaces
+func convertDistributionsE2I(in entities.Distributions) Distributions {
+	out := Distributions{Languages: make(map[string]map[string]Distribution, len(in.ByLanguage))}
+	for language, byField := range in.ByLanguage {
+		out.Languages[language] = make(map[string]Distribution, len(byField))
+		for field, d := range byField {
+			var histogram []HistogramBucket
+			if d.Histogram != nil {
+				histogram = make([]HistogramBucket, len(d.Histogram))
+				for i, b := range d.Histogram {
+					histogram[i] = HistogramBucket(b)
+				}
+			}
+			out.Languages[language][field] = Distribution{
+				Count:     d.Count,
+				Min:       d.Min,
+				Max:       d.Max,
+				Median:    d.Median,
+				P90:       d.P90,
+				P99:       d.P99,
+				Histogram: histogram,
+			}
+		}
+	}
+	for _, b := range in.CreatedAt {
+		out.CreatedAt = append(out.CreatedAt, TimeBucket(b))
+	}
+	return out
+}
+
+// convertStatsHistoryE2I converts the points of a series of the stats history from entities to StatsHistory from
+// interfaces
+func convertStatsHistoryE2I(filters usecases.HistoryFilters, in []entities.HistoryPoint) StatsHistory {
+	out := StatsHistory{Metric: filters.Metric, Language: filters.Language, Points: make([]HistoryPoint, len(in))}
+	if filters.Step > 0 {
+		out.Step = filters.Step.String()
+	}
+	for i, v := range in {
+		out.Points[i] = HistoryPoint(v)
 	}
 	return out
 }
@@ -0,0 +1,76 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// aggregateHandler returns a handler that responds with the metrics of the repositories grouped by a field
+// it accepts the filters of /stats, and the following query parameters:
+// - group_by: string (language, license, owner or a boolean flag such as allow_forking. Required)
+// - metrics: string (a comma separated list of count and <func>:<field>, e.g. "count,avg:forks_count,max:size".
+// func is one of sum, avg, min and max, and field one of size, forks_count, watchers_count and open_issues_count.
+// Default count)
+// it returns a JSON object containing the metrics of each group, ordered by value
+func (ws Webservice) aggregateHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewAggregateFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iAggregate, ok := ws.aggregateCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				groups, err := ws.uc.Aggregate(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to aggregate repositories")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the groups from the types used in the entities layer to those in the interfaces layer
+				iAggregate = convertAggregateE2I(filters.GroupBy, groups)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.aggregateCache[cacheKey] = iAggregate
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iAggregate)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
@@ -0,0 +1,74 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// cooccurrenceHandler returns a handler that responds with the pairs of languages used together in the repositories
+// it accepts the filters of /stats, and the following query parameters:
+// - min_bytes: int (the number of bytes from which a language counts in a repository. Default 0)
+// it returns a JSON object containing the number of repositories using each pair of languages, with its lift and
+// Jaccard index
+func (ws Webservice) cooccurrenceHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewCooccurrenceFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iCooccurrence, ok := ws.cooccurrenceCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				cooccurrence, err := ws.uc.GetLanguageCooccurrence(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get language co-occurrence")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the pairs from the types used in the entities layer to those in the interfaces layer
+				iCooccurrence = convertLanguageCooccurrenceE2I(cooccurrence)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.cooccurrenceCache[cacheKey] = iCooccurrence
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iCooccurrence)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
@@ -0,0 +1,75 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// distributionHandler returns a handler that responds with the distributions of the numeric fields by language
+// it accepts the filters of /stats, and the following query parameters:
+// - fields: string (a comma separated list of size, forks_count, watchers_count and open_issues_count. Default all)
+// - buckets: string (the ascending lower bounds of the histogram buckets. Default "0,10,100,1000,10000")
+// - created_interval: string (hour, day or month: adds the number of repositories created in each interval)
+// it returns a JSON object containing the count, min, max, median, p90, p99 and histogram of each field by language
+func (ws Webservice) distributionHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewDistributionFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iDistributions, ok := ws.distributionCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				distributions, err := ws.uc.GetDistributions(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get distributions")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the distributions from the types used in the entities layer to those in the interfaces layer
+				iDistributions = convertDistributionsE2I(distributions)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.distributionCache[cacheKey] = iDistributions
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iDistributions)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
@@ -0,0 +1,76 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// historyHandler returns a handler that responds with a series of the stats history recorded by the worker
+// it accepts the following query parameters:
+// - metric: string (required: num_repos, avg_forks, avg_open_issues or avg_size)
+// - language: string (required: the primary language of the series)
+// - from, to: string (an RFC3339 time or a duration before now. Default the last 24 hours)
+// - step: string (a duration: the points of each step are averaged. Default the recorded points)
+// it returns a JSON object containing the points of the series in ascending time order
+func (ws Webservice) historyHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewHistoryFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iHistory, ok := ws.historyCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				points, err := ws.uc.GetStatsHistory(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get stats history")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the points from the types used in the entities layer to those in the interfaces layer
+				iHistory = convertStatsHistoryE2I(filters, points)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.historyCache[cacheKey] = iHistory
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iHistory)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
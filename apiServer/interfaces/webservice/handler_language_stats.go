@@ -0,0 +1,73 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// languageStatsHandler returns a handler that responds with the statistics of the languages breakdowns
+// - it accepts the filters of /stats
+// it returns a JSON object containing, for each language of the breakdowns, the bytes of code, the share of the bytes,
+// the number of repositories using it and its mean share within them
+func (ws Webservice) languageStatsHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewGetStatsFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iStats, ok := ws.languageStatsCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				stats, err := ws.uc.GetLanguageStats(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get language stats")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the stats from the types used in the entities layer to those in the interfaces layer
+				iStats = convertLanguageStatsE2I(stats)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.languageStatsCache[cacheKey] = iStats
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iStats)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
@@ -0,0 +1,150 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// languagesHandler returns a handler that responds with the languages of the repositories
+// it accepts the filters of /stats
+// it returns a JSON object containing the number of repositories (primary and any), bytes and average repository size
+// of each language (primary or in the breakdowns), by descending number of repositories
+func (ws Webservice) languagesHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewGetStatsFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iLanguages, ok := ws.languagesCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				languages, err := ws.uc.GetLanguages(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get languages")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the languages from the types used in the entities layer to those in the interfaces layer
+				iLanguages = convertLanguageListE2I(languages)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.languagesCache[cacheKey] = iLanguages
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iLanguages)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
+
+// languageHandler returns a http.Handler that handles the request to get a language: /languages/{name}
+// the name is case-insensitive (e.g. /languages/go)
+// it accepts the following query parameters:
+// - limit: int (the number of top repositories, 1 to 100. Default 10)
+// it returns a JSON object containing the numbers of /languages for the language and its top repositories by
+// watchers, or a 404 if no repository uses the language
+func (ws Webservice) languageHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the name from the path
+			name := strings.TrimPrefix(r.URL.Path, "/languages/")
+			if name == "" || strings.Contains(name, "/") {
+				ws.writeError(w, http.StatusNotFound, errors.Errorf("no route for %s", r.URL.Path))
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewLanguageFilters(name, r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iLanguage, ok := ws.languageCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				language, err := ws.uc.GetLanguage(r.Context(), filters)
+				if errors.Is(err, usecases.ErrNotFound) {
+					ws.writeError(w, http.StatusNotFound, err)
+					return
+				}
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get language")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the language from the types used in the entities layer to those in the interfaces layer
+				iLanguage = convertLanguageDetailE2I(language)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.languageCache[cacheKey] = iLanguage
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iLanguage)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
@@ -0,0 +1,83 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// leaderboardHandler returns a http.Handler that handles the request to get a leaderboard: /leaderboards/{metric}
+// the metric is one of forks, watchers, size, open_issues and language_count
+// it accepts the following query parameters:
+// - language: string (only rank the repositories with this primary language, case-insensitive)
+// - limit: int (the number of repositories, 1 to 100. Default 10)
+// it returns a JSON object containing the ranked repositories, with the fields needed to display them and their value
+func (ws Webservice) leaderboardHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the metric from the path
+			metric := strings.TrimPrefix(r.URL.Path, "/leaderboards/")
+			if metric == "" || strings.Contains(metric, "/") {
+				ws.writeError(w, http.StatusNotFound, errors.Errorf("no route for %s", r.URL.Path))
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewLeaderboardFilters(metric, r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iLeaderboard, ok := ws.leaderboardCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				entries, err := ws.uc.GetLeaderboard(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get leaderboard")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the entries from the types used in the entities layer to those in the interfaces layer
+				iLeaderboard = convertLeaderboardE2I(filters, entries)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.leaderboardCache[cacheKey] = iLeaderboard
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iLeaderboard)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
@@ -0,0 +1,141 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// ownersHandler returns a handler that responds with the owners of the repositories
+// it accepts the filters of /stats, and the following query parameters:
+// - limit: int (the number of owners, 1 to 1000. Default 100)
+// it returns a JSON object containing the number of repositories, total size and dominant language of each owner,
+// by descending number of repositories
+func (ws Webservice) ownersHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewOwnersFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iOwners, ok := ws.ownersCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				owners, total, err := ws.uc.GetOwners(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get owners")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the owners from the types used in the entities layer to those in the interfaces layer
+				iOwners = convertOwnersE2I(owners, total)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.ownersCache[cacheKey] = iOwners
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iOwners)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
+
+// ownerHandler returns a http.Handler that handles the request to get an owner: /owners/{login}
+// it returns a JSON object containing the summary of the owner, their repositories (newest first) and the statistics
+// of their languages, or a 404 if the owner has no repository
+func (ws Webservice) ownerHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the login from the path
+			login := strings.TrimPrefix(r.URL.Path, "/owners/")
+			if login == "" || strings.Contains(login, "/") {
+				ws.writeError(w, http.StatusNotFound, errors.Errorf("no route for %s", r.URL.Path))
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first (logins are case-insensitive)
+			cacheKey := strings.ToLower(login)
+			ws.reposMU.Lock()
+			iOwner, ok := ws.ownerCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				owner, err := ws.uc.GetOwner(r.Context(), login)
+				if errors.Is(err, usecases.ErrNotFound) {
+					ws.writeError(w, http.StatusNotFound, err)
+					return
+				}
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get owner")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the owner from the types used in the entities layer to those in the interfaces layer
+				iOwner = convertOwnerE2I(owner)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.ownerCache[cacheKey] = iOwner
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err := json.NewEncoder(w).Encode(iOwner)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
@@ -0,0 +1,107 @@
+package webservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// repoHandler returns a http.Handler that handles the request to get a single repository: /repos/{id}
+// the requests to /repos/{id}/similar are handled by similarHandler
+// it accepts the following query parameters:
+// - fields: string (a comma separated list of the repository keys to return, e.g. "full_name,language,languages")
+// it returns a JSON object containing the repository and its languages, or a 404 if the repository does not exist
+func (ws Webservice) repoHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// The similar repositories share the /repos/ prefix
+			if strings.HasSuffix(r.URL.Path, similarPathSuffix) {
+				ws.similarHandler().ServeHTTP(w, r)
+				return
+			}
+
+			// Get the ID from the path
+			repoID, ok := ws.repoIDFromPath(w, r.URL.Path, "")
+			if !ok {
+				return
+			}
+
+			// Get the sparse fieldset
+			fields, err := usecases.ParseRepoItemFields(r.URL.Query().Get("fields"))
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := fmt.Sprintf("%d-%s", repoID, strings.Join(fields, ","))
+			ws.reposMU.Lock()
+			iItem, ok := ws.repoCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				item, err := ws.uc.GetRepoItem(r.Context(), repoID)
+				if errors.Is(err, usecases.ErrNotFound) {
+					ws.writeError(w, http.StatusNotFound, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get repository")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the item from the types used in the entities layer to those in the interfaces layer
+				iItem = convertRepoItemE2I(item, fields)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.repoCache[cacheKey] = iItem
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iItem)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
+
+// repoIDFromPath returns the {id} of a /repos/{id}{suffix} path
+// It writes a 404 if the path has another form and a 400 if the ID is not a positive integer, and returns false.
+func (ws Webservice) repoIDFromPath(w http.ResponseWriter, path, suffix string) (int64, bool) {
+	idStr := strings.TrimSuffix(strings.TrimPrefix(path, "/repos/"), suffix)
+	if idStr == "" || strings.Contains(idStr, "/") {
+		ws.writeError(w, http.StatusNotFound, errors.Errorf("no route for %s", path))
+		return 0, false
+	}
+	repoID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || repoID <= 0 {
+		ws.writeError(
+			w, http.StatusBadRequest, errors.Wrapf(usecases.ErrInvalidParameter, "id must be a positive integer, got %q", idStr),
+		)
+		return 0, false
+	}
+	return repoID, true
+}
@@ -6,16 +6,32 @@ import (
 
 	"github.com/Scalingo/go-utils/logger"
 	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
 )
 
 // repoListHandler returns a http.Handler that handles the request to get the list of repositories
 // it accepts the following query parameters:
 // - name: string
 // - language: string
-// - license: string
+// - dominant_language: string (the language of the breakdown with the most bytes)
+// - min_language_count, max_language_count: int
+// - min_quality, max_quality: int (the quality score, 0 to 100)
+// - license: string (the exact SPDX key of the license, e.g. "apache-2.0")
+// - license_name: string (a part of the license name)
+// - license_family: string (permissive, weak-copyleft, strong-copyleft, other or none)
 // - allow_forking: string
 // - has_open_issues: string
-// it returns a JSON object containing the list of repositories
+// - q: string (a search query, e.g. "language:go forks:>3 -license:mit")
+// - text: string (a full-text search over the name and description, e.g. "json parser")
+// - highlight: bool (highlight the matching words of the text search in the name and description)
+// - limit: int (the page size, default 100)
+// - cursor: string (the opaque next_cursor returned with the previous page)
+// - sort: string (created_at, forks, watchers, size, open_issues, quality or relevance. Default created_at, or
+// relevance with text)
+// - order: string (asc or desc. Default desc)
+// - facets: string (a comma separated list of language, license and owner to count the matching repositories by)
+// - fields: string (a comma separated list of the repository keys to return, e.g. "full_name,language,languages")
+// it returns a JSON object containing a page of repositories, the total count and the cursor of the next page
 func (ws Webservice) reposHandler() http.Handler {
 	return http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
@@ -27,13 +43,11 @@ func (ws Webservice) reposHandler() http.Handler {
 			}
 
 			// Get the filters from the query parameters
-			filters := usecases.NewGetRepoListFilteredFilters(
-				r.URL.Query().Get("name"),
-				r.URL.Query().Get("language"),
-				r.URL.Query().Get("license"),
-				r.URL.Query().Get("allow_forking"),
-				r.URL.Query().Get("has_open_issues"),
-			)
+			filters, err := usecases.NewGetRepoListFilteredFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
 
 			// Check the cache is valid
 			ws.checkCacheValidity()
@@ -45,17 +59,21 @@ func (ws Webservice) reposHandler() http.Handler {
 			// Cache miss
 			if !ok {
 				// Get the repository list from the usecases
-				list, err := ws.uc.GetRepoListFiltered(
+				page, err := ws.uc.GetRepoListFiltered(
 					r.Context(), filters,
 				)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
 				if err != nil {
 					logger.Get(r.Context()).WithError(err).Error("Fail to get latest 100 repositories")
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
 
-				// Convert the list from the types used in the entities layer to those in the interfaces layer
-				iList = convertRepoListE2I(list)
+				// Convert the page from the types used in the entities layer to those in the interfaces layer
+				iList = convertRepoPageE2I(page, filters.Fields)
 
 				// Store the data in the local-memory cache
 				ws.reposMU.Lock()
@@ -66,11 +84,7 @@ func (ws Webservice) reposHandler() http.Handler {
 			w.Header().Add("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 
-			err := json.NewEncoder(w).Encode(
-				RepoList{
-					Items: iList,
-				},
-			)
+			err = json.NewEncoder(w).Encode(iList)
 			if err != nil {
 				ws.log.WithError(err).Error("Fail to encode JSON")
 			}
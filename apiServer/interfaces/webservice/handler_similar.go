@@ -0,0 +1,92 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// similarPathSuffix is the suffix of the path of the similar repositories: /repos/{id}/similar
+const similarPathSuffix = "/similar"
+
+// similarHandler returns a http.Handler that handles the request to get the repositories most similar to a
+// repository: /repos/{id}/similar
+// the similarity is the cosine similarity of the normalised languages breakdowns, optionally mixed with the license
+// and the size. It accepts the following query parameters:
+// - limit: int (the number of repositories, 1 to 100. Default 10)
+// - license_weight: float (the weight of having the same license, 0 to 1. Default 0)
+// - size_weight: float (the weight of the ratio of the sizes, 0 to 1. Default 0)
+// the weights add up to at most 1, and the languages weigh the rest.
+// it returns a JSON object containing the similar repositories by descending score, or a 404 if the repository does
+// not exist. A repository without languages breakdown has no similar repositories.
+func (ws Webservice) similarHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the ID from the path
+			repoID, ok := ws.repoIDFromPath(w, r.URL.Path, similarPathSuffix)
+			if !ok {
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewSimilarFilters(repoID, r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iSimilar, ok := ws.similarCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				similar, err := ws.uc.GetSimilarRepos(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if errors.Is(err, usecases.ErrNotFound) {
+					ws.writeError(w, http.StatusNotFound, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get similar repositories")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the repositories from the types used in the entities layer to those in the interfaces layer
+				iSimilar = convertSimilarReposE2I(filters, similar)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.similarCache[cacheKey] = iSimilar
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iSimilar)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
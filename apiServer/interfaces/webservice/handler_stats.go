@@ -5,10 +5,13 @@ import (
 	"net/http"
 
 	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
 )
 
 // statsHandler returns a handler that responds with a JSON object containing the stats of the repositories
-// - it accepts no query parameters
+// - it accepts the filters of /repos (name, language, license, license_family, allow_forking, has_open_issues,
+// the ranges, q and text)
 // it returns a JSON object containing the stats of the repositories
 func (ws Webservice) statsHandler() http.Handler {
 	return http.HandlerFunc(
@@ -20,25 +23,37 @@ func (ws Webservice) statsHandler() http.Handler {
 				return
 			}
 
+			// Get the filters from the query parameters
+			filters, err := usecases.NewGetStatsFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
 			// Check the cache is valid
 			ws.checkCacheValidity()
 
 			// Check the local-memory cache first
-			iStats := ws.statsCache
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iStats, ok := ws.statsCache[cacheKey]
+			ws.reposMU.Unlock()
 
 			// Cache miss
-			if iStats == nil {
+			if !ok {
 
-				stats, err := ws.uc.GetStats(
-					r.Context(),
-				)
+				stats, err := ws.uc.GetStats(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
 				if err != nil {
 					logger.Get(r.Context()).WithError(err).Error("Fail to get latest 100 repositories")
 					w.WriteHeader(http.StatusInternalServerError)
 					return
 				}
 
-				iStats = &Stats{
+				iStats = Stats{
 					AvgNumForksPerRepoByLanguage: stats.AvgNumForksPerRepoByLanguage,
 					AvgNumOpenIssuesByLanguage:   stats.AvgNumOpenIssuesByLanguage,
 					AvgSizeByLanguage:            stats.AvgSizeByLanguage,
@@ -46,13 +61,15 @@ func (ws Webservice) statsHandler() http.Handler {
 				}
 
 				// Store the data in the local-memory cache
-				ws.statsCache = iStats
+				ws.reposMU.Lock()
+				ws.statsCache[cacheKey] = iStats
+				ws.reposMU.Unlock()
 			}
 
 			w.Header().Add("Content-Type", "application/json")
 			w.WriteHeader(http.StatusOK)
 
-			err := json.NewEncoder(w).Encode(iStats)
+			err = json.NewEncoder(w).Encode(iStats)
 			if err != nil {
 				ws.log.WithError(err).Error("Fail to encode JSON")
 			}
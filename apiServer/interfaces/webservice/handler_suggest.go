@@ -0,0 +1,70 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+)
+
+// suggestHandler returns a http.Handler that handles the request to autocomplete the values of a search field
+// it accepts the following query parameters:
+// - field: string (name, owner, language or license. Required)
+// - prefix: string (the start of the value, case-insensitive. Required)
+// - limit: int (the maximum number of suggestions, default 10)
+// it returns a JSON object containing the suggested values, ranked by the number of repositories with the value
+func (ws Webservice) suggestHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewGetSuggestionsFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iSuggestions, ok := ws.suggestCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				suggestions, err := ws.uc.GetSuggestions(r.Context(), filters)
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get suggestions")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the suggestions from the types used in the entities layer to those in the interfaces layer
+				iSuggestions = convertSuggestionsE2I(suggestions)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.suggestCache[cacheKey] = iSuggestions
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iSuggestions)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
@@ -0,0 +1,74 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/pkg/errors"
+)
+
+// languageTrendsHandler returns a handler that responds with the languages ranked by the growth of their share of the
+// newly created repositories
+// it accepts the filters of /stats (except created_after and created_before), and the following query parameters:
+// - window: string (a duration: the current window ends now and is compared to the previous one. Default 24h)
+// it returns a JSON object containing the counts, shares, share change and percent change of each language
+func (ws Webservice) languageTrendsHandler() http.Handler {
+	return http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+
+			// Check to see if the request is a GET request
+			if r.Method != http.MethodGet {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+
+			// Get the filters from the query parameters
+			filters, err := usecases.NewTrendsFilters(r.URL.Query())
+			if err != nil {
+				ws.writeError(w, http.StatusBadRequest, err)
+				return
+			}
+
+			// Check the cache is valid
+			ws.checkCacheValidity()
+
+			// Check the local-memory cache first
+			cacheKey := filters.CacheKey()
+			ws.reposMU.Lock()
+			iTrends, ok := ws.trendsCache[cacheKey]
+			ws.reposMU.Unlock()
+
+			// Cache miss
+			if !ok {
+				trends, err := ws.uc.GetLanguageTrends(r.Context(), filters)
+				if errors.Is(err, usecases.ErrInvalidParameter) {
+					ws.writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				if err != nil {
+					logger.Get(r.Context()).WithError(err).Error("Fail to get language trends")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+
+				// Convert the trends from the types used in the entities layer to those in the interfaces layer
+				iTrends = convertLanguageTrendsE2I(trends)
+
+				// Store the data in the local-memory cache
+				ws.reposMU.Lock()
+				ws.trendsCache[cacheKey] = iTrends
+				ws.reposMU.Unlock()
+			}
+
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			err = json.NewEncoder(w).Encode(iTrends)
+			if err != nil {
+				ws.log.WithError(err).Error("Fail to encode JSON")
+			}
+		},
+	)
+}
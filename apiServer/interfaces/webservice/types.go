@@ -1,46 +1,410 @@
 package webservice
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
 
 // The types in this file are used to represent the data returned by our API.
 
-// RepoList represents a list of repositories
+// RepoList represents a page of repositories
+//   - NextCursor: pass as the cursor query parameter to get the next page (omitted on the last page)
+//   - Total: the number of repositories matching the filters, across all pages
+//   - Facets: the number of matching repositories for each value of the requested facet fields
 type RepoList struct {
-	Items []RepoItem `json:"repositories"`
+	Items      []RepoItem                `json:"repositories"`
+	NextCursor string                    `json:"next_cursor,omitempty"`
+	Total      int                       `json:"total"`
+	Facets     map[string]map[string]int `json:"facets,omitempty"`
 }
 
 // RepoItem represents a repository
 type RepoItem struct {
-	ID              int64     `json:"id"`
-	Name            string    `json:"name"`
-	FullName        string    `json:"full_name"`
-	Owner           string    `json:"owner"`
-	HTMLUrl         string    `json:"html_url"`
-	Description     string    `json:"description"`
-	LanguagesURL    string    `json:"languages_url"`
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
-	Size            int       `json:"size"`
-	Language        string    `json:"language"`
-	Languages       Languages `json:"languages"`
-	LicenseName     string    `json:"license"`
-	ForksCount      int       `json:"forks_count"`
-	OpenIssuesCount int       `json:"open_issues_count"`
-	WatchersCount   int       `json:"watchers_count"`
-	AllowForking    bool      `json:"allow_forking"`
-	HasIssues       bool      `json:"has_issues"`
-	HasProjects     bool      `json:"has_projects"`
-	HasDownloads    bool      `json:"has_downloads"`
-	HasWiki         bool      `json:"has_wiki"`
-	HasPages        bool      `json:"has_pages"`
-	HasDiscussions  bool      `json:"has_discussions"`
+	ID               int64     `json:"id"`
+	Name             string    `json:"name"`
+	FullName         string    `json:"full_name"`
+	Owner            string    `json:"owner"`
+	HTMLUrl          string    `json:"html_url"`
+	Description      string    `json:"description"`
+	LanguagesURL     string    `json:"languages_url"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Size             int       `json:"size"`
+	Language         string    `json:"language"`
+	Languages        Languages `json:"languages"`
+	LanguageCount    int       `json:"language_count"`
+	DominantLanguage string    `json:"dominant_language"`
+	LanguageDerived  bool      `json:"language_derived"`
+	LicenseName      string    `json:"license"`
+	LicenseKey       string    `json:"license_key"`
+	LicenseFamily    string    `json:"license_family"`
+	ForksCount       int       `json:"forks_count"`
+	OpenIssuesCount  int       `json:"open_issues_count"`
+	WatchersCount    int       `json:"watchers_count"`
+	AllowForking     bool      `json:"allow_forking"`
+	HasIssues        bool      `json:"has_issues"`
+	HasProjects      bool      `json:"has_projects"`
+	HasDownloads     bool      `json:"has_downloads"`
+	HasWiki          bool      `json:"has_wiki"`
+	HasPages         bool      `json:"has_pages"`
+	HasDiscussions   bool      `json:"has_discussions"`
+	Quality          Quality   `json:"quality"`
+
+	// The full-text search hit of the repository (omitted outside a text search)
+	Score      *float64          `json:"score,omitempty"`
+	Highlights map[string]string `json:"highlights,omitempty"`
+
+	// fields is the sparse fieldset to serialise (nil for all fields)
+	fields []string
+}
+
+// searchHitKeys are the keys of the full-text search hit. They are not item fields, so they are always serialised.
+var searchHitKeys = []string{"score", "highlights"}
+
+// MarshalJSON serialises the repository. When a sparse fieldset is set, only those keys (and the search hit) are
+// serialised.
+func (r RepoItem) MarshalJSON() ([]byte, error) {
+	type repoItem RepoItem
+	data, err := json.Marshal(repoItem(r))
+	if err != nil || len(r.fields) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range r.fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Quote(field))
+		buf.WriteByte(':')
+		buf.Write(all[field])
+	}
+	for _, key := range searchHitKeys {
+		if value, ok := all[key]; ok {
+			buf.WriteString("," + strconv.Quote(key) + ":")
+			buf.Write(value)
+		}
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Quality represents the quality score of a repository, from 0 to 100, and the contribution of each factor to it
+type Quality struct {
+	Score   int             `json:"score"`
+	Factors []QualityFactor `json:"factors"`
+}
+
+// QualityFactor represents the contribution of a factor to the quality score of a repository
+//   - Weight: the share of the score of the factor, from 0 to 100
+//   - Value: how well the repository meets the factor, from 0 to 1
+//   - Points: the points of the factor in the score (weight * value)
+type QualityFactor struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Value  float64 `json:"value"`
+	Points float64 `json:"points"`
 }
 
 // Languages is a map of languages used in a repository.
 type Languages map[string]Language
 
+// Language represents the bytes of code of a language in a repository, its percentage of the bytes of the repository
+// (0 to 100) and the metadata of the language (see LanguageMeta)
 type Language struct {
-	Bytes int64 `json:"bytes"`
+	Bytes   int64   `json:"bytes"`
+	Percent float64 `json:"percent"`
+	LanguageMeta
+}
+
+// LanguageMeta represents the metadata of a language from the Linguist table (see the linguist package)
+//   - Color: the colour GitHub displays the language with (omitted when unknown)
+//   - Type: programming, markup, data or prose (omitted when unknown)
+//   - Family: the language the statistics of the language are grouped with (e.g. TypeScript for TSX), or its own name
+//     (omitted when unknown)
+type LanguageMeta struct {
+	Color  string `json:"color,omitempty"`
+	Type   string `json:"type,omitempty"`
+	Family string `json:"family,omitempty"`
+}
+
+// Suggestions represents the suggested values for a search field, ranked by the number of repositories
+type Suggestions struct {
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Suggestion represents a suggested value and the number of repositories with the value
+type Suggestion struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// Aggregate represents the groups of an aggregation, ordered by value
+type Aggregate struct {
+	GroupBy string           `json:"group_by"`
+	Groups  []AggregateGroup `json:"groups"`
+}
+
+// AggregateGroup represents the metrics of the repositories sharing a value of the group-by field
+//   - Metrics: the value of each requested metric, by metric (e.g. "avg:forks_count")
+type AggregateGroup struct {
+	Value   string             `json:"value"`
+	Metrics map[string]float64 `json:"metrics"`
+}
+
+// LanguageStats represents the statistics of the languages breakdowns of the repositories
+//   - TotalBytes: the number of bytes of code of all the languages
+//   - Repos: the number of repositories with a languages breakdown
+type LanguageStats struct {
+	TotalBytes int64          `json:"total_bytes"`
+	Repos      int            `json:"repos"`
+	Languages  []LanguageStat `json:"languages"`
+}
+
+// LanguageStat represents the statistics of a language
+//   - Share: the share of the bytes of all the languages (0 to 1)
+//   - Repos: the number of repositories using the language
+//   - MeanShare: the mean share of the language within the repositories using it (0 to 1)
+type LanguageStat struct {
+	Language  string  `json:"language"`
+	Bytes     int64   `json:"bytes"`
+	Share     float64 `json:"share"`
+	Repos     int     `json:"repos"`
+	MeanShare float64 `json:"mean_share"`
+	LanguageMeta
+}
+
+// LanguageCooccurrence represents the pairs of languages used together in the repositories
+//   - Repos: the number of repositories using at least one language
+//   - Pairs: the pairs of languages, by descending count
+type LanguageCooccurrence struct {
+	Repos int            `json:"repos"`
+	Pairs []LanguagePair `json:"pairs"`
+}
+
+// LanguagePair represents the co-occurrence of two languages
+//   - Count: the number of repositories using both languages
+//   - Lift: how much more often the languages are used together than if they were independent (1 is independent)
+//   - Jaccard: the number of repositories using both languages over the number using either (0 to 1)
+type LanguagePair struct {
+	Languages [2]string `json:"languages"`
+	Count     int       `json:"count"`
+	Lift      float64   `json:"lift"`
+	Jaccard   float64   `json:"jaccard"`
+}
+
+// LanguageList represents the languages of the repositories (primary or in the breakdowns), by descending number of
+// repositories
+type LanguageList struct {
+	Languages []LanguageSummary `json:"languages"`
+}
+
+// LanguageSummary represents the usage of a language
+//   - PrimaryRepos: the number of repositories with the language as primary language
+//   - Repos: the number of repositories using the language (primary or in the languages breakdown)
+//   - Bytes: the bytes of code of the language in the languages breakdowns
+//   - AvgSize: the average size of the repositories using the language
+type LanguageSummary struct {
+	Name         string  `json:"name"`
+	PrimaryRepos int     `json:"primary_repos"`
+	Repos        int     `json:"repos"`
+	Bytes        int64   `json:"bytes"`
+	AvgSize      float64 `json:"avg_size"`
+	LanguageMeta
+}
+
+// LanguageDetail represents the usage of a language and the top repositories using it, by descending watchers
+type LanguageDetail struct {
+	LanguageSummary
+	TopRepositories []RepoItem `json:"top_repositories"`
+}
+
+// Owners represents the owners of the repositories, by descending number of repositories
+//   - Total: the number of owners, across all pages
+type Owners struct {
+	Owners []OwnerSummary `json:"owners"`
+	Total  int            `json:"total"`
+}
+
+// OwnerSummary represents the summary of the repositories of an owner
+//   - Repos: the number of repositories
+//   - TotalSize: the sum of the sizes of the repositories
+//   - DominantLanguage: the language with the most bytes of code (the most frequent primary language before the
+//     languages are fetched)
+type OwnerSummary struct {
+	Login            string `json:"login"`
+	Repos            int    `json:"repos"`
+	TotalSize        int    `json:"total_size"`
+	DominantLanguage string `json:"dominant_language"`
+}
+
+// Owner represents the repositories of an owner, newest first, and the statistics of their languages
+type Owner struct {
+	OwnerSummary
+	Repositories []RepoItem    `json:"repositories"`
+	Languages    LanguageStats `json:"languages"`
+}
+
+// LicenseStats represents the licenses of the repositories
+//   - Total: the number of repositories
+//   - Licenses: the number of repositories with each license (the repositories without license are not listed)
+//   - Families: the number of repositories in each family of licenses, including none
+type LicenseStats struct {
+	Total    int            `json:"total"`
+	Licenses []LicenseCount `json:"licenses"`
+	Families []FamilyCount  `json:"families"`
+}
+
+// LicenseCount represents the number of repositories with a license
+type LicenseCount struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	Family string `json:"family"`
+	Count  int    `json:"count"`
+}
+
+// FamilyCount represents the number of repositories with a license of a family
+type FamilyCount struct {
+	Family string `json:"family"`
+	Count  int    `json:"count"`
+}
+
+// Leaderboard represents the repositories ranked by a metric
+//   - Language: the primary language of the ranked repositories (omitted for all the repositories)
+type Leaderboard struct {
+	Metric       string             `json:"metric"`
+	Language     string             `json:"language,omitempty"`
+	Repositories []LeaderboardEntry `json:"repositories"`
+}
+
+// LeaderboardEntry represents a ranked repository, with the fields needed to display it
+//   - Rank: the rank of the repository, from 1
+//   - Value: the value of the metric
+type LeaderboardEntry struct {
+	Rank     int    `json:"rank"`
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Owner    string `json:"owner"`
+	HTMLUrl  string `json:"html_url"`
+	Language string `json:"language"`
+	Value    int    `json:"value"`
+}
+
+// SimilarRepos represents the repositories most similar to a repository
+//   - ID: the ID of the repository
+//   - LicenseWeight, SizeWeight: the weights of the license and the size in the scores
+type SimilarRepos struct {
+	ID            int64         `json:"id"`
+	LicenseWeight float64       `json:"license_weight"`
+	SizeWeight    float64       `json:"size_weight"`
+	Repositories  []SimilarRepo `json:"repositories"`
+}
+
+// SimilarRepo represents a similar repository, with the fields needed to display it
+//   - Rank: the rank of the repository, from 1
+//   - Score: the similarity, between 0 and 1 (the languages mixed with the license and the size)
+//   - LanguageSimilarity: the cosine similarity of the languages breakdowns, between 0 and 1
+type SimilarRepo struct {
+	Rank               int     `json:"rank"`
+	ID                 int64   `json:"id"`
+	Name               string  `json:"name"`
+	FullName           string  `json:"full_name"`
+	Owner              string  `json:"owner"`
+	HTMLUrl            string  `json:"html_url"`
+	Language           string  `json:"language"`
+	LicenseKey         string  `json:"license_key"`
+	Size               int     `json:"size"`
+	Score              float64 `json:"score"`
+	LanguageSimilarity float64 `json:"language_similarity"`
+}
+
+// LanguageTrends represents the growth of the languages of the repositories created in a window
+//   - Start: the start of the current window, which ends now (the previous window ends at Start)
+//   - Repos, PreviousRepos: the number of repositories created in the current and previous windows
+//   - Languages: the primary languages, by descending share change
+type LanguageTrends struct {
+	Window        string          `json:"window"`
+	Start         time.Time       `json:"start"`
+	Repos         int             `json:"repos"`
+	PreviousRepos int             `json:"previous_repos"`
+	Languages     []LanguageTrend `json:"languages"`
+}
+
+// LanguageTrend represents the growth of a language between the previous and the current windows
+//   - Share, PreviousShare: the share of the repositories created in the window (0 to 1)
+//   - ShareChange: share - previous_share
+//   - PercentChange: the percent change of the count (omitted when the previous count is 0)
+type LanguageTrend struct {
+	Language      string   `json:"language"`
+	Count         int      `json:"count"`
+	PreviousCount int      `json:"previous_count"`
+	Share         float64  `json:"share"`
+	PreviousShare float64  `json:"previous_share"`
+	ShareChange   float64  `json:"share_change"`
+	PercentChange *float64 `json:"percent_change,omitempty"`
+}
+
+// Distributions represents the distributions of the numeric fields of the repositories
+//   - Languages: the distribution of each field, by primary language then by field
+//   - CreatedAt: the number of repositories created in each interval (omitted when not requested)
+type Distributions struct {
+	Languages map[string]map[string]Distribution `json:"languages"`
+	CreatedAt []TimeBucket                       `json:"created_at,omitempty"`
+}
+
+// Distribution represents the distribution of the values of a numeric field
+type Distribution struct {
+	Count     int               `json:"count"`
+	Min       float64           `json:"min"`
+	Max       float64           `json:"max"`
+	Median    float64           `json:"median"`
+	P90       float64           `json:"p90"`
+	P99       float64           `json:"p99"`
+	Histogram []HistogramBucket `json:"histogram,omitempty"`
+}
+
+// HistogramBucket represents the number of values in [Min, Max). Max is omitted for the last (unbounded) bucket.
+type HistogramBucket struct {
+	Min   int  `json:"min"`
+	Max   *int `json:"max,omitempty"`
+	Count int  `json:"count"`
+}
+
+// TimeBucket represents the number of repositories in the time interval starting at Start
+type TimeBucket struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// StatsHistory represents a series of the stats history, in ascending time order
+//   - Step: the width of the downsampling buckets (omitted for the recorded points)
+type StatsHistory struct {
+	Metric   string         `json:"metric"`
+	Language string         `json:"language"`
+	Step     string         `json:"step,omitempty"`
+	Points   []HistoryPoint `json:"points"`
+}
+
+// HistoryPoint represents the value of the metric at a time (the start of its bucket when downsampled)
+type HistoryPoint struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Error represents an error returned by the API
+//   - Position: the byte offset of a syntax error in the search query (q)
+type Error struct {
+	Error    string `json:"error"`
+	Position *int   `json:"position,omitempty"`
 }
 
 // Stats represents the statistics returned by the API
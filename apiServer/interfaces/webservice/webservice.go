@@ -2,6 +2,7 @@ package webservice
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/Scalingo/sclng-backend-test-v1/apiServer/config"
 	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/Scalingo/sclng-backend-test-v1/common/query"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/negroni"
@@ -27,9 +29,25 @@ type Webservice struct {
 	// Response times drop significantly when the data is cached.
 	//  - No cache: ~ < 10ms (approx)
 	//  - With cache:  < 300µs (approx)
-	statsCache *Stats
+	statsCache map[string]Stats
 	reposMU    *sync.Mutex
-	reposCache map[string][]RepoItem
+	reposCache map[string]RepoList
+	repoCache  map[string]RepoItem
+
+	suggestCache       map[string]Suggestions
+	aggregateCache     map[string]Aggregate
+	languageStatsCache map[string]LanguageStats
+	licenseStatsCache  map[string]LicenseStats
+	cooccurrenceCache  map[string]LanguageCooccurrence
+	distributionCache  map[string]Distributions
+	historyCache       map[string]StatsHistory
+	trendsCache        map[string]LanguageTrends
+	leaderboardCache   map[string]Leaderboard
+	similarCache       map[string]SimilarRepos
+	ownersCache        map[string]Owners
+	languagesCache     map[string]LanguageList
+	languageCache      map[string]LanguageDetail
+	ownerCache         map[string]Owner
 
 	// We have a naive cache invalidation strategy here. If the timestamp is older than a certain age, we invalidate the cache.
 	cacheTimeStamp time.Time
@@ -61,7 +79,25 @@ func New(log logrus.FieldLogger, cfg *config.Config, uc usecases.Usecases) (*Web
 		serverPort: cfg.APIServerPort,
 		uc:         uc,
 		reposMU:    &sync.Mutex{},
-		reposCache: make(map[string][]RepoItem),
+		reposCache: make(map[string]RepoList),
+		repoCache:  make(map[string]RepoItem),
+
+		suggestCache:   make(map[string]Suggestions),
+		statsCache:     make(map[string]Stats),
+		aggregateCache: make(map[string]Aggregate),
+
+		languageStatsCache: make(map[string]LanguageStats),
+		licenseStatsCache:  make(map[string]LicenseStats),
+		cooccurrenceCache:  make(map[string]LanguageCooccurrence),
+		distributionCache:  make(map[string]Distributions),
+		historyCache:       make(map[string]StatsHistory),
+		trendsCache:        make(map[string]LanguageTrends),
+		leaderboardCache:   make(map[string]Leaderboard),
+		similarCache:       make(map[string]SimilarRepos),
+		ownersCache:        make(map[string]Owners),
+		languagesCache:     make(map[string]LanguageList),
+		languageCache:      make(map[string]LanguageDetail),
+		ownerCache:         make(map[string]Owner),
 	}, nil
 }
 
@@ -92,7 +128,21 @@ func (ws Webservice) Start(ctx context.Context, stop context.CancelFunc, wg *syn
 		mux := http.NewServeMux()
 		mux.Handle("/ping", ws.pongHandler())
 		mux.Handle("/repos", ws.reposHandler())
+		mux.Handle("/repos/", ws.repoHandler())
 		mux.Handle("/stats", ws.statsHandler())
+		mux.Handle("/stats/aggregate", ws.aggregateHandler())
+		mux.Handle("/stats/languages", ws.languageStatsHandler())
+		mux.Handle("/stats/cooccurrence", ws.cooccurrenceHandler())
+		mux.Handle("/stats/distribution", ws.distributionHandler())
+		mux.Handle("/stats/history", ws.historyHandler())
+		mux.Handle("/trends/languages", ws.languageTrendsHandler())
+		mux.Handle("/leaderboards/", ws.leaderboardHandler())
+		mux.Handle("/languages", ws.languagesHandler())
+		mux.Handle("/languages/", ws.languageHandler())
+		mux.Handle("/licenses", ws.licensesHandler())
+		mux.Handle("/owners", ws.ownersHandler())
+		mux.Handle("/owners/", ws.ownerHandler())
+		mux.Handle("/suggest", ws.suggestHandler())
 
 		// Use negroni to create a middleware stack (because included in go.mod of this exercise)
 		n := negroni.Classic()
@@ -147,9 +197,40 @@ func (ws *Webservice) checkCacheValidity() {
 	// The cache is old. Invalidate it. (This covers the case where the cacheTimeStamp isZero also)
 	if time.Since(ws.cacheTimeStamp).Seconds() > float64(ws.cfg.RequestMemCacheMaxAgeSeconds) {
 		ws.reposMU.Lock()
-		ws.reposCache = make(map[string][]RepoItem)
-		ws.statsCache = nil
+		ws.reposCache = make(map[string]RepoList)
+		ws.repoCache = make(map[string]RepoItem)
+		ws.suggestCache = make(map[string]Suggestions)
+		ws.statsCache = make(map[string]Stats)
+		ws.aggregateCache = make(map[string]Aggregate)
+		ws.languageStatsCache = make(map[string]LanguageStats)
+		ws.licenseStatsCache = make(map[string]LicenseStats)
+		ws.cooccurrenceCache = make(map[string]LanguageCooccurrence)
+		ws.distributionCache = make(map[string]Distributions)
+		ws.historyCache = make(map[string]StatsHistory)
+		ws.trendsCache = make(map[string]LanguageTrends)
+		ws.leaderboardCache = make(map[string]Leaderboard)
+		ws.similarCache = make(map[string]SimilarRepos)
+		ws.ownersCache = make(map[string]Owners)
+		ws.languagesCache = make(map[string]LanguageList)
+		ws.languageCache = make(map[string]LanguageDetail)
+		ws.ownerCache = make(map[string]Owner)
 		ws.reposMU.Unlock()
 		ws.cacheTimeStamp = time.Now()
 	}
 }
+
+// writeError writes a JSON error response with the given status code
+func (ws Webservice) writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	out := Error{Error: err.Error()}
+	var syntaxErr *query.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		out.Position = &syntaxErr.Position
+	}
+
+	if err := json.NewEncoder(w).Encode(out); err != nil {
+		ws.log.WithError(err).Error("Fail to encode JSON")
+	}
+}
@@ -2,13 +2,22 @@ package webservice
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/Scalingo/go-utils/logger"
 	"github.com/Scalingo/sclng-backend-test-v1/apiServer/config"
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
 	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases/standard"
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
 	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db/memory"
 	"github.com/sirupsen/logrus"
 )
@@ -250,3 +259,571 @@ func TestWebservice_GracefulShutdown(t *testing.T) {
 	wg.Wait()
 
 }
+
+// TestWebservice_RepoHandler tests the /repos/{id} endpoint
+func TestWebservice_RepoHandler(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Logger
+	log := logger.Default()
+
+	// Config
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf(`failed to create config: %v`, err)
+	}
+	cfg.APIServerPort = 5003
+
+	// DB Service (memory)
+	db, err := memory.New(log)
+	if err != nil {
+		t.Fatalf(`failed to create db: %v`, err)
+	}
+	err = db.SetRepoList(ctx, entities.RepoList{{ID: 1, Name: "repo1"}})
+	if err != nil {
+		t.Fatalf(`failed to set repo list: %v`, err)
+	}
+	err = db.SetRepoItemLanguages(ctx, 1, entities.Languages{"Go": 100})
+	if err != nil {
+		t.Fatalf(`failed to set languages: %v`, err)
+	}
+
+	// Usecases Layer
+	uc := standard.New(ctx, log, cfg, db)
+
+	ws, err := New(log, cfg, uc)
+	if err != nil {
+		t.Fatalf(`failed to create webservice: %v`, err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "Found", path: "/repos/1", wantStatus: http.StatusOK},
+		{name: "Not found", path: "/repos/2", wantStatus: http.StatusNotFound},
+		{name: "Invalid ID", path: "/repos/abc", wantStatus: http.StatusBadRequest},
+		{name: "Unknown sub path", path: "/repos/1/other", wantStatus: http.StatusNotFound},
+		{name: "Sparse fieldset", path: "/repos/1?fields=id,languages", wantStatus: http.StatusOK},
+		{name: "Unknown field", path: "/repos/1?fields=id,colour", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				ws.repoHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				if rec.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				var got RepoItem
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode the response: %v", err)
+				}
+				if got.ID != 1 || got.Languages["Go"].Bytes != 100 || got.Languages["Go"].Percent != 100 {
+					t.Errorf("got = %+v", got)
+				}
+				if meta := got.Languages["Go"].LanguageMeta; meta.Type != "programming" || meta.Family != "Go" {
+					t.Errorf("got language metadata = %+v, want a programming language of the Go family", meta)
+				}
+				if tt.name == "Found" && (got.Quality.Score != 20 || len(got.Quality.Factors) != 8) {
+					t.Errorf("got quality = %+v, want a score of 20 (the languages) with 8 factors", got.Quality)
+				}
+			},
+		)
+	}
+}
+
+// TestWebservice_ReposHandler_Cursor tests the pagination of the /repos endpoint
+// The cursors past the results RediSearch can read are rejected with a 400
+func TestWebservice_ReposHandler_Cursor(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Logger
+	log := logger.Default()
+
+	// Config
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf(`failed to create config: %v`, err)
+	}
+
+	// DB Service (memory)
+	db, err := memory.New(log)
+	if err != nil {
+		t.Fatalf(`failed to create db: %v`, err)
+	}
+	err = db.SetRepoList(ctx, entities.RepoList{{ID: 1, Name: "repo1"}, {ID: 2, Name: "repo2"}})
+	if err != nil {
+		t.Fatalf(`failed to set repo list: %v`, err)
+	}
+
+	// Usecases Layer
+	uc := standard.New(ctx, log, cfg, db)
+
+	ws, err := New(log, cfg, uc)
+	if err != nil {
+		t.Fatalf(`failed to create webservice: %v`, err)
+	}
+
+	cursor := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantItems  int
+		wantNext   bool
+	}{
+		{name: "First page", path: "/repos?limit=1", wantStatus: http.StatusOK, wantItems: 1, wantNext: true},
+		{name: "Last page", path: "/repos?limit=1&cursor=" + cursor("v1:1"), wantStatus: http.StatusOK, wantItems: 1},
+		{name: "Past the results", path: "/repos?cursor=" + cursor("v1:9000"), wantStatus: http.StatusOK},
+		{name: "Past the max offset", path: "/repos?cursor=" + cursor("v1:999999999"), wantStatus: http.StatusBadRequest},
+		{name: "Negative offset", path: "/repos?cursor=" + cursor("v1:-1"), wantStatus: http.StatusBadRequest},
+		{name: "Invalid cursor", path: "/repos?cursor=abc", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				ws.reposHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				if rec.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				var got RepoList
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode the response: %v", err)
+				}
+				if len(got.Items) != tt.wantItems || (got.NextCursor != "") != tt.wantNext || got.Total != 2 {
+					t.Errorf("got = %+v", got)
+				}
+			},
+		)
+	}
+}
+
+// TestWebservice_SimilarHandler tests the /repos/{id}/similar endpoint
+func TestWebservice_SimilarHandler(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Logger
+	log := logger.Default()
+
+	// Config
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf(`failed to create config: %v`, err)
+	}
+
+	// DB Service (memory)
+	db, err := memory.New(log)
+	if err != nil {
+		t.Fatalf(`failed to create db: %v`, err)
+	}
+	err = db.SetRepoList(
+		ctx, entities.RepoList{
+			{ID: 1, Name: "repo1", LicenseKey: "mit"},
+			{ID: 2, Name: "repo2", LicenseKey: "mit"},
+			{ID: 3, Name: "repo3", LicenseKey: "gpl-3.0"},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`failed to set repo list: %v`, err)
+	}
+	for id, langs := range map[int64]entities.Languages{1: {"Go": 100}, 2: {"Go": 50, "C": 50}, 3: {"Go": 100}} {
+		err = db.SetRepoItemLanguages(ctx, id, langs)
+		if err != nil {
+			t.Fatalf(`failed to set languages: %v`, err)
+		}
+	}
+
+	// Usecases Layer
+	uc := standard.New(ctx, log, cfg, db)
+
+	ws, err := New(log, cfg, uc)
+	if err != nil {
+		t.Fatalf(`failed to create webservice: %v`, err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantIDs    []int64
+	}{
+		{name: "Languages", path: "/repos/1/similar", wantStatus: http.StatusOK, wantIDs: []int64{3, 2}},
+		{name: "License", path: "/repos/1/similar?license_weight=0.5", wantStatus: http.StatusOK, wantIDs: []int64{2, 3}},
+		{name: "Limit", path: "/repos/1/similar?limit=1", wantStatus: http.StatusOK, wantIDs: []int64{3}},
+		{name: "Not found", path: "/repos/4/similar", wantStatus: http.StatusNotFound},
+		{name: "Invalid ID", path: "/repos/abc/similar", wantStatus: http.StatusBadRequest},
+		{name: "Invalid limit", path: "/repos/1/similar?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "Invalid weight", path: "/repos/1/similar?size_weight=2", wantStatus: http.StatusBadRequest},
+		{
+			name: "Weights above 1", path: "/repos/1/similar?license_weight=0.6&size_weight=0.6",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				ws.repoHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				if rec.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				var got SimilarRepos
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode the response: %v", err)
+				}
+				ids := make([]int64, len(got.Repositories))
+				for i, repo := range got.Repositories {
+					ids[i] = repo.ID
+				}
+				if got.ID != 1 || !reflect.DeepEqual(ids, tt.wantIDs) || got.Repositories[0].Rank != 1 {
+					t.Errorf("got = %+v, want the repositories %v", got, tt.wantIDs)
+				}
+			},
+		)
+	}
+}
+
+// TestWebservice_OwnerHandler tests the /owners/{login} endpoint
+func TestWebservice_OwnerHandler(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Logger
+	log := logger.Default()
+
+	// Config
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf(`failed to create config: %v`, err)
+	}
+
+	// DB Service (memory)
+	db, err := memory.New(log)
+	if err != nil {
+		t.Fatalf(`failed to create db: %v`, err)
+	}
+	now := time.Now()
+	err = db.SetRepoList(
+		ctx, entities.RepoList{
+			{ID: 1, Name: "repo1", Owner: "alice", Language: "Go", Size: 10, CreatedAt: now.Add(-time.Hour)},
+			{ID: 2, Name: "repo2", Owner: "alice", Language: "Go", Size: 20, CreatedAt: now},
+			{ID: 3, Name: "repo3", Owner: "bob", Language: "Rust", Size: 5, CreatedAt: now},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`failed to set repo list: %v`, err)
+	}
+	err = db.SetRepoItemLanguages(ctx, 1, entities.Languages{"Go": 100})
+	if err != nil {
+		t.Fatalf(`failed to set languages: %v`, err)
+	}
+
+	// Usecases Layer
+	uc := standard.New(ctx, log, cfg, db)
+
+	ws, err := New(log, cfg, uc)
+	if err != nil {
+		t.Fatalf(`failed to create webservice: %v`, err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{name: "Found", path: "/owners/alice", wantStatus: http.StatusOK},
+		{name: "Case-insensitive", path: "/owners/Alice", wantStatus: http.StatusOK},
+		{name: "Not found", path: "/owners/ali", wantStatus: http.StatusNotFound},
+		{name: "Unknown sub path", path: "/owners/alice/other", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				ws.ownerHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				if rec.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				var got Owner
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode the response: %v", err)
+				}
+				want := OwnerSummary{Login: "alice", Repos: 2, TotalSize: 30, DominantLanguage: "Go"}
+				if got.OwnerSummary != want || len(got.Repositories) != 2 || got.Repositories[0].ID != 2 ||
+					got.Languages.Repos != 1 {
+					t.Errorf("got = %+v", got)
+				}
+			},
+		)
+	}
+}
+
+// TestWebservice_LanguageTrendsHandler tests the /trends/languages endpoint
+func TestWebservice_LicensesHandler(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Logger
+	log := logger.Default()
+
+	// Config
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf(`failed to create config: %v`, err)
+	}
+
+	// DB Service (memory)
+	db, err := memory.New(log)
+	if err != nil {
+		t.Fatalf(`failed to create db: %v`, err)
+	}
+	err = db.SetRepoList(
+		ctx, entities.RepoList{
+			{ID: 1, Name: "repo1", LicenseKey: "mit", LicenseName: "MIT License"},
+			{ID: 2, Name: "repo2", LicenseKey: "gpl-3.0", LicenseName: "GNU General Public License v3.0"},
+			{ID: 3, Name: "repo3", LicenseKey: "lgpl-3.0", LicenseName: "GNU Lesser General Public License v3.0"},
+			{ID: 4, Name: "repo4"},
+		},
+	)
+	if err != nil {
+		t.Fatalf(`failed to set repo list: %v`, err)
+	}
+
+	// Usecases Layer
+	uc := standard.New(ctx, log, cfg, db)
+
+	ws, err := New(log, cfg, uc)
+	if err != nil {
+		t.Fatalf(`failed to create webservice: %v`, err)
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		wantStatus   int
+		wantTotal    int
+		wantLicenses []string
+	}{
+		{name: "All", path: "/licenses", wantStatus: http.StatusOK, wantTotal: 4,
+			wantLicenses: []string{"gpl-3.0", "lgpl-3.0", "mit"}},
+		{name: "Exact key", path: "/licenses?license=GPL-3.0", wantStatus: http.StatusOK, wantTotal: 1,
+			wantLicenses: []string{"gpl-3.0"}},
+		{name: "Partial key", path: "/licenses?license=gpl", wantStatus: http.StatusOK, wantLicenses: []string{}},
+		{name: "License name", path: "/licenses?license_name=gnu", wantStatus: http.StatusOK, wantTotal: 2,
+			wantLicenses: []string{"gpl-3.0", "lgpl-3.0"}},
+		{name: "Family", path: "/licenses?license_family=weak-copyleft", wantStatus: http.StatusOK, wantTotal: 1,
+			wantLicenses: []string{"lgpl-3.0"}},
+		{name: "Unknown family", path: "/licenses?license_family=copyleft", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				rec := httptest.NewRecorder()
+				ws.licensesHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+				if rec.Code != tt.wantStatus {
+					t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+				}
+				if tt.wantStatus != http.StatusOK {
+					return
+				}
+
+				var got LicenseStats
+				if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+					t.Fatalf("failed to decode the response: %v", err)
+				}
+				keys := []string{}
+				for _, license := range got.Licenses {
+					keys = append(keys, license.Key)
+				}
+				if got.Total != tt.wantTotal || !reflect.DeepEqual(keys, tt.wantLicenses) {
+					t.Errorf("got total %d and licenses %v, want %d and %v", got.Total, keys, tt.wantTotal, tt.wantLicenses)
+				}
+				if len(got.Families) != 5 {
+					t.Errorf("got %d families, want 5", len(got.Families))
+				}
+			},
+		)
+	}
+}
+
+func TestWebservice_LanguageTrendsHandler(t *testing.T) {
+
+	ctx := context.Background()
+
+	// Logger
+	log := logger.Default()
+
+	// Config
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatalf(`failed to create config: %v`, err)
+	}
+
+	// DB Service (memory): Go grows from 1 to 3 repositories, Rust falls from 3 to 1 and Python disappears
+	db, err := memory.New(log)
+	if err != nil {
+		t.Fatalf(`failed to create db: %v`, err)
+	}
+	now := time.Now()
+	list := entities.RepoList{}
+	for i, repo := range []struct {
+		language string
+		age      time.Duration
+	}{
+		{"Go", time.Hour}, {"Go", 2 * time.Hour}, {"Go", 3 * time.Hour}, {"Rust", 4 * time.Hour},
+		{"Go", 30 * time.Hour}, {"Rust", 31 * time.Hour}, {"Rust", 32 * time.Hour}, {"Rust", 33 * time.Hour},
+		{"Python", 34 * time.Hour}, {"Python", 72 * time.Hour},
+	} {
+		list = append(list, entities.RepoItem{ID: int64(i + 1), Language: repo.language, CreatedAt: now.Add(-repo.age)})
+	}
+	if err := db.SetRepoList(ctx, list); err != nil {
+		t.Fatalf(`failed to set repo list: %v`, err)
+	}
+
+	// Usecases Layer
+	uc := standard.New(ctx, log, cfg, db)
+
+	ws, err := New(log, cfg, uc)
+	if err != nil {
+		t.Fatalf(`failed to create webservice: %v`, err)
+	}
+
+	for _, path := range []string{"/trends/languages?window=0s", "/trends/languages?created_after=1h"} {
+		rec := httptest.NewRecorder()
+		ws.languageTrendsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s status = %d, want %d", path, rec.Code, http.StatusBadRequest)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	ws.languageTrendsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/trends/languages?window=24h", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	var got LanguageTrends
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode the response: %v", err)
+	}
+
+	if got.Window != "24h0m0s" || got.Repos != 4 || got.PreviousRepos != 5 {
+		t.Errorf("got window = %s, repos = %d, previous_repos = %d", got.Window, got.Repos, got.PreviousRepos)
+	}
+	percent := func(v float64) *float64 { return &v }
+	want := []LanguageTrend{
+		{Language: "Go", Count: 3, PreviousCount: 1, Share: 0.75, PreviousShare: 0.2, PercentChange: percent(200)},
+		{Language: "Python", Count: 0, PreviousCount: 1, Share: 0, PreviousShare: 0.2, PercentChange: percent(-100)},
+		{Language: "Rust", Count: 1, PreviousCount: 3, Share: 0.25, PreviousShare: 0.6, PercentChange: percent(-200. / 3)},
+	}
+	if len(got.Languages) != len(want) {
+		t.Fatalf("got languages = %+v, want %+v", got.Languages, want)
+	}
+	for i, w := range want {
+		g := got.Languages[i]
+		if g.Language != w.Language || g.Count != w.Count || g.PreviousCount != w.PreviousCount ||
+			!almostEqual(g.Share, w.Share) || !almostEqual(g.PreviousShare, w.PreviousShare) ||
+			!almostEqual(g.ShareChange, w.Share-w.PreviousShare) || !almostEqual(*g.PercentChange, *w.PercentChange) {
+			t.Errorf("got languages[%d] = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+// almostEqual compares floats computed in a different order
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestRepoItemFields checks that the fields accepted by the fields parameter are the JSON keys of RepoItem
+func TestRepoItemFields(t *testing.T) {
+	isSearchHitKey := map[string]bool{}
+	for _, key := range searchHitKeys {
+		isSearchHitKey[key] = true
+	}
+
+	var keys []string
+	rt := reflect.TypeOf(RepoItem{})
+	for i := 0; i < rt.NumField(); i++ {
+		tag := rt.Field(i).Tag.Get("json")
+		if key := strings.Split(tag, ",")[0]; key != "" && !isSearchHitKey[key] {
+			keys = append(keys, key)
+		}
+	}
+	if !reflect.DeepEqual(keys, usecases.RepoItemFields) {
+		t.Errorf("usecases.RepoItemFields = %v, want %v", usecases.RepoItemFields, keys)
+	}
+}
+
+// TestRepoItem_MarshalJSON checks that only the keys of the sparse fieldset are serialised
+func TestRepoItem_MarshalJSON(t *testing.T) {
+	item := RepoItem{ID: 1, FullName: "owner/repo", Languages: Languages{"Go": {Bytes: 10, Percent: 100}}}
+
+	tests := []struct {
+		name   string
+		fields []string
+		hit    *entities.SearchHit
+		want   string
+	}{
+		{
+			name:   "Sparse",
+			fields: []string{"full_name", "languages"},
+			want:   `{"full_name":"owner/repo","languages":{"Go":{"bytes":10,"percent":100}}}`,
+		},
+		{name: "Single", fields: []string{"id"}, want: `{"id":1}`},
+		{
+			name:   "Search hit",
+			fields: []string{"id"},
+			hit:    &entities.SearchHit{Score: 1.5, Highlights: map[string]string{"name": "<em>repo</em>"}},
+			want:   `{"id":1,"score":1.5,"highlights":{"name":"\u003cem\u003erepo\u003c/em\u003e"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				item.fields = tt.fields
+				item.Score, item.Highlights = nil, nil
+				if tt.hit != nil {
+					item.Score, item.Highlights = &tt.hit.Score, tt.hit.Highlights
+				}
+				got, err := json.Marshal(item)
+				if err != nil {
+					t.Fatalf("MarshalJSON() error = %v", err)
+				}
+				if string(got) != tt.want {
+					t.Errorf("MarshalJSON() got = %s, want %s", got, tt.want)
+				}
+			},
+		)
+	}
+
+	// No fieldset serialises every key
+	item.fields = nil
+	got, err := json.Marshal(item)
+	if err != nil {
+		t.Fatalf("MarshalJSON() error = %v", err)
+	}
+	if !strings.Contains(string(got), `"has_discussions":false`) {
+		t.Errorf("MarshalJSON() got = %s", got)
+	}
+}
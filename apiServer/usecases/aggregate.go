@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// AggregateGroupFields lists the values accepted by the group_by query parameter of the aggregations
+var AggregateGroupFields = []string{
+	"language", "license", "owner", "allow_forking", "has_issues", "has_projects", "has_downloads", "has_wiki",
+	"has_pages", "has_discussions",
+}
+
+// AggregateFuncs lists the functions accepted in the metrics query parameter of the aggregations.
+// count takes no field, the other functions take one of AggregateMetricFields (e.g. "avg:forks_count")
+var AggregateFuncs = []string{"count", "sum", "avg", "min", "max"}
+
+// AggregateMetricFields lists the numeric fields the metrics can be computed over
+var AggregateMetricFields = []string{"size", "forks_count", "watchers_count", "open_issues_count"}
+
+// DefaultAggregateMetric is the metric computed when no metrics are requested
+const DefaultAggregateMetric = "count"
+
+// AggregateMetric is a metric of an aggregation: a function of a numeric field (no field for count)
+type AggregateMetric struct {
+	Func  string
+	Field string
+}
+
+// String returns the metric as written in the metrics query parameter, e.g. "avg:forks_count" or "count"
+func (m AggregateMetric) String() string {
+	if m.Field == "" {
+		return m.Func
+	}
+	return m.Func + ":" + m.Field
+}
+
+// AggregateFilters is a struct to hold the parameters for the Aggregate usecase
+//   - Filters: the repositories to aggregate (the same filters as the stats)
+//   - GroupBy: the field to group the repositories by (one of AggregateGroupFields)
+//   - Metrics: the metrics to compute over each group, without duplicates
+type AggregateFilters struct {
+	Filters GetRepoListFilters
+	GroupBy string
+	Metrics []AggregateMetric
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (a AggregateFilters) CacheKey() string {
+	metrics := make([]string, len(a.Metrics))
+	for i, m := range a.Metrics {
+		metrics[i] = m.String()
+	}
+	return fmt.Sprintf("%s-%s-%s", a.Filters.CacheKey(), a.GroupBy, strings.Join(metrics, ","))
+}
+
+// NewAggregateFilters creates a new AggregateFilters struct from the query parameters of a request
+// It returns an error wrapping ErrInvalidParameter if a parameter is missing or cannot be parsed
+func NewAggregateFilters(values url.Values) (AggregateFilters, error) {
+
+	filters, err := NewGetStatsFilters(values)
+	if err != nil {
+		return AggregateFilters{}, err
+	}
+
+	groupBy := values.Get("group_by")
+	if !contains(AggregateGroupFields, groupBy) {
+		return AggregateFilters{}, errors.Wrapf(
+			ErrInvalidParameter, "group_by must be one of %s, got %q", strings.Join(AggregateGroupFields, ", "), groupBy,
+		)
+	}
+
+	metrics, err := toAggregateMetrics(values.Get("metrics"))
+	if err != nil {
+		return AggregateFilters{}, err
+	}
+
+	return AggregateFilters{Filters: filters, GroupBy: groupBy, Metrics: metrics}, nil
+}
+
+// toAggregateMetrics parses a comma separated list of metrics (the metrics query parameter)
+// The metrics are returned in the requested order without duplicates. An empty list returns the default metric.
+func toAggregateMetrics(in string) ([]AggregateMetric, error) {
+	if strings.TrimSpace(in) == "" {
+		in = DefaultAggregateMetric
+	}
+
+	var out []AggregateMetric
+	seen := map[AggregateMetric]bool{}
+	for _, part := range strings.Split(in, ",") {
+		fn, field, _ := strings.Cut(strings.TrimSpace(part), ":")
+		m := AggregateMetric{Func: fn, Field: field}
+		switch {
+		case !contains(AggregateFuncs, fn):
+			return nil, errors.Wrapf(
+				ErrInvalidParameter, "metric function must be one of %s, got %q", strings.Join(AggregateFuncs, ", "), part,
+			)
+		case fn == "count" && field != "":
+			return nil, errors.Wrapf(ErrInvalidParameter, "count takes no field, got %q", part)
+		case fn != "count" && !contains(AggregateMetricFields, field):
+			return nil, errors.Wrapf(
+				ErrInvalidParameter, "metric field must be one of %s, got %q", strings.Join(AggregateMetricFields, ", "), part,
+			)
+		}
+		if !seen[m] {
+			seen[m] = true
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
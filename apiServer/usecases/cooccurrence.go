@@ -0,0 +1,44 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// CooccurrenceFilters is a struct to hold the parameters for the GetLanguageCooccurrence usecase
+//   - Filters: the repositories to count (the same filters as the stats)
+//   - MinBytes: the number of bytes from which a language counts in a repository (0 counts every language)
+type CooccurrenceFilters struct {
+	Filters  GetRepoListFilters
+	MinBytes int64
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (c CooccurrenceFilters) CacheKey() string {
+	return fmt.Sprintf("%s-%d", c.Filters.CacheKey(), c.MinBytes)
+}
+
+// NewCooccurrenceFilters creates a new CooccurrenceFilters struct from the query parameters of a request
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed
+func NewCooccurrenceFilters(values url.Values) (CooccurrenceFilters, error) {
+
+	filters, err := NewGetStatsFilters(values)
+	if err != nil {
+		return CooccurrenceFilters{}, err
+	}
+
+	var minBytes int64
+	if in := values.Get("min_bytes"); in != "" {
+		minBytes, err = strconv.ParseInt(in, 10, 64)
+		if err != nil || minBytes < 0 {
+			return CooccurrenceFilters{}, errors.Wrapf(
+				ErrInvalidParameter, "min_bytes must be a non-negative integer, got %q", in,
+			)
+		}
+	}
+
+	return CooccurrenceFilters{Filters: filters, MinBytes: minBytes}, nil
+}
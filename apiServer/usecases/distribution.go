@@ -0,0 +1,94 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultHistogramBuckets are the histogram buckets computed when no buckets are requested
+var DefaultHistogramBuckets = []int{0, 10, 100, 1000, 10000}
+
+// MaxHistogramBuckets is the maximum number of buckets that may be requested
+const MaxHistogramBuckets = 50
+
+// TimeIntervals lists the values accepted by the created_interval query parameter of the distributions
+var TimeIntervals = []string{"hour", "day", "month"}
+
+// DistributionFilters is a struct to hold the parameters for the GetDistributions usecase
+//   - Filters: the repositories to compute the distributions over (the same filters as the stats)
+//   - Fields: the numeric fields to compute the distributions of (AggregateMetricFields order)
+//   - Buckets: the ascending lower bounds of the histogram buckets (the last bucket is unbounded)
+//   - CreatedInterval: the interval of the created_at histogram, one of TimeIntervals (empty for no histogram)
+type DistributionFilters struct {
+	Filters         GetRepoListFilters
+	Fields          []string
+	Buckets         []int
+	CreatedInterval string
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (d DistributionFilters) CacheKey() string {
+	return fmt.Sprintf(
+		"%s-%s-%v-%s", d.Filters.CacheKey(), strings.Join(d.Fields, ","), d.Buckets, d.CreatedInterval,
+	)
+}
+
+// NewDistributionFilters creates a new DistributionFilters struct from the query parameters of a request
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed
+func NewDistributionFilters(values url.Values) (DistributionFilters, error) {
+
+	filters, err := NewGetStatsFilters(values)
+	if err != nil {
+		return DistributionFilters{}, err
+	}
+
+	fields, err := parseFieldList(values.Get("fields"), AggregateMetricFields, "fields")
+	if err != nil {
+		return DistributionFilters{}, err
+	}
+	if fields == nil {
+		fields = AggregateMetricFields
+	}
+
+	buckets, err := toBuckets(values.Get("buckets"))
+	if err != nil {
+		return DistributionFilters{}, err
+	}
+
+	interval := values.Get("created_interval")
+	if interval != "" && !contains(TimeIntervals, interval) {
+		return DistributionFilters{}, errors.Wrapf(
+			ErrInvalidParameter, "created_interval must be one of %s, got %q", strings.Join(TimeIntervals, ", "), interval,
+		)
+	}
+
+	return DistributionFilters{Filters: filters, Fields: fields, Buckets: buckets, CreatedInterval: interval}, nil
+}
+
+// toBuckets parses a comma separated list of ascending, non-negative bucket bounds (the buckets query parameter)
+// An empty list returns DefaultHistogramBuckets
+func toBuckets(in string) ([]int, error) {
+	if strings.TrimSpace(in) == "" {
+		return DefaultHistogramBuckets, nil
+	}
+
+	parts := strings.Split(in, ",")
+	if len(parts) > MaxHistogramBuckets {
+		return nil, errors.Wrapf(ErrInvalidParameter, "buckets must have at most %d bounds", MaxHistogramBuckets)
+	}
+	out := make([]int, len(parts))
+	for i, part := range parts {
+		bound, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || bound < 0 || (i > 0 && bound <= out[i-1]) {
+			return nil, errors.Wrapf(
+				ErrInvalidParameter, "buckets must be ascending non-negative integers, got %q", in,
+			)
+		}
+		out[i] = bound
+	}
+	return out, nil
+}
@@ -1,38 +1,486 @@
 package usecases
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/fulltext"
+	"github.com/Scalingo/sclng-backend-test-v1/common/licenses"
+	"github.com/Scalingo/sclng-backend-test-v1/common/linguist"
+	"github.com/Scalingo/sclng-backend-test-v1/common/query"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRepoListLimit is the number of repositories returned when no limit is requested
+	DefaultRepoListLimit = 100
+	// MaxRepoListLimit is the largest limit that may be requested
+	MaxRepoListLimit = 1000
+	// MaxRepoListOffset is the largest offset a cursor may hold. RediSearch reads at most 10000 results
+	// (MAXSEARCHRESULTS), so the pages past it cannot be read.
+	MaxRepoListOffset = 10000 - MaxRepoListLimit
+
+	// DefaultRepoListSort is the field used to sort the repositories when no sort is requested
+	DefaultRepoListSort = "created_at"
+	// RelevanceSort sorts the repositories by descending score. It is the default sort of a text search.
+	RelevanceSort = "relevance"
+
+	// cursorPrefix versions the opaque cursor format
+	cursorPrefix = "v1:"
 )
 
 // GetRepoListFilters is a struct to hold the parameters for the GetRepoList usecase
 // use pointer values to allow null values
 type GetRepoListFilters struct {
-	Name          *string
-	Language      *string
-	License       *string
-	AllowForking  *bool
-	HasOpenIssues *bool
+	Name             *string
+	Language         *string
+	DominantLanguage *string
+	LicenseName      *string // a substring of the license name (license_name parameter)
+	LicenseKey       *string // the exact SPDX key of the license (license parameter)
+	LicenseFamily    *string // one of licenses.Families (license_family parameter)
+	Owner            *string
+	AllowForking     *bool
+	HasOpenIssues    *bool
+
+	// Ranges
+	Size            IntRange
+	ForksCount      IntRange
+	WatchersCount   IntRange
+	OpenIssuesCount IntRange
+	LanguageCount   IntRange
+	Quality         IntRange // the quality score, from 0 to 100 (min_quality and max_quality parameters)
+	CreatedAt       TimeRange
+	UpdatedAt       TimeRange
+
+	// Query is the parsed search query of the q parameter (nil matches everything)
+	Query query.Expr
+
+	// Text is the full-text search of the text parameter over the name and description (nil for no text search)
+	// Highlight requests the matched terms of the text search to be highlighted in the name and description
+	Text      *string
+	Highlight bool
+
+	// Pagination (Offset is decoded from the opaque cursor)
+	Limit  int
+	Offset int
+
+	// Sorting (one of RepoListSortFields, descending unless SortAscending; relevance is always descending)
+	SortBy        string
+	SortAscending bool
+
+	// Facets lists the fields to count the values of over the matching repositories (one of RepoListFacetFields)
+	Facets []string
+
+	// Fields is the sparse fieldset requested with the fields parameter, in RepoItemFields order (nil for all fields)
+	Fields []string
+}
+
+// IntRange is an inclusive range filter on an integer field. A nil bound is unbounded.
+type IntRange struct {
+	Min *int
+	Max *int
+}
+
+// TimeRange is an exclusive range filter on a time field. A nil bound is unbounded.
+type TimeRange struct {
+	After  *time.Time
+	Before *time.Time
+}
+
+// RepoListSortFields lists the values accepted by the sort query parameter
+var RepoListSortFields = []string{"created_at", "forks", "watchers", "size", "open_issues", "quality", RelevanceSort}
+
+// RepoListFacetFields lists the values accepted by the facets query parameter
+var RepoListFacetFields = []string{"language", "license", "owner"}
+
+// RepoItemFields lists the values accepted by the fields query parameter (the keys of a repository in the API)
+var RepoItemFields = []string{
+	"id", "name", "full_name", "owner", "html_url", "description", "languages_url", "created_at", "updated_at", "size",
+	"language", "languages", "language_count", "dominant_language", "language_derived", "license", "license_key",
+	"license_family", "forks_count", "open_issues_count", "watchers_count", "allow_forking", "has_issues", "has_projects",
+	"has_downloads", "has_wiki", "has_pages", "has_discussions", "quality",
 }
 
 // CacheKey returns a string that can be used as a cache key for the filters
 func (g GetRepoListFilters) CacheKey() string {
-	return fmt.Sprintf("%v-%v-%v-%v-%v", g.Name, g.Language, g.License, g.AllowForking, g.HasOpenIssues)
+	return fmt.Sprintf(
+		"%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%s-%t-%d-%d-%s-%t-%s-%s",
+		strPtrKey(g.Name), strPtrKey(g.Language), strPtrKey(g.DominantLanguage), strPtrKey(g.LicenseName),
+		strPtrKey(g.LicenseKey), strPtrKey(g.LicenseFamily), strPtrKey(g.Owner), boolPtrKey(g.AllowForking),
+		boolPtrKey(g.HasOpenIssues), g.Size.cacheKey(), g.ForksCount.cacheKey(), g.WatchersCount.cacheKey(),
+		g.OpenIssuesCount.cacheKey(), g.LanguageCount.cacheKey(), g.Quality.cacheKey(), g.CreatedAt.cacheKey(),
+		g.UpdatedAt.cacheKey(),
+		queryKey(g.Query), strPtrKey(g.Text), g.Highlight, g.Limit, g.Offset, g.SortBy, g.SortAscending,
+		strings.Join(g.Facets, ","), strings.Join(g.Fields, ","),
+	)
 }
 
-// NewGetRepoListFilteredFilters creates a new GetRepoListFilters struct with the provided values
-func NewGetRepoListFilteredFilters(name, language, license, allowForkingCount, hasOpenIssues string) GetRepoListFilters {
+// NewGetRepoListFilteredFilters creates a new GetRepoListFilters struct from the query parameters of a request
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed,
+// or a *query.SyntaxError if the search query (q) cannot be parsed
+func NewGetRepoListFilteredFilters(values url.Values) (GetRepoListFilters, error) {
+
+	limit, err := toLimit(values.Get("limit"))
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	offset, err := decodeCursor(values.Get("cursor"))
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	text, err := toText(values.Get("text"))
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	sortBy, err := toSortField(values.Get("sort"), text != nil)
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	ascending, err := toAscending(values.Get("order"))
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+	if sortBy == RelevanceSort && ascending {
+		return GetRepoListFilters{}, errors.Wrap(ErrInvalidParameter, "relevance can only be sorted in desc order")
+	}
+
+	highlight, err := toHighlight(values.Get("highlight"), text != nil)
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	// Range filters
+	var ranges [6]IntRange
+	rangeFields := []string{"size", "forks_count", "watchers_count", "open_issues_count", "language_count", "quality"}
+	for i, field := range rangeFields {
+		if ranges[i], err = toIntRange(values, field); err != nil {
+			return GetRepoListFilters{}, err
+		}
+	}
+	createdAt, err := toTimeRange(values, "created")
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+	updatedAt, err := toTimeRange(values, "updated")
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	licenseFamily, err := toLicenseFamily(values.Get("license_family"))
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	q, err := query.Parse(values.Get("q"))
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	facets, err := toFacets(values.Get("facets"))
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	fields, err := ParseRepoItemFields(values.Get("fields"))
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
 
 	return GetRepoListFilters{
-		Name:          toStr(name),
-		Language:      toStr(language),
-		License:       toStr(license),
-		AllowForking:  toBool(allowForkingCount),
-		HasOpenIssues: toBool(hasOpenIssues),
+		Name:             toStr(values.Get("name")),
+		Language:         toLanguage(values.Get("language")),
+		DominantLanguage: toLanguage(values.Get("dominant_language")),
+		LicenseName:      toStr(values.Get("license_name")),
+		LicenseKey:       toStr(strings.ToLower(strings.TrimSpace(values.Get("license")))),
+		LicenseFamily:    licenseFamily,
+		Owner:            toStr(values.Get("owner")),
+		AllowForking:     toBool(values.Get("allow_forking")),
+		HasOpenIssues:    toBool(values.Get("has_open_issues")),
+
+		Size:            ranges[0],
+		ForksCount:      ranges[1],
+		WatchersCount:   ranges[2],
+		OpenIssuesCount: ranges[3],
+		LanguageCount:   ranges[4],
+		Quality:         ranges[5],
+		CreatedAt:       createdAt,
+		UpdatedAt:       updatedAt,
+
+		Query: q,
+
+		Text:      text,
+		Highlight: highlight,
+
+		Limit:  limit,
+		Offset: offset,
+
+		SortBy:        sortBy,
+		SortAscending: ascending,
+
+		Facets: facets,
+		Fields: fields,
+	}, nil
+}
+
+// NewGetStatsFilters creates the filters of the stats from the query parameters of a request
+// The stats accept the same filters as the repository list. The pagination, sorting, facets, fields and highlight
+// parameters do not apply to the stats and are ignored, so they are left unset (and out of the cache key).
+func NewGetStatsFilters(values url.Values) (GetRepoListFilters, error) {
+	filters, err := NewGetRepoListFilteredFilters(values)
+	if err != nil {
+		return GetRepoListFilters{}, err
+	}
+
+	return GetRepoListFilters{
+		Name:             filters.Name,
+		Language:         filters.Language,
+		DominantLanguage: filters.DominantLanguage,
+		LicenseName:      filters.LicenseName,
+		LicenseKey:       filters.LicenseKey,
+		LicenseFamily:    filters.LicenseFamily,
+		Owner:            filters.Owner,
+		AllowForking:     filters.AllowForking,
+		HasOpenIssues:    filters.HasOpenIssues,
+		Size:             filters.Size,
+		ForksCount:       filters.ForksCount,
+		WatchersCount:    filters.WatchersCount,
+		OpenIssuesCount:  filters.OpenIssuesCount,
+		LanguageCount:    filters.LanguageCount,
+		Quality:          filters.Quality,
+		CreatedAt:        filters.CreatedAt,
+		UpdatedAt:        filters.UpdatedAt,
+		Query:            filters.Query,
+		Text:             filters.Text,
+	}, nil
+}
+
+// toFacets parses a comma separated list of RepoListFacetFields (the facets query parameter)
+// The fields are returned in RepoListFacetFields order without duplicates. An empty list returns nil (no facets).
+func toFacets(in string) ([]string, error) {
+	return parseFieldList(in, RepoListFacetFields, "facets")
+}
+
+// ParseRepoItemFields parses a comma separated list of RepoItemFields (the fields query parameter)
+// The fields are returned in RepoItemFields order without duplicates. An empty list returns nil (all fields).
+func ParseRepoItemFields(in string) ([]string, error) {
+	return parseFieldList(in, RepoItemFields, "fields")
+}
+
+// parseFieldList parses a comma separated list of the valid values of the named parameter
+// The values are returned in the order of valid without duplicates. An empty list returns nil.
+func parseFieldList(in string, valid []string, name string) ([]string, error) {
+	if strings.TrimSpace(in) == "" {
+		return nil, nil
+	}
+
+	requested := make(map[string]bool)
+	for _, field := range strings.Split(in, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !contains(valid, field) {
+			return nil, errors.Wrapf(
+				ErrInvalidParameter, "%s must be a list of %s, got %q", name, strings.Join(valid, ", "), field,
+			)
+		}
+		requested[field] = true
+	}
+
+	var out []string
+	for _, field := range valid {
+		if requested[field] {
+			out = append(out, field)
+		}
+	}
+	return out, nil
+}
+
+// contains reports whether the list contains the value
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// NextCursor returns the cursor for the page following the current one
+// It returns an empty string when there are no more pages, or when the next page is past MaxRepoListOffset
+func (g GetRepoListFilters) NextCursor(pageLen, total int) string {
+	next := g.Offset + pageLen
+	if pageLen == 0 || next >= total || next > MaxRepoListOffset {
+		return ""
+	}
+	return encodeCursor(next)
+}
+
+// encodeCursor encodes an offset into an opaque cursor
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.Itoa(offset)))
+}
+
+// decodeCursor decodes an opaque cursor into an offset. An empty cursor is the first page.
+func decodeCursor(in string) (int, error) {
+	if in == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(in)
+	if err != nil || !strings.HasPrefix(string(raw), cursorPrefix) {
+		return 0, errors.Wrapf(ErrInvalidParameter, "cursor %q", in)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), cursorPrefix))
+	if err != nil || offset < 0 || offset > MaxRepoListOffset {
+		return 0, errors.Wrapf(ErrInvalidParameter, "cursor %q", in)
+	}
+	return offset, nil
+}
+
+// toLimit converts a string to a page size, applying the default and maximum limits
+func toLimit(in string) (int, error) {
+	if in == "" {
+		return DefaultRepoListLimit, nil
+	}
+	limit, err := strconv.Atoi(in)
+	if err != nil || limit < 1 || limit > MaxRepoListLimit {
+		return 0, errors.Wrapf(ErrInvalidParameter, "limit must be between 1 and %d, got %q", MaxRepoListLimit, in)
+	}
+	return limit, nil
+}
 
-		// Note we could extend this with integer values.
-		// To be useful integer values would need range filters.
+// toText validates the full-text search: it must have at least one term that is not a stop word
+func toText(in string) (*string, error) {
+	text := toStr(strings.TrimSpace(in))
+	if text != nil && len(fulltext.Terms(*text)) == 0 {
+		return nil, errors.Wrapf(ErrInvalidParameter, "text must contain at least one searchable word, got %q", in)
 	}
+	return text, nil
+}
+
+// toLicenseFamily validates the license_family parameter: it must be one of licenses.Families
+func toLicenseFamily(in string) (*string, error) {
+	family := toStr(strings.ToLower(strings.TrimSpace(in)))
+	if family != nil && !licenses.IsFamily(*family) {
+		return nil, errors.Wrapf(
+			ErrInvalidParameter, "license_family must be one of %v, got %q", licenses.Families, in,
+		)
+	}
+	return family, nil
+}
+
+// toHighlight parses the highlight parameter, which requires a text search
+func toHighlight(in string, hasText bool) (bool, error) {
+	if in == "" {
+		return false, nil
+	}
+	highlight, err := strconv.ParseBool(in)
+	if err != nil {
+		return false, errors.Wrapf(ErrInvalidParameter, "highlight must be a boolean, got %q", in)
+	}
+	if highlight && !hasText {
+		return false, errors.Wrap(ErrInvalidParameter, "highlight requires a text search")
+	}
+	return highlight, nil
+}
+
+// toSortField validates the sort field, applying the default (relevance for a text search)
+func toSortField(in string, hasText bool) (string, error) {
+	if in == "" {
+		if hasText {
+			return RelevanceSort, nil
+		}
+		return DefaultRepoListSort, nil
+	}
+	if in == RelevanceSort && !hasText {
+		return "", errors.Wrap(ErrInvalidParameter, "sort=relevance requires a text search")
+	}
+	for _, field := range RepoListSortFields {
+		if in == field {
+			return in, nil
+		}
+	}
+	return "", errors.Wrapf(
+		ErrInvalidParameter, "sort must be one of %s, got %q", strings.Join(RepoListSortFields, ", "), in,
+	)
+}
+
+// toAscending converts the sort order to true for ascending (the default is descending)
+func toAscending(in string) (bool, error) {
+	switch strings.ToLower(in) {
+	case "", "desc":
+		return false, nil
+	case "asc":
+		return true, nil
+	}
+	return false, errors.Wrapf(ErrInvalidParameter, "order must be asc or desc, got %q", in)
+}
+
+// toIntRange reads the min_<field> and max_<field> query parameters into an IntRange
+func toIntRange(values url.Values, field string) (IntRange, error) {
+	var out IntRange
+	for _, bound := range []struct {
+		name string
+		dest **int
+	}{
+		{name: "min_" + field, dest: &out.Min},
+		{name: "max_" + field, dest: &out.Max},
+	} {
+		in := values.Get(bound.name)
+		if in == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(in)
+		if err != nil {
+			return IntRange{}, errors.Wrapf(ErrInvalidParameter, "%s must be an integer, got %q", bound.name, in)
+		}
+		*bound.dest = &parsed
+	}
+	return out, nil
+}
+
+// toTimeRange reads the <prefix>_after and <prefix>_before query parameters into a TimeRange
+func toTimeRange(values url.Values, prefix string) (TimeRange, error) {
+	var out TimeRange
+	for _, bound := range []struct {
+		name string
+		dest **time.Time
+	}{
+		{name: prefix + "_after", dest: &out.After},
+		{name: prefix + "_before", dest: &out.Before},
+	} {
+		in := values.Get(bound.name)
+		if in == "" {
+			continue
+		}
+		parsed, err := toTime(in)
+		if err != nil {
+			return TimeRange{}, errors.Wrapf(ErrInvalidParameter, "%s: %v", bound.name, err)
+		}
+		*bound.dest = &parsed
+	}
+	return out, nil
+}
+
+// toTime converts a string to a time. It accepts either
+//   - an RFC3339 timestamp (2024-01-02T15:04:05Z)
+//   - a duration relative to now (1h30m means 1 hour and 30 minutes ago)
+func toTime(in string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, in); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(in); err == nil {
+		return time.Now().Add(-d).Truncate(time.Second), nil
+	}
+	return time.Time{}, errors.Errorf("expected an RFC3339 time or a duration, got %q", in)
 }
 
 // toStr converts a string to a string pointer
@@ -43,6 +491,11 @@ func toStr(in string) *string {
 	return &in
 }
 
+// toLanguage converts a language name or alias to a pointer to its canonical name (see linguist.Canonical)
+func toLanguage(in string) *string {
+	return toStr(linguist.Canonical(in))
+}
+
 // toBool converts a string to a bool pointer
 func toBool(in string) *bool {
 	if in == "" {
@@ -54,3 +507,53 @@ func toBool(in string) *bool {
 	}
 	return &parsed
 }
+
+// strPtrKey formats a string pointer for use in a cache key
+func strPtrKey(in *string) string {
+	if in == nil {
+		return "<nil>"
+	}
+	return strconv.Quote(*in)
+}
+
+// cacheKey formats the range for use in a cache key
+func (r IntRange) cacheKey() string {
+	return fmt.Sprintf("[%s,%s]", intPtrKey(r.Min), intPtrKey(r.Max))
+}
+
+// cacheKey formats the range for use in a cache key
+func (r TimeRange) cacheKey() string {
+	return fmt.Sprintf("(%s,%s)", timePtrKey(r.After), timePtrKey(r.Before))
+}
+
+// intPtrKey formats an int pointer for use in a cache key
+func intPtrKey(in *int) string {
+	if in == nil {
+		return "<nil>"
+	}
+	return strconv.Itoa(*in)
+}
+
+// timePtrKey formats a time pointer for use in a cache key
+func timePtrKey(in *time.Time) string {
+	if in == nil {
+		return "<nil>"
+	}
+	return strconv.FormatInt(in.Unix(), 10)
+}
+
+// queryKey formats a search query for use in a cache key
+func queryKey(in query.Expr) string {
+	if in == nil {
+		return "<nil>"
+	}
+	return in.String()
+}
+
+// boolPtrKey formats a bool pointer for use in a cache key
+func boolPtrKey(in *bool) string {
+	if in == nil {
+		return "<nil>"
+	}
+	return strconv.FormatBool(*in)
+}
@@ -0,0 +1,88 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/linguist"
+	"github.com/pkg/errors"
+)
+
+// HistoryMetrics lists the values accepted by the metric query parameter of the stats history
+var HistoryMetrics = []string{"num_repos", "avg_forks", "avg_open_issues", "avg_size"}
+
+// DefaultHistoryRange is how far back the stats history goes when no from parameter is given
+const DefaultHistoryRange = 24 * time.Hour
+
+// HistoryFilters is a struct to hold the parameters for the GetStatsHistory usecase
+//   - Metric: the metric of the series, one of HistoryMetrics
+//   - Language: the primary language of the series
+//   - From, To: the time range of the series (inclusive)
+//   - Step: the width of the downsampling buckets, whose points are averaged (0 for the recorded points)
+type HistoryFilters struct {
+	Metric   string
+	Language string
+	From     time.Time
+	To       time.Time
+	Step     time.Duration
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (h HistoryFilters) CacheKey() string {
+	return fmt.Sprintf(
+		"%s-%s-%d-%d-%s", h.Metric, h.Language, h.From.UnixMilli(), h.To.UnixMilli(), h.Step,
+	)
+}
+
+// NewHistoryFilters creates a new HistoryFilters struct from the query parameters of a request
+// from and to accept the same values as the created_after parameter of /repos. They default to the last
+// DefaultHistoryRange.
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed
+func NewHistoryFilters(values url.Values) (HistoryFilters, error) {
+
+	metric := values.Get("metric")
+	if !contains(HistoryMetrics, metric) {
+		return HistoryFilters{}, errors.Wrapf(
+			ErrInvalidParameter, "metric must be one of %s, got %q", strings.Join(HistoryMetrics, ", "), metric,
+		)
+	}
+
+	language := linguist.Canonical(values.Get("language"))
+	if language == "" {
+		return HistoryFilters{}, errors.Wrap(ErrInvalidParameter, "language is required")
+	}
+
+	to := time.Now().Truncate(time.Second)
+	if in := values.Get("to"); in != "" {
+		parsed, err := toTime(in)
+		if err != nil {
+			return HistoryFilters{}, errors.Wrapf(ErrInvalidParameter, "to: %v", err)
+		}
+		to = parsed
+	}
+
+	from := to.Add(-DefaultHistoryRange)
+	if in := values.Get("from"); in != "" {
+		parsed, err := toTime(in)
+		if err != nil {
+			return HistoryFilters{}, errors.Wrapf(ErrInvalidParameter, "from: %v", err)
+		}
+		from = parsed
+	}
+	if to.Before(from) {
+		return HistoryFilters{}, errors.Wrap(ErrInvalidParameter, "from must be before to")
+	}
+
+	var step time.Duration
+	if in := values.Get("step"); in != "" {
+		parsed, err := time.ParseDuration(in)
+		if err != nil || parsed <= 0 {
+			return HistoryFilters{}, errors.Wrapf(ErrInvalidParameter, "step must be a positive duration, got %q", in)
+		}
+		step = parsed
+	}
+
+	return HistoryFilters{Metric: metric, Language: language, From: from, To: to, Step: step}, nil
+}
@@ -0,0 +1,47 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/linguist"
+	"github.com/pkg/errors"
+)
+
+// DefaultLanguageTopRepos is the number of top repositories of a language when no limit is requested
+const DefaultLanguageTopRepos = 10
+
+// MaxLanguageTopRepos is the largest number of top repositories of a language that may be requested
+const MaxLanguageTopRepos = 100
+
+// LanguageFilters is a struct to hold the parameters for the GetLanguage usecase
+//   - Name: the canonical name of the language (the name or an alias of the request, case-insensitive)
+//   - Limit: the number of top repositories using the language
+type LanguageFilters struct {
+	Name  string
+	Limit int
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (l LanguageFilters) CacheKey() string {
+	return fmt.Sprintf("%q-%d", l.Name, l.Limit)
+}
+
+// NewLanguageFilters creates a new LanguageFilters struct from the language name and the query parameters of a request
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed
+func NewLanguageFilters(name string, values url.Values) (LanguageFilters, error) {
+
+	limit := DefaultLanguageTopRepos
+	if in := values.Get("limit"); in != "" {
+		parsed, err := strconv.Atoi(in)
+		if err != nil || parsed < 1 || parsed > MaxLanguageTopRepos {
+			return LanguageFilters{}, errors.Wrapf(
+				ErrInvalidParameter, "limit must be an integer between 1 and %d, got %q", MaxLanguageTopRepos, in,
+			)
+		}
+		limit = parsed
+	}
+
+	return LanguageFilters{Name: linguist.Canonical(name), Limit: limit}, nil
+}
@@ -0,0 +1,58 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// LeaderboardMetrics lists the metrics the repositories can be ranked by (the {metric} of /leaderboards/{metric})
+var LeaderboardMetrics = []string{"forks", "watchers", "size", "open_issues", "language_count"}
+
+// DefaultLeaderboardLimit is the number of entries of a leaderboard when no limit is requested
+const DefaultLeaderboardLimit = 10
+
+// MaxLeaderboardLimit is the maximum number of entries of a leaderboard
+const MaxLeaderboardLimit = 100
+
+// LeaderboardFilters is a struct to hold the parameters for the GetLeaderboard usecase
+//   - Metric: the metric the repositories are ranked by, one of LeaderboardMetrics
+//   - Language: only rank the repositories with this primary language (nil for all)
+//   - Limit: the number of entries
+type LeaderboardFilters struct {
+	Metric   string
+	Language *string
+	Limit    int
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (l LeaderboardFilters) CacheKey() string {
+	return fmt.Sprintf("%s-%s-%d", l.Metric, strPtrKey(l.Language), l.Limit)
+}
+
+// NewLeaderboardFilters creates a new LeaderboardFilters struct from the metric and the query parameters of a request
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed
+func NewLeaderboardFilters(metric string, values url.Values) (LeaderboardFilters, error) {
+
+	if !contains(LeaderboardMetrics, metric) {
+		return LeaderboardFilters{}, errors.Wrapf(
+			ErrInvalidParameter, "metric must be one of %s, got %q", strings.Join(LeaderboardMetrics, ", "), metric,
+		)
+	}
+
+	limit := DefaultLeaderboardLimit
+	if in := values.Get("limit"); in != "" {
+		parsed, err := strconv.Atoi(in)
+		if err != nil || parsed < 1 || parsed > MaxLeaderboardLimit {
+			return LeaderboardFilters{}, errors.Wrapf(
+				ErrInvalidParameter, "limit must be an integer between 1 and %d, got %q", MaxLeaderboardLimit, in,
+			)
+		}
+		limit = parsed
+	}
+
+	return LeaderboardFilters{Metric: metric, Language: toLanguage(values.Get("language")), Limit: limit}, nil
+}
@@ -0,0 +1,50 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultOwnersLimit is the number of owners listed when no limit is requested
+const DefaultOwnersLimit = 100
+
+// MaxOwnersLimit is the largest number of owners that may be listed
+const MaxOwnersLimit = 1000
+
+// OwnersFilters is a struct to hold the parameters for the GetOwners usecase
+//   - Filters: the repositories to summarise by owner (the same filters as the stats)
+//   - Limit: the number of owners to list
+type OwnersFilters struct {
+	Filters GetRepoListFilters
+	Limit   int
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (o OwnersFilters) CacheKey() string {
+	return fmt.Sprintf("%s-%d", o.Filters.CacheKey(), o.Limit)
+}
+
+// NewOwnersFilters creates a new OwnersFilters struct from the query parameters of a request
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed
+func NewOwnersFilters(values url.Values) (OwnersFilters, error) {
+
+	filters, err := NewGetStatsFilters(values)
+	if err != nil {
+		return OwnersFilters{}, err
+	}
+
+	limit := DefaultOwnersLimit
+	if in := values.Get("limit"); in != "" {
+		limit, err = strconv.Atoi(in)
+		if err != nil || limit < 1 || limit > MaxOwnersLimit {
+			return OwnersFilters{}, errors.Wrapf(
+				ErrInvalidParameter, "limit must be an integer between 1 and %d, got %q", MaxOwnersLimit, in,
+			)
+		}
+	}
+
+	return OwnersFilters{Filters: filters, Limit: limit}, nil
+}
@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/pkg/errors"
+)
+
+// DefaultSimilarLimit is the number of similar repositories returned when no limit is requested
+const DefaultSimilarLimit = 10
+
+// SimilarFilters is a struct to hold the parameters for the GetSimilarRepos usecase
+//   - ID: the ID of the repository
+//   - Limit: the number of similar repositories (1 to db.MaxSimilarLimit)
+//   - LicenseWeight, SizeWeight: the weights of the license and the size in the similarity (the languages weigh the
+//     rest)
+type SimilarFilters struct {
+	ID            int64
+	Limit         int
+	LicenseWeight float64
+	SizeWeight    float64
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (s SimilarFilters) CacheKey() string {
+	return fmt.Sprintf("%d-%d-%g-%g", s.ID, s.Limit, s.LicenseWeight, s.SizeWeight)
+}
+
+// NewSimilarFilters creates a new SimilarFilters struct from the ID of the repository and the query parameters of a
+// request
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed
+func NewSimilarFilters(id int64, values url.Values) (SimilarFilters, error) {
+
+	limit := DefaultSimilarLimit
+	if in := values.Get("limit"); in != "" {
+		parsed, err := strconv.Atoi(in)
+		if err != nil || parsed < 1 || parsed > db.MaxSimilarLimit {
+			return SimilarFilters{}, errors.Wrapf(
+				ErrInvalidParameter, "limit must be an integer between 1 and %d, got %q", db.MaxSimilarLimit, in,
+			)
+		}
+		limit = parsed
+	}
+
+	licenseWeight, err := toWeight(values, "license_weight")
+	if err != nil {
+		return SimilarFilters{}, err
+	}
+	sizeWeight, err := toWeight(values, "size_weight")
+	if err != nil {
+		return SimilarFilters{}, err
+	}
+	if licenseWeight+sizeWeight > 1 {
+		return SimilarFilters{}, errors.Wrapf(
+			ErrInvalidParameter, "license_weight and size_weight must add up to at most 1, got %g",
+			licenseWeight+sizeWeight,
+		)
+	}
+
+	return SimilarFilters{ID: id, Limit: limit, LicenseWeight: licenseWeight, SizeWeight: sizeWeight}, nil
+}
+
+// toWeight parses the weight in the query parameter key, between 0 and 1 (0 when the parameter is not set)
+func toWeight(values url.Values, key string) (float64, error) {
+	in := values.Get(key)
+	if in == "" {
+		return 0, nil
+	}
+	weight, err := strconv.ParseFloat(in, 64)
+	if err != nil || !(weight >= 0 && weight <= 1) {
+		return 0, errors.Wrapf(ErrInvalidParameter, "%s must be a number between 0 and 1, got %q", key, in)
+	}
+	return weight, nil
+}
@@ -0,0 +1,49 @@
+package standard
+
+import (
+	"context"
+
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+)
+
+// GetOwners returns the first filters.Limit owners of the repositories matching the filters, by descending number
+// of repositories, and the total number of owners
+func (s Standard) GetOwners(ctx context.Context, filters usecases.OwnersFilters) ([]entities.OwnerSummary, int, error) {
+	owners, err := s.db.GetOwners(ctx, convertFiltersU2D(filters.Filters))
+	if err != nil {
+		return nil, 0, convertStatsErrorD2U(err)
+	}
+
+	total := len(owners)
+	if len(owners) > filters.Limit {
+		owners = owners[:filters.Limit]
+	}
+	return owners, total, nil
+}
+
+// GetOwner returns the repositories of an owner, newest first, with the statistics of their languages
+// It returns ErrNotFound if the owner has no repository
+func (s Standard) GetOwner(ctx context.Context, login string) (entities.Owner, error) {
+	filters := db.GetRepoListFilters{Owner: &login}
+
+	page, err := s.db.GetRepoList(ctx, filters)
+	if err != nil {
+		return entities.Owner{}, convertStatsErrorD2U(err)
+	}
+	if len(page.Items) == 0 {
+		return entities.Owner{}, usecases.ErrNotFound
+	}
+
+	languages, err := s.db.GetLanguageStats(ctx, filters)
+	if err != nil {
+		return entities.Owner{}, convertStatsErrorD2U(err)
+	}
+
+	return entities.Owner{
+		Summary:   db.ComputeOwners(db.GroupItems(page.Items))[0],
+		Items:     page.Items,
+		Languages: languages,
+	}, nil
+}
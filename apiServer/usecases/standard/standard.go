@@ -6,7 +6,9 @@ import (
 	"github.com/Scalingo/sclng-backend-test-v1/apiServer/config"
 	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/fulltext"
 	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,46 +21,306 @@ type Standard struct {
 }
 
 func (s Standard) GetRepoListFiltered(ctx context.Context, filters usecases.GetRepoListFilters) (
-	entities.RepoList, error,
+	entities.RepoPage, error,
 ) {
 
-	list, err := s.db.GetRepoList(
-		ctx, db.GetRepoListFilters{
-			Name:          filters.Name,
-			Language:      filters.Language,
-			License:       filters.License,
-			AllowForking:  filters.AllowForking,
-			HasOpenIssues: filters.HasOpenIssues,
+	page, err := s.db.GetRepoList(ctx, convertFiltersU2D(filters))
+	if errors.Is(err, db.ErrInvalidFilter) {
+		return entities.RepoPage{}, errors.Wrap(usecases.ErrInvalidParameter, err.Error())
+	}
+	if err != nil {
+		return entities.RepoPage{}, err
+	}
+
+	page.NextCursor = filters.NextCursor(len(page.Items), page.Total)
+
+	if filters.Highlight && filters.Text != nil {
+		highlightHits(page, fulltext.Terms(*filters.Text))
+	}
+
+	return page, nil
+}
+
+// highlightSnippetWords is the number of words in a highlighted snippet of the description
+const highlightSnippetWords = 20
+
+// highlightHits adds the highlighted name and description snippet of each item of the page to its search hit
+// The fields without a match are not highlighted
+func highlightHits(page entities.RepoPage, terms []string) {
+	for _, item := range page.Items {
+		highlights := map[string]string{}
+		if name := fulltext.Highlight(item.Name, terms, 0); name != "" {
+			highlights["name"] = name
+		}
+		if description := fulltext.Highlight(item.Description, terms, highlightSnippetWords); description != "" {
+			highlights["description"] = description
+		}
+		hit := page.Hits[item.ID]
+		hit.Highlights = highlights
+		page.Hits[item.ID] = hit
+	}
+}
+
+func (s Standard) GetRepoItem(ctx context.Context, repoID int64) (entities.RepoItem, error) {
+
+	item, err := s.db.GetRepoItem(ctx, repoID)
+	if errors.Is(err, db.ErrNotFound) {
+		return entities.RepoItem{}, errors.Wrapf(usecases.ErrNotFound, "repository %d", repoID)
+	}
+	if err != nil {
+		return entities.RepoItem{}, err
+	}
+
+	return item, nil
+}
+
+// GetSimilarRepos returns the repositories most similar to a repository, by descending similarity
+// It returns ErrNotFound if the repository does not exist
+func (s Standard) GetSimilarRepos(ctx context.Context, filters usecases.SimilarFilters) (
+	[]entities.SimilarRepo, error,
+) {
+	similar, err := s.db.GetSimilarRepos(
+		ctx, db.SimilarQuery{
+			ID:            filters.ID,
+			Limit:         filters.Limit,
+			LicenseWeight: filters.LicenseWeight,
+			SizeWeight:    filters.SizeWeight,
 		},
 	)
+	if errors.Is(err, db.ErrNotFound) {
+		return nil, errors.Wrapf(usecases.ErrNotFound, "repository %d", filters.ID)
+	}
 	if err != nil {
-		return entities.RepoList{}, err
+		return nil, convertStatsErrorD2U(err)
 	}
+	return similar, nil
+}
+
+func (s Standard) GetSuggestions(ctx context.Context, filters usecases.GetSuggestionsFilters) (
+	[]entities.Suggestion, error,
+) {
+	return s.db.GetSuggestions(ctx, db.SuggestField(filters.Field), filters.Prefix, filters.Limit)
+}
 
-	return list, nil
+func (s Standard) GetStats(ctx context.Context, filters usecases.GetRepoListFilters) (entities.Stats, error) {
+	out, err := db.CollectStats(ctx, s.db, convertFiltersU2D(filters))
+	if err != nil {
+		return entities.Stats{}, convertStatsErrorD2U(err)
+	}
+	return out, nil
 }
 
-func (s Standard) GetStats(ctx context.Context) (entities.Stats, error) {
-	var err error
-	out := entities.Stats{}
+func (s Standard) GetLanguageStats(ctx context.Context, filters usecases.GetRepoListFilters) (
+	entities.LanguageStats, error,
+) {
+	stats, err := s.db.GetLanguageStats(ctx, convertFiltersU2D(filters))
+	if err != nil {
+		return entities.LanguageStats{}, convertStatsErrorD2U(err)
+	}
+	return stats, nil
+}
 
-	if out.AvgNumForksPerRepoByLanguage, err = s.db.GetAvgNumForksPerRepoByLanguage(ctx); err != nil {
-		return entities.Stats{}, err
+func (s Standard) GetLanguages(ctx context.Context, filters usecases.GetRepoListFilters) (
+	[]entities.LanguageSummary, error,
+) {
+	languages, err := s.db.GetLanguages(ctx, convertFiltersU2D(filters))
+	if err != nil {
+		return nil, convertStatsErrorD2U(err)
 	}
+	return languages, nil
+}
 
-	if out.NumReposByLanguage, err = s.db.GetNumReposByLanguage(ctx); err != nil {
-		return entities.Stats{}, err
+func (s Standard) GetLicenseStats(ctx context.Context, filters usecases.GetRepoListFilters) (
+	entities.LicenseStats, error,
+) {
+	stats, err := s.db.GetLicenseStats(ctx, convertFiltersU2D(filters))
+	if err != nil {
+		return entities.LicenseStats{}, convertStatsErrorD2U(err)
 	}
+	return stats, nil
+}
 
-	if out.AvgNumOpenIssuesByLanguage, err = s.db.GetAvgNumOpenIssuesByLanguage(ctx); err != nil {
-		return entities.Stats{}, err
+func (s Standard) GetLanguage(ctx context.Context, filters usecases.LanguageFilters) (entities.LanguageDetail, error) {
+	detail, err := s.db.GetLanguage(ctx, filters.Name, filters.Limit)
+	if errors.Is(err, db.ErrNotFound) {
+		return entities.LanguageDetail{}, usecases.ErrNotFound
 	}
+	if err != nil {
+		return entities.LanguageDetail{}, convertStatsErrorD2U(err)
+	}
+	return detail, nil
+}
 
-	if out.AvgSizeByLanguage, err = s.db.GetAvgSizeByLanguage(ctx); err != nil {
-		return entities.Stats{}, err
+func (s Standard) GetLanguageCooccurrence(ctx context.Context, filters usecases.CooccurrenceFilters) (
+	entities.LanguageCooccurrence, error,
+) {
+	cooccurrence, err := s.db.GetLanguageCooccurrence(ctx, convertFiltersU2D(filters.Filters), filters.MinBytes)
+	if err != nil {
+		return entities.LanguageCooccurrence{}, convertStatsErrorD2U(err)
 	}
+	return cooccurrence, nil
+}
 
-	return out, nil
+func (s Standard) GetDistributions(ctx context.Context, filters usecases.DistributionFilters) (
+	entities.Distributions, error,
+) {
+	distributions, err := s.db.GetDistributions(
+		ctx, db.DistributionSpec{
+			Filters:         convertFiltersU2D(filters.Filters),
+			Fields:          filters.Fields,
+			Buckets:         filters.Buckets,
+			CreatedInterval: db.TimeInterval(filters.CreatedInterval),
+		},
+	)
+	if err != nil {
+		return entities.Distributions{}, convertStatsErrorD2U(err)
+	}
+	return distributions, nil
+}
+
+func (s Standard) Aggregate(ctx context.Context, filters usecases.AggregateFilters) (
+	[]entities.AggregateGroup, error,
+) {
+	groups, err := s.db.Aggregate(ctx, convertAggregateU2D(filters))
+	if err != nil {
+		return nil, convertStatsErrorD2U(err)
+	}
+	return groups, nil
+}
+
+func (s Standard) GetLeaderboard(ctx context.Context, filters usecases.LeaderboardFilters) (
+	[]entities.LeaderboardEntry, error,
+) {
+	entries, err := s.db.GetLeaderboard(
+		ctx, db.LeaderboardQuery{
+			Metric:   db.LeaderboardMetric(filters.Metric),
+			Language: filters.Language,
+			Limit:    filters.Limit,
+		},
+	)
+	if err != nil {
+		return nil, convertStatsErrorD2U(err)
+	}
+	return entries, nil
+}
+
+func (s Standard) GetStatsHistory(ctx context.Context, filters usecases.HistoryFilters) (
+	[]entities.HistoryPoint, error,
+) {
+	points, err := s.db.GetStatsHistory(ctx, convertHistoryFiltersU2D(filters))
+	if err != nil {
+		return nil, convertStatsErrorD2U(err)
+	}
+	return points, nil
+}
+
+// convertStatsErrorD2U converts the filter errors of the db to ErrInvalidParameter
+func convertStatsErrorD2U(err error) error {
+	if errors.Is(err, db.ErrInvalidFilter) {
+		return errors.Wrap(usecases.ErrInvalidParameter, err.Error())
+	}
+	return err
+}
+
+// convertFiltersU2D converts the filters from the usecases layer to those of the db interface
+func convertFiltersU2D(in usecases.GetRepoListFilters) db.GetRepoListFilters {
+	return db.GetRepoListFilters{
+		Name:             in.Name,
+		Language:         in.Language,
+		DominantLanguage: in.DominantLanguage,
+		License:          in.LicenseName,
+		LicenseKey:       in.LicenseKey,
+		LicenseFamily:    in.LicenseFamily,
+		Owner:            in.Owner,
+		AllowForking:     in.AllowForking,
+		HasOpenIssues:    in.HasOpenIssues,
+		Size:             db.IntRange(in.Size),
+		ForksCount:       db.IntRange(in.ForksCount),
+		WatchersCount:    db.IntRange(in.WatchersCount),
+		OpenIssuesCount:  db.IntRange(in.OpenIssuesCount),
+		LanguageCount:    db.IntRange(in.LanguageCount),
+		Quality:          db.IntRange(in.Quality),
+		CreatedAt:        db.TimeRange(in.CreatedAt),
+		UpdatedAt:        db.TimeRange(in.UpdatedAt),
+		Query:            in.Query,
+		Text:             in.Text,
+		Offset:           in.Offset,
+		Limit:            in.Limit,
+		SortBy:           db.SortField(in.SortBy),
+		SortAscending:    in.SortAscending,
+		Facets:           convertFacetsU2D(in.Facets),
+		Fields:           convertFieldsU2D(in),
+	}
+}
+
+// convertFieldsU2D returns the fields to load from the db: the sparse fieldset, plus the highlighted fields, the
+// license key of the license family and the percentages of the languages
+func convertFieldsU2D(in usecases.GetRepoListFilters) []string {
+	if in.Fields == nil {
+		return nil
+	}
+	loaded := map[string]bool{}
+	for _, field := range in.Fields {
+		loaded[field] = true
+	}
+
+	var needed []string
+	if in.Highlight {
+		needed = append(needed, "name", "description")
+	}
+	if loaded["license_family"] {
+		// The family is not stored in the items, it is derived from the license key
+		needed = append(needed, "license_key")
+	}
+	if loaded["languages"] {
+		// The percentages of the languages are stored apart from the breakdown
+		needed = append(needed, "language_percentages")
+	}
+
+	fields := append([]string{}, in.Fields...)
+	for _, field := range needed {
+		if !loaded[field] {
+			fields = append(fields, field)
+			loaded[field] = true
+		}
+	}
+	return fields
+}
+
+// convertAggregateU2D converts the aggregation from the usecases layer to the spec of the db interface
+func convertAggregateU2D(in usecases.AggregateFilters) db.AggregateSpec {
+	metrics := make([]db.AggregateMetric, len(in.Metrics))
+	for i, m := range in.Metrics {
+		metrics[i] = db.AggregateMetric{Func: db.AggregateFunc(m.Func), Field: m.Field}
+	}
+	return db.AggregateSpec{
+		Filters: convertFiltersU2D(in.Filters),
+		GroupBy: db.AggregateGroupField(in.GroupBy),
+		Metrics: metrics,
+	}
+}
+
+// convertFacetsU2D converts the facet fields from the usecases layer to those of the db interface
+func convertFacetsU2D(in []string) []db.FacetField {
+	if in == nil {
+		return nil
+	}
+	out := make([]db.FacetField, len(in))
+	for i, v := range in {
+		out[i] = db.FacetField(v)
+	}
+	return out
+}
+
+// convertHistoryFiltersU2D converts the history filters from the usecases layer to the db history query
+func convertHistoryFiltersU2D(in usecases.HistoryFilters) db.HistoryQuery {
+	return db.HistoryQuery{
+		Metric:   db.HistoryMetric(in.Metric),
+		Language: in.Language,
+		From:     in.From,
+		To:       in.To,
+		Step:     in.Step,
+	}
 }
 
 func New(
@@ -0,0 +1,99 @@
+package standard
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/apiServer/usecases"
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+)
+
+// GetLanguageTrends ranks the primary languages by the growth of their share of the repositories created in the
+// window ending now, compared to the previous window
+func (s Standard) GetLanguageTrends(ctx context.Context, filters usecases.TrendsFilters) (
+	entities.LanguageTrends, error,
+) {
+	start := time.Now().Truncate(time.Second).Add(-filters.Window)
+	previousStart := start.Add(-filters.Window)
+
+	// The bounds of the time ranges are exclusive, so the current window starts just before start
+	current := convertFiltersU2D(filters.Filters)
+	currentAfter := start.Add(-time.Nanosecond)
+	current.CreatedAt.After = &currentAfter
+	counts, err := s.db.GetNumReposByLanguage(ctx, current)
+	if err != nil {
+		return entities.LanguageTrends{}, convertStatsErrorD2U(err)
+	}
+
+	previous := convertFiltersU2D(filters.Filters)
+	previousAfter := previousStart.Add(-time.Nanosecond)
+	previous.CreatedAt.After, previous.CreatedAt.Before = &previousAfter, &start
+	previousCounts, err := s.db.GetNumReposByLanguage(ctx, previous)
+	if err != nil {
+		return entities.LanguageTrends{}, convertStatsErrorD2U(err)
+	}
+
+	out := computeLanguageTrends(counts, previousCounts)
+	out.Window, out.Start = filters.Window, start
+	return out, nil
+}
+
+// computeLanguageTrends compares the number of repositories by primary language of two windows
+// The repositories without a language count in the totals, but are not ranked.
+func computeLanguageTrends(counts, previousCounts map[string]int) entities.LanguageTrends {
+	out := entities.LanguageTrends{Languages: []entities.LanguageTrend{}}
+	for _, count := range counts {
+		out.Repos += count
+	}
+	for _, count := range previousCounts {
+		out.PreviousRepos += count
+	}
+
+	share := func(count, total int) float64 {
+		if total == 0 {
+			return 0
+		}
+		return float64(count) / float64(total)
+	}
+
+	languages := map[string]bool{}
+	for language := range counts {
+		languages[language] = true
+	}
+	for language := range previousCounts {
+		languages[language] = true
+	}
+	for language := range languages {
+		if language == "" {
+			continue
+		}
+		trend := entities.LanguageTrend{
+			Language:      language,
+			Count:         counts[language],
+			PreviousCount: previousCounts[language],
+			Share:         share(counts[language], out.Repos),
+			PreviousShare: share(previousCounts[language], out.PreviousRepos),
+		}
+		trend.ShareChange = trend.Share - trend.PreviousShare
+		if trend.PreviousCount > 0 {
+			change := float64(trend.Count-trend.PreviousCount) / float64(trend.PreviousCount) * 100
+			trend.PercentChange = &change
+		}
+		out.Languages = append(out.Languages, trend)
+	}
+
+	sort.Slice(
+		out.Languages, func(i, j int) bool {
+			a, b := out.Languages[i], out.Languages[j]
+			if a.ShareChange != b.ShareChange {
+				return a.ShareChange > b.ShareChange
+			}
+			if a.Count != b.Count {
+				return a.Count > b.Count
+			}
+			return a.Language < b.Language
+		},
+	)
+	return out
+}
@@ -0,0 +1,65 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultSuggestLimit is the number of suggestions returned when no limit is requested
+	DefaultSuggestLimit = 10
+	// MaxSuggestLimit is the largest limit that may be requested
+	MaxSuggestLimit = 100
+)
+
+// SuggestFields lists the values accepted by the field query parameter of the suggestions
+var SuggestFields = []string{"name", "owner", "language", "license"}
+
+// GetSuggestionsFilters is a struct to hold the parameters for the GetSuggestions usecase
+//   - Field: the field to suggest values for (one of SuggestFields)
+//   - Prefix: the start of the values (case-insensitive)
+//   - Limit: the maximum number of suggestions
+type GetSuggestionsFilters struct {
+	Field  string
+	Prefix string
+	Limit  int
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (g GetSuggestionsFilters) CacheKey() string {
+	return fmt.Sprintf("%s-%s-%d", g.Field, strconv.Quote(strings.ToLower(g.Prefix)), g.Limit)
+}
+
+// NewGetSuggestionsFilters creates a new GetSuggestionsFilters struct from the query parameters of a request
+// It returns an error wrapping ErrInvalidParameter if a parameter is missing or cannot be parsed
+func NewGetSuggestionsFilters(values url.Values) (GetSuggestionsFilters, error) {
+
+	field := values.Get("field")
+	if !contains(SuggestFields, field) {
+		return GetSuggestionsFilters{}, errors.Wrapf(
+			ErrInvalidParameter, "field must be one of %s, got %q", strings.Join(SuggestFields, ", "), field,
+		)
+	}
+
+	prefix := strings.TrimSpace(values.Get("prefix"))
+	if prefix == "" {
+		return GetSuggestionsFilters{}, errors.Wrap(ErrInvalidParameter, "prefix is required")
+	}
+
+	limit := DefaultSuggestLimit
+	if in := values.Get("limit"); in != "" {
+		var err error
+		limit, err = strconv.Atoi(in)
+		if err != nil || limit < 1 || limit > MaxSuggestLimit {
+			return GetSuggestionsFilters{}, errors.Wrapf(
+				ErrInvalidParameter, "limit must be between 1 and %d, got %q", MaxSuggestLimit, in,
+			)
+		}
+	}
+
+	return GetSuggestionsFilters{Field: field, Prefix: prefix, Limit: limit}, nil
+}
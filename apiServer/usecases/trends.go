@@ -0,0 +1,57 @@
+package usecases
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultTrendsWindow is the window of the language trends when no window is requested
+const DefaultTrendsWindow = 24 * time.Hour
+
+// MaxTrendsWindow is the largest window of the language trends
+const MaxTrendsWindow = 365 * 24 * time.Hour
+
+// TrendsFilters is a struct to hold the parameters for the GetLanguageTrends usecase
+//   - Filters: the repositories to count (the same filters as the stats, without the created_at range)
+//   - Window: the width of the current window, ending now, and of the previous window it is compared to
+type TrendsFilters struct {
+	Filters GetRepoListFilters
+	Window  time.Duration
+}
+
+// CacheKey returns a string that can be used as a cache key for the filters
+func (t TrendsFilters) CacheKey() string {
+	return fmt.Sprintf("%s-%s", t.Filters.CacheKey(), t.Window)
+}
+
+// NewTrendsFilters creates a new TrendsFilters struct from the query parameters of a request
+// The windows are ranges of created_at, so the created_after and created_before parameters are rejected.
+// It returns an error wrapping ErrInvalidParameter if a parameter cannot be parsed
+func NewTrendsFilters(values url.Values) (TrendsFilters, error) {
+
+	for _, name := range []string{"created_after", "created_before"} {
+		if values.Get(name) != "" {
+			return TrendsFilters{}, errors.Wrapf(ErrInvalidParameter, "%s cannot be used with the trends window", name)
+		}
+	}
+
+	filters, err := NewGetStatsFilters(values)
+	if err != nil {
+		return TrendsFilters{}, err
+	}
+
+	window := DefaultTrendsWindow
+	if in := values.Get("window"); in != "" {
+		window, err = time.ParseDuration(in)
+		if err != nil || window <= 0 || window > MaxTrendsWindow {
+			return TrendsFilters{}, errors.Wrapf(
+				ErrInvalidParameter, "window must be a positive duration up to %s, got %q", MaxTrendsWindow, in,
+			)
+		}
+	}
+
+	return TrendsFilters{Filters: filters, Window: window}, nil
+}
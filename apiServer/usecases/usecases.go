@@ -6,7 +6,30 @@ import (
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
 )
 
+var ErrInvalidParameter = UsecaseError("invalid parameter")
+var ErrNotFound = UsecaseError("not found")
+
 type Usecases interface {
-	GetRepoListFiltered(ctx context.Context, filters GetRepoListFilters) (entities.RepoList, error)
-	GetStats(ctx context.Context) (entities.Stats, error)
+	GetRepoListFiltered(ctx context.Context, filters GetRepoListFilters) (entities.RepoPage, error)
+	GetRepoItem(ctx context.Context, repoID int64) (entities.RepoItem, error)
+	GetSimilarRepos(ctx context.Context, filters SimilarFilters) ([]entities.SimilarRepo, error)
+	GetSuggestions(ctx context.Context, filters GetSuggestionsFilters) ([]entities.Suggestion, error)
+	GetStats(ctx context.Context, filters GetRepoListFilters) (entities.Stats, error)
+	GetLanguageStats(ctx context.Context, filters GetRepoListFilters) (entities.LanguageStats, error)
+	GetLanguageCooccurrence(ctx context.Context, filters CooccurrenceFilters) (entities.LanguageCooccurrence, error)
+	GetDistributions(ctx context.Context, filters DistributionFilters) (entities.Distributions, error)
+	GetLanguages(ctx context.Context, filters GetRepoListFilters) ([]entities.LanguageSummary, error)
+	GetLicenseStats(ctx context.Context, filters GetRepoListFilters) (entities.LicenseStats, error)
+	GetLanguage(ctx context.Context, filters LanguageFilters) (entities.LanguageDetail, error)
+	GetOwners(ctx context.Context, filters OwnersFilters) ([]entities.OwnerSummary, int, error)
+	GetOwner(ctx context.Context, login string) (entities.Owner, error)
+	GetLeaderboard(ctx context.Context, filters LeaderboardFilters) ([]entities.LeaderboardEntry, error)
+	GetLanguageTrends(ctx context.Context, filters TrendsFilters) (entities.LanguageTrends, error)
+	GetStatsHistory(ctx context.Context, filters HistoryFilters) ([]entities.HistoryPoint, error)
+	Aggregate(ctx context.Context, filters AggregateFilters) ([]entities.AggregateGroup, error)
 }
+
+// UsecaseError is a custom error type for usecase errors
+type UsecaseError string
+
+func (e UsecaseError) Error() string { return string(e) }
@@ -13,6 +13,34 @@ func (v RepoList) Less(i, j int) bool {
 	return v[j].CreatedAt.Before(v[i].CreatedAt)
 }
 
+// RepoPage is a single page of a RepoList
+//   - Items: the items in the page
+//   - Total: the number of items matching the search (across all pages)
+//   - NextCursor: an opaque cursor to request the next page (empty on the last page)
+//   - Facets: the facet counts over all the matching items (nil when no facets are requested)
+//   - Hits: the full-text search results of the items in the page, by item ID (nil without a text search)
+type RepoPage struct {
+	Items      RepoList
+	Total      int
+	NextCursor string
+	Facets     Facets
+	Hits       map[int64]SearchHit
+}
+
+// SearchHit is the full-text search result of an item
+//   - Score: the relevance of the item (higher is more relevant)
+//   - Highlights: snippets of the matching fields with the matching words highlighted, by field name
+type SearchHit struct {
+	Score      float64
+	Highlights map[string]string
+}
+
+// Facets maps a facet field (language, license, owner) to the number of items for each value of the field
+type Facets map[string]FacetCounts
+
+// FacetCounts maps the values of a facet field to the number of items with that value
+type FacetCounts map[string]int
+
 // RepoItem is a representation of a GitHub repository.
 type RepoItem struct {
 	ID              int64
@@ -28,6 +56,7 @@ type RepoItem struct {
 	Language        string
 	Languages       Languages
 	LicenseName     string
+	LicenseKey      string
 	ForksCount      int
 	OpenIssuesCount int
 	WatchersCount   int
@@ -38,6 +67,39 @@ type RepoItem struct {
 	HasWiki         bool
 	HasPages        bool
 	HasDiscussions  bool
+
+	// Fields derived from the languages breakdown when it is stored (see db.WithLanguages)
+	//  - LanguageCount: the number of languages of the breakdown
+	//  - DominantLanguage: the language of the breakdown with the most bytes of code
+	//  - LanguageDerived: Language is the dominant language, as GitHub reports no primary language for the repository
+	//  - LanguagePercentages: the percentage of the bytes of code of each language of the breakdown (0 to 100)
+	LanguageCount       int
+	DominantLanguage    string
+	LanguageDerived     bool
+	LanguagePercentages map[string]float64
+
+	// Quality is the quality score of the item, computed when it is stored (see quality.Compute)
+	Quality Quality
+}
+
+// Quality holds the quality score of an item and the contribution of each factor to it
+//   - Score: the score, from 0 to 100 (the sum of the points of the factors, rounded)
+//   - Factors: the factors, in quality.Factors order
+type Quality struct {
+	Score   int
+	Factors []QualityFactor
+}
+
+// QualityFactor holds the contribution of a signal to the quality score of an item
+//   - Name: the name of the factor (see quality.Factors)
+//   - Weight: the share of the score of the factor, from 0 to 100 (the weights of the factors add up to 100)
+//   - Value: how well the item meets the factor, from 0 to 1
+//   - Points: the points of the factor in the score (Weight * Value)
+type QualityFactor struct {
+	Name   string
+	Weight float64
+	Value  float64
+	Points float64
 }
 
 // Languages is a map of languages used in a repository.
@@ -51,9 +113,251 @@ func (v Languages) Strings() []string {
 	return langs
 }
 
+// Dominant returns the language with the most bytes of code (ties by name), or an empty string without languages
+func (v Languages) Dominant() string {
+	var dominant string
+	for lang, bytes := range v {
+		if dominant == "" || bytes > v[dominant] || (bytes == v[dominant] && lang < dominant) {
+			dominant = lang
+		}
+	}
+	return dominant
+}
+
+// Percentages returns the percentage of the bytes of code of each language (0 to 100)
+// The percentages are 0 when the languages have no bytes.
+func (v Languages) Percentages() map[string]float64 {
+	var total int64
+	for _, bytes := range v {
+		total += bytes
+	}
+
+	out := make(map[string]float64, len(v))
+	for lang, bytes := range v {
+		var percent float64
+		if total > 0 {
+			percent = 100 * float64(bytes) / float64(total)
+		}
+		out[lang] = percent
+	}
+	return out
+}
+
+// Suggestion is a suggested value for a search field
+//   - Value: the value of the field
+//   - Count: the number of repositories with the value
+type Suggestion struct {
+	Value string
+	Count int
+}
+
+// AggregateGroup holds the metrics of a group of items sharing the value of the group-by field
+//   - Value: the value of the group-by field (booleans are "true" or "false")
+//   - Metrics: the value of each metric, by metric name (e.g. "avg:forks_count")
+type AggregateGroup struct {
+	Value   string
+	Metrics map[string]float64
+}
+
+// LanguageStats holds the statistics of the languages breakdowns of a set of items
+//   - TotalBytes: the number of bytes of code of all the languages
+//   - Repos: the number of items with a languages breakdown
+//   - Languages: the statistics of each language, by descending number of bytes
+type LanguageStats struct {
+	TotalBytes int64
+	Repos      int
+	Languages  []LanguageStat
+}
+
+// LanguageStat holds the statistics of a language over the languages breakdowns of a set of items
+//   - Bytes: the number of bytes of code in the language
+//   - Share: the share of the bytes of all the languages (0 to 1)
+//   - Repos: the number of items using the language
+//   - MeanShare: the mean share of the language within the items using it (0 to 1)
+type LanguageStat struct {
+	Language  string
+	Bytes     int64
+	Share     float64
+	Repos     int
+	MeanShare float64
+}
+
+// LanguageCooccurrence holds the number of items using each pair of languages together
+//   - Repos: the number of items using at least one language
+//   - Pairs: the pairs of languages used together, by descending count
+type LanguageCooccurrence struct {
+	Repos int
+	Pairs []LanguagePair
+}
+
+// LanguagePair holds the co-occurrence of two languages (A < B)
+//   - Count: the number of items using both languages
+//   - Lift: how much more often the languages are used together than if they were independent (1 is independent)
+//   - Jaccard: the number of items using both languages over the number of items using either (0 to 1)
+type LanguagePair struct {
+	A       string
+	B       string
+	Count   int
+	Lift    float64
+	Jaccard float64
+}
+
+// LanguageSummary holds the usage of a language in the items
+//   - PrimaryRepos: the number of items with the language as primary language
+//   - Repos: the number of items using the language (primary or in the languages breakdown)
+//   - Bytes: the bytes of code of the language in the languages breakdowns
+//   - AvgSize: the average size of the items using the language
+type LanguageSummary struct {
+	Name         string
+	PrimaryRepos int
+	Repos        int
+	Bytes        int64
+	AvgSize      float64
+}
+
+// LanguageDetail holds the usage of a language and the top items using it
+//   - TopRepos: the items using the language, by descending watchers
+type LanguageDetail struct {
+	Summary  LanguageSummary
+	TopRepos RepoList
+}
+
+// OwnerSummary holds the summary of the items of an owner
+//   - Repos: the number of items
+//   - TotalSize: the sum of the sizes of the items
+//   - DominantLanguage: the language with the most bytes of code in the items (see db.DominantLanguage)
+type OwnerSummary struct {
+	Login            string
+	Repos            int
+	TotalSize        int
+	DominantLanguage string
+}
+
+// Owner holds the items of an owner
+//   - Items: the items, newest first
+//   - Languages: the statistics of the languages breakdowns of the items
+type Owner struct {
+	Summary   OwnerSummary
+	Items     RepoList
+	Languages LanguageStats
+}
+
+// LeaderboardEntry holds a ranked item of a leaderboard
+//   - Item: the item, with only the fields needed to display it
+//   - Value: the value of the metric the item is ranked by
+type LeaderboardEntry struct {
+	Item  RepoItem
+	Value int
+}
+
+// SimilarRepo holds an item similar to a repository
+//   - Item: the similar item
+//   - Score: the similarity to the repository, between 0 and 1 (the languages mixed with the license and the size)
+//   - LanguageSimilarity: the cosine similarity of the languages breakdowns, between 0 and 1
+type SimilarRepo struct {
+	Item               RepoItem
+	Score              float64
+	LanguageSimilarity float64
+}
+
+// LanguageTrends holds the growth of the languages in the items created in a window compared to the previous window
+//   - Start: the start of the current window (the previous window ends there)
+//   - Repos, PreviousRepos: the number of items created in the current and previous windows
+//   - Languages: the primary languages, by descending ShareChange
+type LanguageTrends struct {
+	Window        time.Duration
+	Start         time.Time
+	Repos         int
+	PreviousRepos int
+	Languages     []LanguageTrend
+}
+
+// LanguageTrend holds the growth of a primary language between two windows
+//   - Count, PreviousCount: the number of items created in the current and previous windows
+//   - Share, PreviousShare: the share of the items created in the current and previous windows (0 to 1)
+//   - ShareChange: Share - PreviousShare
+//   - PercentChange: the percent change from PreviousCount to Count (nil when PreviousCount is 0)
+type LanguageTrend struct {
+	Language      string
+	Count         int
+	PreviousCount int
+	Share         float64
+	PreviousShare float64
+	ShareChange   float64
+	PercentChange *float64
+}
+
+// Distributions holds the distributions of the numeric fields of a set of items
+//   - ByLanguage: the distribution of each field, by primary language then by field name
+//   - CreatedAt: the number of items created in each time interval, in ascending order (nil when not requested)
+type Distributions struct {
+	ByLanguage map[string]map[string]Distribution
+	CreatedAt  []TimeBucket
+}
+
+// Distribution holds the distribution of the values of a numeric field
+//   - Count: the number of values
+//   - Median, P90, P99: the 50th, 90th and 99th percentiles
+//   - Histogram: the number of values in each bucket (nil when not requested)
+type Distribution struct {
+	Count     int
+	Min       float64
+	Max       float64
+	Median    float64
+	P90       float64
+	P99       float64
+	Histogram []HistogramBucket
+}
+
+// HistogramBucket holds the number of values in [Min, Max). A nil Max is unbounded.
+type HistogramBucket struct {
+	Min   int
+	Max   *int
+	Count int
+}
+
+// TimeBucket holds the number of items in the time interval starting at Start
+type TimeBucket struct {
+	Start time.Time
+	Count int
+}
+
+// HistoryPoint is the value of a series of the stats history at a time
+type HistoryPoint struct {
+	Time  time.Time
+	Value float64
+}
+
 type Stats struct {
 	AvgNumForksPerRepoByLanguage map[string]float32
 	AvgNumOpenIssuesByLanguage   map[string]float32
 	AvgSizeByLanguage            map[string]float32
 	NumReposByLanguage           map[string]int
 }
+
+// LicenseCount holds the number of items with a license
+//   - Key: the SPDX key of the license
+//   - Name: the name of the license
+//   - Family: the family of the license (see licenses.Families)
+type LicenseCount struct {
+	Key    string
+	Name   string
+	Family string
+	Count  int
+}
+
+// FamilyCount holds the number of items with a license of a family (see licenses.Families)
+type FamilyCount struct {
+	Family string
+	Count  int
+}
+
+// LicenseStats holds the licenses of the items
+//   - Total: the number of items
+//   - Licenses: the number of items with each license, excluding the items without license
+//   - Families: the number of items in each family, including the items without license
+type LicenseStats struct {
+	Total    int
+	Licenses []LicenseCount
+	Families []FamilyCount
+}
@@ -0,0 +1,55 @@
+// Package fulltext holds the text analysis shared by the full-text search of the db backends:
+// tokenizing, stop words, stemming and highlighting.
+// The rules approximate the ones RediSearch applies to TEXT fields (default separators, default stop words and an
+// English stemmer), so the memory backend and the highlights behave like the redis search.
+package fulltext
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are the words that are not indexed or searched (the default stop words of RediSearch)
+var stopWords = map[string]bool{
+	"a": true, "is": true, "the": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "if": true, "in": true, "into": true, "it": true, "no": true, "not": true,
+	"of": true, "on": true, "or": true, "such": true, "that": true, "their": true, "then": true, "there": true,
+	"these": true, "they": true, "this": true, "to": true, "was": true, "will": true, "with": true,
+}
+
+// Tokenize splits the text into lower case words
+// Words are separated by any rune that is not a letter, a digit or an underscore
+func Tokenize(text string) []string {
+	words := strings.FieldsFunc(text, isSeparator)
+	for i, word := range words {
+		words[i] = strings.ToLower(word)
+	}
+	return words
+}
+
+// Terms returns the distinct stemmed search terms of a text search, without the stop words
+// A text search with no terms can never match
+func Terms(text string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, word := range Tokenize(text) {
+		if stopWords[word] {
+			continue
+		}
+		term := Stem(word)
+		if !seen[term] {
+			seen[term] = true
+			out = append(out, term)
+		}
+	}
+	return out
+}
+
+// IsStopWord reports whether the word is ignored by the search
+func IsStopWord(word string) bool {
+	return stopWords[strings.ToLower(word)]
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
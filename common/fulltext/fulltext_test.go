@@ -0,0 +1,90 @@
+package fulltext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{word: "parse", want: "pars"},
+		{word: "parses", want: "pars"},
+		{word: "parsing", want: "pars"},
+		{word: "parsed", want: "pars"},
+		{word: "parsers", want: "parser"},
+		{word: "Libraries", want: "librari"},
+		{word: "classes", want: "class"},
+		{word: "running", want: "run"},
+		{word: "quickly", want: "quick"},
+		{word: "status", want: "status"},
+		{word: "tool", want: "tool"},
+		{word: "go", want: "go"},
+	}
+	for _, tt := range tests {
+		if got := Stem(tt.word); got != tt.want {
+			t.Errorf("Stem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestTerms(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "A fast JSON parser", want: []string{"fast", "json", "parser"}},
+		{text: "parsing, parse & parsed", want: []string{"pars"}},
+		{text: "the and of", want: nil},
+		{text: "", want: nil},
+	}
+	for _, tt := range tests {
+		if got := Terms(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Terms(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		maxWords int
+		want     string
+	}{
+		{
+			name:  "Whole text",
+			text:  "Parsing JSON, fast.",
+			terms: []string{"pars", "fast"},
+			want:  "<em>Parsing</em> JSON, <em>fast</em>.",
+		},
+		{
+			name:     "Snippet",
+			text:     "one two three four five six seven parser eight nine ten eleven",
+			terms:    []string{"parser"},
+			maxWords: 5,
+			want:     "...five six seven <em>parser</em> eight...",
+		},
+		{
+			name:     "Snippet at the end",
+			text:     "one two three four five six seven parser",
+			terms:    []string{"parser"},
+			maxWords: 4,
+			want:     "...five six seven <em>parser</em>",
+		},
+		{name: "HTML", text: "<b>fast</b> & safe", terms: []string{"fast"}, want: "&lt;b&gt;<em>fast</em>&lt;/b&gt; &amp; safe"},
+		{name: "No match", text: "a json tool", terms: []string{"pars"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if got := Highlight(tt.text, tt.terms, tt.maxWords); got != tt.want {
+					t.Errorf("Highlight() = %q, want %q", got, tt.want)
+				}
+			},
+		)
+	}
+}
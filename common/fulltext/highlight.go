@@ -0,0 +1,94 @@
+package fulltext
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// HighlightStart and HighlightEnd surround the matching words in a highlighted snippet
+	HighlightStart = "<em>"
+	HighlightEnd   = "</em>"
+
+	// snippetContext is the number of words kept before the first match in a snippet
+	snippetContext = 3
+)
+
+// Highlight returns the text with the words matching the search terms surrounded by HighlightStart and HighlightEnd
+// When maxWords > 0 the text is cut to a snippet of maxWords words around the first match, with "..." marking the
+// cuts. The text is HTML escaped, so the snippet is safe to render as HTML. It returns an empty string when no word
+// matches.
+func Highlight(text string, terms []string, maxWords int) string {
+	isTerm := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		isTerm[term] = true
+	}
+
+	// Find the words (with their byte offsets) and the matching ones
+	type word struct {
+		start, end int
+		match      bool
+	}
+	var words []word
+	firstMatch := -1
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if isSeparator(r) {
+			i += size
+			continue
+		}
+		start := i
+		for i < len(text) {
+			r, size = utf8.DecodeRuneInString(text[i:])
+			if isSeparator(r) {
+				break
+			}
+			i += size
+		}
+		w := text[start:i]
+		match := !IsStopWord(w) && isTerm[Stem(w)]
+		if match && firstMatch < 0 {
+			firstMatch = len(words)
+		}
+		words = append(words, word{start: start, end: i, match: match})
+	}
+	if firstMatch < 0 {
+		return ""
+	}
+
+	// The window of words in the snippet
+	from, to := 0, len(words)
+	if maxWords > 0 && len(words) > maxWords {
+		from = firstMatch - snippetContext
+		if from < 0 {
+			from = 0
+		}
+		to = from + maxWords
+		if to > len(words) {
+			to, from = len(words), len(words)-maxWords
+		}
+	}
+
+	var sb strings.Builder
+	pos := 0
+	if from > 0 {
+		sb.WriteString("...")
+		pos = words[from].start
+	}
+	for _, w := range words[from:to] {
+		sb.WriteString(html.EscapeString(text[pos:w.start]))
+		if w.match {
+			sb.WriteString(HighlightStart + html.EscapeString(text[w.start:w.end]) + HighlightEnd)
+		} else {
+			sb.WriteString(html.EscapeString(text[w.start:w.end]))
+		}
+		pos = w.end
+	}
+	if to < len(words) {
+		sb.WriteString("...")
+	} else {
+		sb.WriteString(html.EscapeString(text[pos:]))
+	}
+	return sb.String()
+}
@@ -0,0 +1,55 @@
+package fulltext
+
+import (
+	"strings"
+)
+
+// Stem reduces an English word to its stem, so that the forms of a word match each other (parse, parses, parsing)
+// It is a light stemmer implementing a subset of the Porter rules: plurals, -ed, -ing and -ly suffixes, a final e
+// and double consonants. Words of 3 letters or less are not stemmed.
+func Stem(word string) string {
+	w := strings.ToLower(word)
+	if len([]rune(w)) <= 3 {
+		return w
+	}
+
+	// Plurals
+	switch {
+	case strings.HasSuffix(w, "sses"):
+		w = w[:len(w)-2]
+	case strings.HasSuffix(w, "ies"):
+		w = w[:len(w)-3] + "i"
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"):
+	case strings.HasSuffix(w, "s"):
+		w = w[:len(w)-1]
+	}
+
+	// Verb and adverb suffixes, when the remaining stem has a vowel
+	for _, suffix := range []string{"ingly", "edly", "ing", "ed", "ly"} {
+		stem := strings.TrimSuffix(w, suffix)
+		if stem != w && len(stem) >= 3 && hasVowel(stem) {
+			w = stem
+			break
+		}
+	}
+
+	// A final e (parse and parsing share the stem pars)
+	if len(w) > 4 && strings.HasSuffix(w, "e") {
+		w = w[:len(w)-1]
+	}
+
+	// A double consonant (running -> runn -> run)
+	if n := len(w); n > 3 && w[n-1] == w[n-2] && isConsonant(w[n-1]) && !strings.ContainsRune("lsz", rune(w[n-1])) {
+		w = w[:n-1]
+	}
+
+	return w
+}
+
+func hasVowel(s string) bool {
+	return strings.ContainsAny(s, "aeiouy")
+}
+
+func isConsonant(c byte) bool {
+	return c >= 'a' && c <= 'z' && !strings.ContainsRune("aeiou", rune(c))
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/pkg/errors"
+)
+
+// AggregateGroupField is a field the repositories can be grouped by in an aggregation
+type AggregateGroupField string
+
+const (
+	GroupByLanguage       AggregateGroupField = "language" // the primary language
+	GroupByLicense        AggregateGroupField = "license"
+	GroupByOwner          AggregateGroupField = "owner"
+	GroupByAllowForking   AggregateGroupField = "allow_forking"
+	GroupByHasIssues      AggregateGroupField = "has_issues"
+	GroupByHasProjects    AggregateGroupField = "has_projects"
+	GroupByHasDownloads   AggregateGroupField = "has_downloads"
+	GroupByHasWiki        AggregateGroupField = "has_wiki"
+	GroupByHasPages       AggregateGroupField = "has_pages"
+	GroupByHasDiscussions AggregateGroupField = "has_discussions"
+)
+
+// AggregateGroupFields lists the valid AggregateGroupField values
+var AggregateGroupFields = []AggregateGroupField{
+	GroupByLanguage, GroupByLicense, GroupByOwner, GroupByAllowForking, GroupByHasIssues, GroupByHasProjects,
+	GroupByHasDownloads, GroupByHasWiki, GroupByHasPages, GroupByHasDiscussions,
+}
+
+// AggregateFunc is a function computing a metric over a group of repositories
+type AggregateFunc string
+
+const (
+	AggregateCount AggregateFunc = "count" // the number of repositories (takes no field)
+	AggregateSum   AggregateFunc = "sum"
+	AggregateAvg   AggregateFunc = "avg"
+	AggregateMin   AggregateFunc = "min"
+	AggregateMax   AggregateFunc = "max"
+)
+
+// AggregateFuncs lists the valid AggregateFunc values
+var AggregateFuncs = []AggregateFunc{AggregateCount, AggregateSum, AggregateAvg, AggregateMin, AggregateMax}
+
+// AggregateMetricFields lists the numeric fields the metrics can be computed over
+var AggregateMetricFields = []string{"size", "forks_count", "watchers_count", "open_issues_count"}
+
+// MaxAggregateGroups is the maximum number of groups returned by an aggregation
+const MaxAggregateGroups = 1000
+
+// AggregateMetric is a metric computed over each group: a function of a numeric field
+type AggregateMetric struct {
+	Func  AggregateFunc
+	Field string // empty for AggregateCount
+}
+
+// Name returns the name of the metric in the results, e.g. "avg:forks_count" or "count"
+func (m AggregateMetric) Name() string {
+	if m.Func == AggregateCount {
+		return string(AggregateCount)
+	}
+	return fmt.Sprintf("%s:%s", m.Func, m.Field)
+}
+
+// AggregateSpec is an aggregation of the repositories matching the filters:
+// the repositories are grouped by the value of GroupBy, and the metrics are computed over each group
+// (the pagination, sorting, facets and fields of the filters are ignored)
+type AggregateSpec struct {
+	Filters GetRepoListFilters
+	GroupBy AggregateGroupField
+	Metrics []AggregateMetric
+}
+
+// Validate checks the group-by field and the metrics of the spec
+// It returns an error wrapping ErrInvalidFilter if the spec cannot be computed
+func (s AggregateSpec) Validate() error {
+	if !contains(AggregateGroupFields, s.GroupBy) {
+		return errors.Wrapf(ErrInvalidFilter, "unknown group-by field %q", s.GroupBy)
+	}
+	if len(s.Metrics) == 0 {
+		return errors.Wrap(ErrInvalidFilter, "at least one metric is required")
+	}
+	for _, m := range s.Metrics {
+		switch {
+		case !contains(AggregateFuncs, m.Func):
+			return errors.Wrapf(ErrInvalidFilter, "unknown aggregate function %q", m.Func)
+		case m.Func == AggregateCount && m.Field != "":
+			return errors.Wrapf(ErrInvalidFilter, "%s takes no field, got %q", m.Func, m.Field)
+		case m.Func != AggregateCount && !contains(AggregateMetricFields, m.Field):
+			return errors.Wrapf(ErrInvalidFilter, "unknown metric field %q", m.Field)
+		}
+	}
+	return nil
+}
+
+// SortAggregateGroups orders the groups by value and keeps the first MaxAggregateGroups
+func SortAggregateGroups(in []entities.AggregateGroup) []entities.AggregateGroup {
+	sort.Slice(in, func(i, j int) bool { return in[i].Value < in[j].Value })
+	if len(in) > MaxAggregateGroups {
+		in = in[:MaxAggregateGroups]
+	}
+	return in
+}
+
+// contains reports whether the list holds the value
+func contains[T comparable](list []T, value T) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package dbRedis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// aggregateGroupAlias is the alias of the group-by value in the aggregation pipeline
+const aggregateGroupAlias = "group_value"
+
+// Aggregate groups the repositories matching the filters of the spec and computes the metrics of each group
+// The spec is compiled to a single FT.AGGREGATE: the group-by field and the metric fields are loaded with JSON
+// paths, then grouped with one reducer per metric. Documents without a group-by value are not grouped.
+func (c *DBServiceRedis) Aggregate(ctx context.Context, spec db.AggregateSpec) ([]entities.AggregateGroup, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	query, err := buildQueryFromFilters(spec.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	// LOAD <count> $.<group> AS group_value $.<field> AS <field> ...
+	load := []interface{}{"LOAD", 0, "$." + string(spec.GroupBy), "AS", aggregateGroupAlias}
+	loaded := map[string]bool{}
+	for _, m := range spec.Metrics {
+		if m.Field != "" && !loaded[m.Field] {
+			loaded[m.Field] = true
+			load = append(load, "$."+m.Field, "AS", m.Field)
+		}
+	}
+	load[1] = len(load) - 2
+
+	// GROUPBY 1 @group_value REDUCE <FUNC> <nargs> [@<field>] AS m<i> ...
+	groupBy := []interface{}{"GROUPBY", 1, "@" + aggregateGroupAlias}
+	for i, m := range spec.Metrics {
+		alias := "m" + strconv.Itoa(i)
+		if m.Func == db.AggregateCount {
+			groupBy = append(groupBy, "REDUCE", "COUNT", 0, "AS", alias)
+		} else {
+			groupBy = append(groupBy, "REDUCE", strings.ToUpper(string(m.Func)), 1, "@"+m.Field, "AS", alias)
+		}
+	}
+
+	args := []interface{}{"FT.AGGREGATE", repoIndex, query}
+	args = append(args, load...)
+	args = append(args, groupBy...)
+	args = append(args, "SORTBY", 2, "@"+aggregateGroupAlias, "ASC", "MAX", db.MaxAggregateGroups)
+
+	res, err := c.pool.Do(ctx, args...).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error aggregating repos")
+	}
+
+	// Decode the returned data
+	var list struct {
+		Results []struct {
+			Extra_Attributes map[string]interface{}
+		}
+	}
+	err = mapstructure.Decode(res, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]entities.AggregateGroup, 0, len(list.Results))
+	for _, row := range list.Results {
+		value, _ := row.Extra_Attributes[aggregateGroupAlias].(string)
+		if value == "" {
+			continue
+		}
+		group := entities.AggregateGroup{Value: value, Metrics: make(map[string]float64, len(spec.Metrics))}
+		for i, m := range spec.Metrics {
+			str, _ := row.Extra_Attributes["m"+strconv.Itoa(i)].(string)
+			metric, err := strconv.ParseFloat(str, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Error decoding the metric %q of group %q", m.Name(), value)
+			}
+			group.Metrics[m.Name()] = metric
+		}
+		out = append(out, group)
+	}
+
+	return db.SortAggregateGroups(out), nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/licenses"
 	"github.com/pkg/errors"
 )
 
@@ -32,6 +33,7 @@ func ConvertRepoItemE2I(e entities.RepoItem) (RepoItem, error) {
 		Language:        e.Language,
 		Languages:       &iLangs,
 		LicenseName:     e.LicenseName,
+		LicenseKey:      e.LicenseKey,
 		ForksCount:      e.ForksCount,
 		OpenIssuesCount: e.OpenIssuesCount,
 		WatchersCount:   e.WatchersCount,
@@ -42,9 +44,38 @@ func ConvertRepoItemE2I(e entities.RepoItem) (RepoItem, error) {
 		HasWiki:         e.HasWiki,
 		HasPages:        e.HasPages,
 		HasDiscussions:  e.HasDiscussions,
+
+		LanguageCount:       e.LanguageCount,
+		DominantLanguage:    e.DominantLanguage,
+		LanguageDerived:     e.LanguageDerived,
+		LanguagePercentages: e.LanguagePercentages,
+		Quality:             ConvertQualityE2I(e.Quality),
+
+		AllLanguages:   allLanguages(e),
+		LicenseFamily:  string(licenses.FamilyOf(e.LicenseKey)),
+		CreatedAtUnix:  e.CreatedAt.Unix(),
+		UpdatedAtUnix:  e.UpdatedAt.Unix(),
+		LanguageVector: languageVector(e.Languages),
 	}, nil
 }
 
+// allLanguages combines the primary language and the languages of the breakdown into a single list for searching
+func allLanguages(e entities.RepoItem) []string {
+	out := e.Languages.Strings()
+	if e.Language != "" {
+		out = append(out, e.Language)
+	}
+	return out
+}
+
+func ConvertQualityE2I(e entities.Quality) Quality {
+	out := Quality{Score: e.Score, Factors: make([]QualityFactor, len(e.Factors))}
+	for i, f := range e.Factors {
+		out.Factors[i] = QualityFactor{Name: f.Name, Weight: f.Weight, Value: f.Value, Points: f.Points}
+	}
+	return out
+}
+
 func ConvertLanguagesE2I(e entities.Languages) (string, error) {
 	jsonData, err := json.Marshal(e)
 	if err != nil {
@@ -83,6 +114,7 @@ func ConvertRepoItemI2E(i RepoItem) (entities.RepoItem, error) {
 		Language:        i.Language,
 		Languages:       eLangs,
 		LicenseName:     i.LicenseName,
+		LicenseKey:      i.LicenseKey,
 		ForksCount:      i.ForksCount,
 		OpenIssuesCount: i.OpenIssuesCount,
 		WatchersCount:   i.WatchersCount,
@@ -93,6 +125,12 @@ func ConvertRepoItemI2E(i RepoItem) (entities.RepoItem, error) {
 		HasWiki:         i.HasWiki,
 		HasPages:        i.HasPages,
 		HasDiscussions:  i.HasDiscussions,
+
+		LanguageCount:       i.LanguageCount,
+		DominantLanguage:    i.DominantLanguage,
+		LanguageDerived:     i.LanguageDerived,
+		LanguagePercentages: i.LanguagePercentages,
+		Quality:             ConvertQualityI2E(i.Quality),
 	}, nil
 }
 
@@ -108,3 +146,14 @@ func ConvertLanguagesI2E(i *string) (entities.Languages, error) {
 	}
 	return out, nil
 }
+
+func ConvertQualityI2E(i Quality) entities.Quality {
+	out := entities.Quality{Score: i.Score}
+	if i.Factors != nil {
+		out.Factors = make([]entities.QualityFactor, len(i.Factors))
+	}
+	for j, f := range i.Factors {
+		out.Factors[j] = entities.QualityFactor{Name: f.Name, Weight: f.Weight, Value: f.Value, Points: f.Points}
+	}
+	return out
+}
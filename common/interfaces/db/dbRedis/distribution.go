@@ -0,0 +1,258 @@
+package dbRedis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	qb "github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db/dbRedis/queryBuilder"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// GetDistributions computes the distributions of the spec over the repositories matching its filters
+//   - the count, min, max and percentiles of all the fields are computed by a single FT.AGGREGATE grouped by
+//     language, with QUANTILE reducers (RediSearch estimates the quantiles of large groups)
+//   - each histogram bucket is counted by a FT.AGGREGATE restricted to the range of the bucket
+//   - the created_at histogram groups the documents by the start of their interval, with APPLY
+//
+// Documents without a primary language are not grouped by language.
+func (c *DBServiceRedis) GetDistributions(ctx context.Context, spec db.DistributionSpec) (
+	entities.Distributions, error,
+) {
+	if err := spec.Validate(); err != nil {
+		return entities.Distributions{}, err
+	}
+
+	query, err := buildQueryFromFilters(spec.Filters)
+	if err != nil {
+		return entities.Distributions{}, err
+	}
+
+	out := entities.Distributions{}
+	out.ByLanguage, err = c.getDistributionsByLanguage(ctx, query, spec.Fields)
+	if err != nil {
+		return entities.Distributions{}, err
+	}
+
+	if len(spec.Buckets) > 0 {
+		for _, field := range spec.Fields {
+			if err := c.getHistograms(ctx, query, field, spec.Buckets, out.ByLanguage); err != nil {
+				return entities.Distributions{}, err
+			}
+		}
+	}
+
+	if spec.CreatedInterval != "" {
+		out.CreatedAt, err = c.getTimeHistogram(ctx, query, "created_at", spec.CreatedInterval)
+		if err != nil {
+			return entities.Distributions{}, err
+		}
+	}
+
+	return out, nil
+}
+
+// getDistributionsByLanguage computes the count, min, max and percentiles of the fields by language
+func (c *DBServiceRedis) getDistributionsByLanguage(ctx context.Context, query string, fields []string) (
+	map[string]map[string]entities.Distribution, error,
+) {
+	args := []interface{}{"FT.AGGREGATE", repoIndex, query, "LOAD", len(fields)}
+	for _, field := range fields {
+		args = append(args, "@"+field)
+	}
+	args = append(args, "GROUPBY", 1, "@language", "REDUCE", "COUNT", 0, "AS", "count")
+	for _, field := range fields {
+		args = append(
+			args,
+			"REDUCE", "MIN", 1, "@"+field, "AS", field+"_min",
+			"REDUCE", "MAX", 1, "@"+field, "AS", field+"_max",
+			"REDUCE", "QUANTILE", 2, "@"+field, 0.5, "AS", field+"_p50",
+			"REDUCE", "QUANTILE", 2, "@"+field, 0.9, "AS", field+"_p90",
+			"REDUCE", "QUANTILE", 2, "@"+field, 0.99, "AS", field+"_p99",
+		)
+	}
+	args = append(args, "LIMIT", 0, db.MaxAggregateGroups)
+
+	rows, err := c.aggregateRows(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]map[string]entities.Distribution{}
+	for _, row := range rows {
+		language := row["language"]
+		if language == "" {
+			continue
+		}
+		count, err := strconv.Atoi(row["count"])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding the count of language %q", language)
+		}
+
+		out[language] = make(map[string]entities.Distribution, len(fields))
+		for _, field := range fields {
+			d := entities.Distribution{Count: count}
+			for _, v := range []struct {
+				suffix string
+				dst    *float64
+			}{
+				{"_min", &d.Min}, {"_max", &d.Max}, {"_p50", &d.Median}, {"_p90", &d.P90}, {"_p99", &d.P99},
+			} {
+				if *v.dst, err = strconv.ParseFloat(row[field+v.suffix], 64); err != nil {
+					return nil, errors.Wrapf(err, "Error decoding %s%s of language %q", field, v.suffix, language)
+				}
+			}
+			out[language][field] = d
+		}
+	}
+	return out, nil
+}
+
+// getHistograms counts the values of the field in each bucket, by language, into the distributions
+func (c *DBServiceRedis) getHistograms(
+	ctx context.Context, query, field string, buckets []int, distributions map[string]map[string]entities.Distribution,
+) error {
+
+	// Start with empty histograms
+	for language, byField := range distributions {
+		d := byField[field]
+		d.Histogram = make([]entities.HistogramBucket, len(buckets))
+		for i := range buckets {
+			d.Histogram[i] = entities.HistogramBucket{Min: buckets[i]}
+			if i+1 < len(buckets) {
+				max := buckets[i+1]
+				d.Histogram[i].Max = &max
+			}
+		}
+		distributions[language][field] = d
+	}
+
+	// Count each bucket [min, max) over the documents matching the query
+	for i := range buckets {
+		var max *float64
+		if i+1 < len(buckets) {
+			max = floatPtr(float64(buckets[i+1]))
+		}
+		clause, err := qb.Range(field, floatPtr(float64(buckets[i])), max, false, true)
+		if err != nil {
+			return err
+		}
+		if query != string(qb.All) {
+			// The query was built by the query builder
+			clause = qb.And(qb.Clause(query), clause)
+		}
+
+		rows, err := c.aggregateRows(
+			ctx, "FT.AGGREGATE", repoIndex, string(clause), "GROUPBY", 1, "@language",
+			"REDUCE", "COUNT", 0, "AS", "count", "LIMIT", 0, db.MaxAggregateGroups,
+		)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			byField, ok := distributions[row["language"]]
+			if !ok {
+				continue
+			}
+			count, err := strconv.Atoi(row["count"])
+			if err != nil {
+				return errors.Wrapf(err, "Error decoding the histogram of %s", field)
+			}
+			byField[field].Histogram[i].Count = count
+		}
+	}
+	return nil
+}
+
+// timeFunctions maps the time intervals to the RediSearch functions rounding a unix time to the start of the interval
+var timeFunctions = map[db.TimeInterval]string{
+	db.IntervalHour:  "hour",
+	db.IntervalDay:   "day",
+	db.IntervalMonth: "month",
+}
+
+// getTimeHistogram counts the documents matching the query in each interval of the (unix time) attribute
+func (c *DBServiceRedis) getTimeHistogram(ctx context.Context, query, attribute string, interval db.TimeInterval) (
+	[]entities.TimeBucket, error,
+) {
+	rows, err := c.aggregateRows(
+		ctx, "FT.AGGREGATE", repoIndex, query, "LOAD", 1, "@"+attribute,
+		"APPLY", timeFunctions[interval]+"(@"+attribute+")", "AS", "start",
+		"GROUPBY", 1, "@start", "REDUCE", "COUNT", 0, "AS", "count",
+		"SORTBY", 2, "@start", "ASC", "MAX", db.MaxTimeBuckets,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]entities.TimeBucket, 0, len(rows))
+	for _, row := range rows {
+		start, err := strconv.ParseInt(row["start"], 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding the start of a %s bucket", interval)
+		}
+		count, err := strconv.Atoi(row["count"])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding the count of a %s bucket", interval)
+		}
+		out = append(out, entities.TimeBucket{Start: time.Unix(start, 0).UTC(), Count: count})
+	}
+	return out, nil
+}
+
+// aggregateRows runs a FT.AGGREGATE and returns the attributes of each row
+// Rows with a non-string attribute (e.g. a missing value) have an empty string for it
+func (c *DBServiceRedis) aggregateRows(ctx context.Context, args ...interface{}) ([]map[string]string, error) {
+	res, err := c.pool.Do(ctx, args...).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error aggregating repos")
+	}
+
+	// Decode the returned data
+	var list struct {
+		Results []struct {
+			Extra_Attributes map[string]interface{}
+		}
+	}
+	err = mapstructure.Decode(res, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]map[string]string, len(list.Results))
+	for i, row := range list.Results {
+		out[i] = make(map[string]string, len(row.Extra_Attributes))
+		for key, value := range row.Extra_Attributes {
+			out[i][key], _ = value.(string)
+		}
+	}
+	return out, nil
+}
+
+// aggregateGroups runs a FT.AGGREGATE grouping by the keys and returns the attributes of every group (see
+// aggregateRows)
+// The groups are read by pages of searchPageSize, sorted by the keys so that the pages do not overlap.
+func (c *DBServiceRedis) aggregateGroups(ctx context.Context, keys []string, args ...interface{}) (
+	[]map[string]string, error,
+) {
+	sortBy := []interface{}{"SORTBY", 2 * len(keys)}
+	for _, key := range keys {
+		sortBy = append(sortBy, "@"+key, "ASC")
+	}
+
+	var out []map[string]string
+	for offset := 0; ; offset += searchPageSize {
+		page := append(append(args[:len(args):len(args)], sortBy...), "LIMIT", offset, searchPageSize)
+		rows, err := c.aggregateRows(ctx, page...)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rows...)
+		if len(rows) < searchPageSize {
+			return out, nil
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package dbRedis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// facetPaths maps the facet fields to the JSON paths of the values in the documents
+var facetPaths = map[db.FacetField]string{
+	db.FacetByLanguage: "$.language",
+	db.FacetByLicense:  "$.license",
+	db.FacetByOwner:    "$.owner",
+}
+
+// getFacets counts the values of each facet field over the documents matching the query
+func (c *DBServiceRedis) getFacets(ctx context.Context, query string, fields []db.FacetField) (entities.Facets, error) {
+	out := entities.Facets{}
+	for _, field := range fields {
+		counts, err := c.getFacetCounts(ctx, query, field)
+		if err != nil {
+			return nil, err
+		}
+		out[string(field)] = counts
+	}
+	return out, nil
+}
+
+// getFacetCounts counts the values of a facet field over the documents matching the query
+// The values are grouped with FT.AGGREGATE, keeping the db.MaxFacetValues most frequent values
+func (c *DBServiceRedis) getFacetCounts(ctx context.Context, query string, field db.FacetField) (
+	entities.FacetCounts, error,
+) {
+	path, ok := facetPaths[field]
+	if !ok {
+		return nil, errors.Errorf("unknown facet field %q", field)
+	}
+
+	res, err := c.pool.Do(
+		ctx, "FT.AGGREGATE", repoIndex, query, "LOAD", "3", path, "AS", "value",
+		"GROUPBY", "1", "@value", "REDUCE", "COUNT", "0", "AS", "count",
+		"SORTBY", "2", "@count", "DESC", "MAX", db.MaxFacetValues,
+	).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error aggregating facets")
+	}
+
+	// Decode the returned data
+	var list struct {
+		Results []struct {
+			Extra_Attributes map[string]interface{}
+		}
+	}
+	err = mapstructure.Decode(res, &list)
+	if err != nil {
+		return nil, err
+	}
+
+	out := entities.FacetCounts{}
+	for _, row := range list.Results {
+		value, _ := row.Extra_Attributes["value"].(string)
+		countStr, _ := row.Extra_Attributes["count"].(string)
+		if value == "" {
+			continue
+		}
+		count, err := strconv.Atoi(countStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding the count of facet %q", field)
+		}
+		out[value] = count
+	}
+
+	return out, nil
+}
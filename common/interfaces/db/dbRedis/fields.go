@@ -0,0 +1,105 @@
+package dbRedis
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// docFields maps the json keys of the RepoItem document to true when the value is a JSON string.
+// FT.SEARCH RETURN returns JSON strings unquoted, so they are quoted again to rebuild the document.
+var docFields = map[string]bool{}
+
+func init() {
+	t := reflect.TypeOf(RepoItem{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key := strings.Split(f.Tag.Get("json"), ",")[0]
+		ft := f.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		docFields[key] = ft.Kind() == reflect.String || ft == reflect.TypeOf(time.Time{})
+	}
+}
+
+// searchFields searches the index for the documents matching the query, in the sortBy order (or by relevance)
+// Only the requested fields (and the id) are loaded from the documents, using the RETURN option with JSON paths.
+// It returns the partial documents in the page [offset, offset+limit), their scores by ID (when requested), and the
+// total number of matching documents.
+func (c *DBServiceRedis) searchFields(ctx context.Context, s search, offset, limit int) (
+	RepoList, map[int64]float64, int, error,
+) {
+
+	// Build the RETURN arguments: RETURN <count> $.<field> AS <field> ...
+	returnArgs := []interface{}{"RETURN", 0}
+	for _, field := range append([]string{"id"}, s.fields...) {
+		if _, ok := docFields[field]; !ok {
+			return nil, nil, 0, errors.Wrapf(db.ErrInvalidFilter, "unknown field %q", field)
+		}
+		returnArgs = append(returnArgs, "$."+field, "AS", field)
+	}
+	returnArgs[1] = len(returnArgs) - 2
+
+	res, err := c.pool.Do(ctx, append(s.args(offset, limit), returnArgs...)...).Result()
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "Error searching for repos")
+	}
+
+	// Decode the returned data
+	var list searchResults
+	err = mapstructure.Decode(res, &list)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	repoList := make(RepoList, len(list.Results))
+	scores := make(map[int64]float64, len(list.Results))
+	for i, result := range list.Results {
+		if repoList[i], err = decodePartialDoc(result.Extra_Attributes); err != nil {
+			return nil, nil, 0, err
+		}
+		scores[repoList[i].ID] = result.Score
+	}
+
+	return repoList, scores, list.Total_Results, nil
+}
+
+// decodePartialDoc rebuilds a RepoItem document from the attributes returned by FT.SEARCH RETURN
+// The fields that were not returned keep their zero value
+func decodePartialDoc(attributes map[string]interface{}) (RepoItem, error) {
+	raw := make(map[string]json.RawMessage, len(attributes))
+	for key, value := range attributes {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if docFields[key] {
+			quoted, err := json.Marshal(str)
+			if err != nil {
+				return RepoItem{}, errors.Wrap(err, "Error marshaling JSON")
+			}
+			raw[key] = quoted
+		} else {
+			raw[key] = json.RawMessage(str)
+		}
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return RepoItem{}, errors.Wrap(err, "Error marshaling JSON")
+	}
+
+	var doc RepoItem
+	err = json.Unmarshal(data, &doc)
+	if err != nil {
+		return RepoItem{}, errors.Wrap(err, "Error unmarshaling document")
+	}
+	return doc, nil
+}
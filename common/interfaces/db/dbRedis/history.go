@@ -0,0 +1,81 @@
+package dbRedis
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// getHistoryKey returns the key of the sorted set of a series of the stats history
+// The score of a member is its time in unix milliseconds and the member is "<time>:<value>", so that equal values
+// recorded at different times are distinct members.
+func getHistoryKey(metric db.HistoryMetric, language string) string {
+	return "history:" + string(metric) + ":" + language
+}
+
+// RecordStatsSnapshot records the stats by language at a time in the stats history
+// The expired points of the written series are removed, and a series not written for HistoryRetention expires.
+func (c *DBServiceRedis) RecordStatsSnapshot(ctx context.Context, at time.Time, stats entities.Stats) error {
+	ms := at.UnixMilli()
+	expiry := strconv.FormatInt(at.Add(-db.HistoryRetention).UnixMilli(), 10)
+
+	_, err := c.pool.TxPipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for metric, byLanguage := range db.StatsValues(stats) {
+				for language, value := range byLanguage {
+					key := getHistoryKey(metric, language)
+					member := strconv.FormatInt(ms, 10) + ":" + strconv.FormatFloat(value, 'g', -1, 64)
+					pipe.ZAdd(ctx, key, redis.Z{Score: float64(ms), Member: member})
+					pipe.ZRemRangeByScore(ctx, key, "-inf", "("+expiry)
+					pipe.Expire(ctx, key, db.HistoryRetention)
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "Error recording the stats snapshot")
+	}
+	return nil
+}
+
+// GetStatsHistory returns the series of the stats history selected by the query
+func (c *DBServiceRedis) GetStatsHistory(ctx context.Context, query db.HistoryQuery) ([]entities.HistoryPoint, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	res, err := c.pool.ZRangeByScore(
+		ctx, getHistoryKey(query.Metric, query.Language), &redis.ZRangeBy{
+			Min: strconv.FormatInt(query.From.UnixMilli(), 10),
+			Max: strconv.FormatInt(query.To.UnixMilli(), 10),
+		},
+	).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the stats history")
+	}
+
+	points := make([]entities.HistoryPoint, 0, len(res))
+	for _, member := range res {
+		ts, v, ok := strings.Cut(member, ":")
+		if !ok {
+			return nil, errors.Errorf("invalid stats history member %q", member)
+		}
+		ms, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding the time of stats history member %q", member)
+		}
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding the value of stats history member %q", member)
+		}
+		points = append(points, entities.HistoryPoint{Time: time.UnixMilli(ms).UTC(), Value: value})
+	}
+	return db.Downsample(points, query.From, query.Step), nil
+}
@@ -0,0 +1,202 @@
+package dbRedis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	qb "github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db/dbRedis/queryBuilder"
+	"github.com/pkg/errors"
+)
+
+// The languages breakdowns are JSON strings, which FT.AGGREGATE cannot group by language, so the statistics of the
+// breakdowns are computed from the distinct breakdowns of the matching documents (see getBreakdownGroups).
+
+// GetLanguageStats computes the statistics of the languages breakdowns of the repositories matching the filters
+func (c *DBServiceRedis) GetLanguageStats(ctx context.Context, filters db.GetRepoListFilters) (
+	entities.LanguageStats, error,
+) {
+	groups, err := c.getBreakdownGroups(ctx, filters)
+	if err != nil {
+		return entities.LanguageStats{}, err
+	}
+
+	return db.ComputeLanguageStats(groups), nil
+}
+
+// GetLanguageCooccurrence counts the repositories matching the filters using each pair of languages together
+func (c *DBServiceRedis) GetLanguageCooccurrence(ctx context.Context, filters db.GetRepoListFilters, minBytes int64) (
+	entities.LanguageCooccurrence, error,
+) {
+	groups, err := c.getBreakdownGroups(ctx, filters)
+	if err != nil {
+		return entities.LanguageCooccurrence{}, err
+	}
+
+	return db.ComputeLanguageCooccurrence(groups, minBytes), nil
+}
+
+// GetLanguages returns the summary of each language of the repositories matching the filters
+// The repositories are grouped by primary language and languages breakdown, as the bytes of each language need the
+// breakdowns.
+func (c *DBServiceRedis) GetLanguages(ctx context.Context, filters db.GetRepoListFilters) (
+	[]entities.LanguageSummary, error,
+) {
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := c.getItemGroups(ctx, query, "language", "languages")
+	if err != nil {
+		return nil, err
+	}
+	return db.ComputeLanguageSummaries(groups), nil
+}
+
+// GetLanguage returns the summary of a language and the top repositories using it
+// The repositories using the language are an exact (case-insensitive) match of the all_languages TAG: the summary is
+// computed from their groups (as in GetLanguages), and the top repositories are a page sorted by descending watchers
+// (ties by descending ID) with only the LanguageDetailFields loaded.
+func (c *DBServiceRedis) GetLanguage(ctx context.Context, name string, top int) (entities.LanguageDetail, error) {
+	clause, err := qb.Tag("all_languages", name)
+	if err != nil {
+		return entities.LanguageDetail{}, errors.Wrapf(db.ErrInvalidFilter, "language: %v", err)
+	}
+
+	groups, err := c.getItemGroups(ctx, string(clause), "language", "languages")
+	if err != nil {
+		return entities.LanguageDetail{}, err
+	}
+	summary, ok := db.FindLanguageSummary(groups, name)
+	if !ok {
+		return entities.LanguageDetail{}, db.ErrNotFound
+	}
+
+	s := search{
+		query:  string(clause),
+		order:  &sortOrder{attribute: "watchers_count", descendingTies: true},
+		fields: db.LanguageDetailFields,
+	}
+	list, _, _, err := c.searchRepoItems(ctx, s, 0, top)
+	if err != nil {
+		return entities.LanguageDetail{}, err
+	}
+	return entities.LanguageDetail{Summary: summary, TopRepos: ConvertRepoListI2E(list)}, nil
+}
+
+// GetOwners returns the summary of each owner of the repositories matching the filters
+// The repositories are grouped by owner, primary language and languages breakdown, as the dominant language of an
+// owner needs the breakdowns.
+func (c *DBServiceRedis) GetOwners(ctx context.Context, filters db.GetRepoListFilters) (
+	[]entities.OwnerSummary, error,
+) {
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := c.getItemGroups(ctx, query, "owner", "language", "languages")
+	if err != nil {
+		return nil, err
+	}
+	return db.ComputeOwners(groups), nil
+}
+
+// GetLicenseStats counts the repositories matching the filters by license and license family
+// The repositories are counted by license key with FT.AGGREGATE, keeping the greatest name of each key so that a
+// non-empty name is kept, and the families are counted from the keys.
+func (c *DBServiceRedis) GetLicenseStats(ctx context.Context, filters db.GetRepoListFilters) (
+	entities.LicenseStats, error,
+) {
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return entities.LicenseStats{}, err
+	}
+
+	rows, err := c.aggregateGroups(
+		ctx, []string{"key"}, "FT.AGGREGATE", repoIndex, query, "LOAD", 6, "$.license_key", "AS", "key",
+		"$.license", "AS", "name", "GROUPBY", 1, "@key", "REDUCE", "COUNT", 0, "AS", "count",
+		"REDUCE", "FIRST_VALUE", 4, "@name", "BY", "@name", "DESC", "AS", "name",
+	)
+	if err != nil {
+		return entities.LicenseStats{}, err
+	}
+
+	counts := make([]entities.LicenseCount, 0, len(rows))
+	for _, row := range rows {
+		count, err := strconv.Atoi(row["count"])
+		if err != nil {
+			return entities.LicenseStats{}, errors.Wrapf(err, "Error decoding the count of license %q", row["key"])
+		}
+		counts = append(counts, entities.LicenseCount{Key: row["key"], Name: row["name"], Count: count})
+	}
+	return db.LicenseStatsOf(counts), nil
+}
+
+// getBreakdownGroups counts the repositories matching the filters by languages breakdown
+// The documents without a breakdown are left out of the query, so that only the distinct breakdowns are returned.
+func (c *DBServiceRedis) getBreakdownGroups(ctx context.Context, filters db.GetRepoListFilters) (
+	[]db.ItemGroup, error,
+) {
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+	clause, err := qb.Range("language_count", floatPtr(1), nil, false, false)
+	if err != nil {
+		return nil, err
+	}
+	if query != string(qb.All) {
+		// The query was built by the query builder
+		clause = qb.And(qb.Clause(query), clause)
+	}
+
+	return c.getItemGroups(ctx, string(clause), "languages")
+}
+
+// itemGroupPaths maps the keys of the item groups to the JSON paths of the values in the documents
+var itemGroupPaths = map[string]string{"owner": "$.owner", "language": "$.language", "languages": "$.languages"}
+
+// getItemGroups counts the documents matching the query and sums their size by the values of the keys (owner,
+// language and/or languages), grouped with FT.AGGREGATE
+func (c *DBServiceRedis) getItemGroups(ctx context.Context, query string, keys ...string) ([]db.ItemGroup, error) {
+	// LOAD <count> @size $.<key> AS <key> ... GROUPBY <count> @<key> ...
+	args := []interface{}{"FT.AGGREGATE", repoIndex, query, "LOAD", 3*len(keys) + 1, "@size"}
+	for _, key := range keys {
+		args = append(args, itemGroupPaths[key], "AS", key)
+	}
+	args = append(args, "GROUPBY", len(keys))
+	for _, key := range keys {
+		args = append(args, "@"+key)
+	}
+	args = append(args, "REDUCE", "COUNT", 0, "AS", "count", "REDUCE", "SUM", 1, "@size", "AS", "size")
+
+	rows, err := c.aggregateGroups(ctx, keys, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]db.ItemGroup, 0, len(rows))
+	for _, row := range rows {
+		group := db.ItemGroup{Owner: row["owner"], Language: row["language"]}
+		if breakdown := row["languages"]; breakdown != "" {
+			group.Languages, err = ConvertLanguagesI2E(&breakdown)
+			if err != nil {
+				return nil, err
+			}
+		}
+		group.Count, err = strconv.Atoi(row["count"])
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding the count of a group of repos")
+		}
+		size, err := strconv.ParseFloat(row["size"], 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding the size of a group of repos")
+		}
+		group.Size = int(size)
+		out = append(out, group)
+	}
+	return out, nil
+}
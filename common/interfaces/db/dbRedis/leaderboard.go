@@ -0,0 +1,216 @@
+package dbRedis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// leaderboardKeyPattern matches the keys of all the leaderboards
+const leaderboardKeyPattern = "lb:*"
+
+// leaderboardDocFields are the document fields needed to compute the values of the leaderboard metrics
+var leaderboardDocFields = []string{
+	"language", "languages", "forks_count", "watchers_count", "size", "open_issues_count",
+}
+
+// getLeaderboardKey returns the key of the sorted set of a leaderboard
+// language is the primary language of the per-language leaderboards (nil for the leaderboard of all the items)
+func getLeaderboardKey(metric db.LeaderboardMetric, language *string) string {
+	if language == nil {
+		return "lb:" + string(metric)
+	}
+	return "lb:" + string(metric) + ":lang:" + db.LeaderboardLanguage(*language)
+}
+
+// getLeaderboardMember returns the member of an item in the leaderboards
+// The ID is zero padded, so that the reverse lexicographic order of the members with the same score is the descending
+// order of the IDs (the order of db.SortLeaderboard)
+func getLeaderboardMember(id int64) string {
+	return fmt.Sprintf("%020d", id)
+}
+
+// addLeaderboardEntries adds the value of each metric of an item to the leaderboards of all the items and of its
+// primary language
+func addLeaderboardEntries(
+	ctx context.Context, pipe redis.Pipeliner, item entities.RepoItem, metrics ...db.LeaderboardMetric,
+) {
+	member := getLeaderboardMember(item.ID)
+	for _, metric := range metrics {
+		z := redis.Z{Score: float64(db.LeaderboardValue(item, metric)), Member: member}
+		pipe.ZAdd(ctx, getLeaderboardKey(metric, nil), z)
+		if item.Language != "" {
+			pipe.ZAdd(ctx, getLeaderboardKey(metric, &item.Language), z)
+		}
+	}
+}
+
+// rebuildLeaderboards rebuilds the sorted sets of the leaderboards from the current documents
+// The sorted sets are replaced in a transaction, so readers never see a partially built leaderboard.
+func (c *DBServiceRedis) rebuildLeaderboards(ctx context.Context) error {
+
+	page, err := c.GetRepoList(ctx, db.GetRepoListFilters{Fields: leaderboardDocFields})
+	if err != nil {
+		return errors.Wrap(err, "could not get the items")
+	}
+
+	// The existing per-language leaderboards are deleted too, as their language may be gone
+	keys, err := c.scanKeys(ctx, leaderboardKeyPattern)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.pool.TxPipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			if len(keys) > 0 {
+				pipe.Del(ctx, keys...)
+			}
+			for _, item := range page.Items {
+				addLeaderboardEntries(ctx, pipe, item, db.LeaderboardMetrics...)
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "Error storing leaderboards")
+	}
+
+	return nil
+}
+
+// updateLeaderboards updates the leaderboards of an item whose languages changed from old to new
+// The language_count changes with the breakdown. The primary language may change with it too (see db.WithLanguages):
+// the entries of the item are then removed from every leaderboard of the old primary language and added to every
+// leaderboard of the new one.
+func (c *DBServiceRedis) updateLeaderboards(ctx context.Context, old, new entities.RepoItem) error {
+	_, err := c.pool.TxPipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			if db.LeaderboardLanguage(old.Language) == db.LeaderboardLanguage(new.Language) {
+				addLeaderboardEntries(ctx, pipe, new, db.LeaderboardLanguageCount)
+				return nil
+			}
+			if old.Language != "" {
+				member := getLeaderboardMember(old.ID)
+				for _, metric := range db.LeaderboardMetrics {
+					pipe.ZRem(ctx, getLeaderboardKey(metric, &old.Language), member)
+				}
+			}
+			addLeaderboardEntries(ctx, pipe, new, db.LeaderboardMetrics...)
+			return nil
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "Error storing leaderboards")
+	}
+	return nil
+}
+
+// scanKeys returns the keys matching the pattern
+func (c *DBServiceRedis) scanKeys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	iter := c.pool.Scan(ctx, 0, pattern, 0).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.Wrapf(err, "Error scanning the keys %s", pattern)
+	}
+	return keys, nil
+}
+
+// GetLeaderboard returns the items ranked by the metric of the query
+// The ranking is read from the sorted set of the leaderboard (O(log n + limit)), then only the display fields of the
+// ranked items are loaded. The items deleted since the leaderboard was built are skipped, so a leaderboard may return
+// fewer entries than the limit until the next SetRepoList.
+func (c *DBServiceRedis) GetLeaderboard(ctx context.Context, query db.LeaderboardQuery) (
+	[]entities.LeaderboardEntry, error,
+) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	ranked, err := c.pool.ZRevRangeWithScores(
+		ctx, getLeaderboardKey(query.Metric, query.Language), 0, int64(query.Limit-1),
+	).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the leaderboard")
+	}
+
+	// Load the display fields of the ranked items: JSON.GET <key> $.id $.name ...
+	paths := make([]string, len(db.LeaderboardFields))
+	for i, field := range db.LeaderboardFields {
+		paths[i] = "$." + field
+	}
+	cmds := make([]*redis.JSONCmd, len(ranked))
+	_, err = c.pool.Pipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for i, z := range ranked {
+				id, err := strconv.ParseInt(z.Member.(string), 10, 64)
+				if err != nil {
+					return errors.Wrapf(err, "invalid leaderboard member %q", z.Member)
+				}
+				cmds[i] = pipe.JSONGet(ctx, string(getRepoKey(id)), paths...)
+			}
+			return nil
+		},
+	)
+	// A missing document only fails its own command: it is checked below
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, errors.Wrap(err, "Error getting the leaderboard items")
+	}
+
+	out := make([]entities.LeaderboardEntry, 0, len(ranked))
+	for i, z := range ranked {
+
+		// The document was deleted since the leaderboard was built: skip it
+		if errors.Is(cmds[i].Err(), redis.Nil) || cmds[i].Val() == "" {
+			continue
+		}
+		if err := cmds[i].Err(); err != nil {
+			return nil, errors.Wrap(err, "Error getting the leaderboard item")
+		}
+
+		doc, err := decodePathsDoc(cmds[i].Val())
+		if err != nil {
+			return nil, err
+		}
+		item, err := ConvertRepoItemI2E(doc)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, entities.LeaderboardEntry{Item: item, Value: int(z.Score)})
+	}
+	return out, nil
+}
+
+// decodePathsDoc rebuilds a RepoItem document from the result of a JSON.GET of several paths ({"$.id":[1],...})
+// The fields that were not loaded keep their zero value
+func decodePathsDoc(data string) (RepoItem, error) {
+	var byPath map[string][]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &byPath); err != nil {
+		return RepoItem{}, errors.Wrap(err, "Error unmarshaling document paths")
+	}
+
+	raw := make(map[string]json.RawMessage, len(byPath))
+	for path, values := range byPath {
+		if len(values) > 0 {
+			raw[path[len("$."):]] = values[0]
+		}
+	}
+
+	doc, err := json.Marshal(raw)
+	if err != nil {
+		return RepoItem{}, errors.Wrap(err, "Error marshaling JSON")
+	}
+	var out RepoItem
+	if err := json.Unmarshal(doc, &out); err != nil {
+		return RepoItem{}, errors.Wrap(err, "Error unmarshaling document")
+	}
+	return out, nil
+}
@@ -0,0 +1,81 @@
+package dbRedis
+
+import (
+	"strings"
+
+	qb "github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db/dbRedis/queryBuilder"
+	"github.com/Scalingo/sclng-backend-test-v1/common/query"
+	"github.com/pkg/errors"
+)
+
+// queryAttributes maps the fields of the search query language to the attributes in the index
+var queryAttributes = map[string]string{
+	query.FieldName.Name:         "name",
+	query.FieldLicense.Name:      "license_key",
+	query.FieldLicenseName.Name:  "license",
+	query.FieldLanguage.Name:     "all_languages",
+	query.FieldAllowForking.Name: "allow_forking",
+	query.FieldForks.Name:        "forks_count",
+	query.FieldWatchers.Name:     "watchers_count",
+	query.FieldSize.Name:         "size",
+	query.FieldOpenIssues.Name:   "open_issues_count",
+	query.FieldCreated.Name:      "created_at",
+	query.FieldUpdated.Name:      "updated_at",
+}
+
+// compileQuery compiles a search query AST into a RediSearch query
+func compileQuery(expr query.Expr) (qb.Clause, error) {
+	switch e := expr.(type) {
+	case query.AndExpr:
+		operands, err := compileOperands(e.Operands)
+		if err != nil {
+			return "", err
+		}
+		return qb.And(operands...), nil
+	case query.OrExpr:
+		operands, err := compileOperands(e.Operands)
+		if err != nil {
+			return "", err
+		}
+		return qb.Or(operands...), nil
+	case query.NotExpr:
+		operand, err := compileQuery(e.Operand)
+		if err != nil {
+			return "", err
+		}
+		return qb.Not(operand), nil
+	case query.MatchExpr:
+		attribute, ok := queryAttributes[e.Field.Name]
+		if !ok {
+			return "", errors.Errorf("field %q is not indexed", e.Field.Name)
+		}
+		switch e.Field.Kind {
+		case query.KindTag, query.KindBool:
+			return qb.Tag(attribute, e.Value)
+		}
+		// Text fields match when the field contains the value. Values with spaces are matched as a phrase.
+		if strings.ContainsAny(e.Value, " \t") {
+			return qb.Phrase(attribute, e.Value)
+		}
+		return qb.Text(attribute, e.Value)
+	case query.RangeExpr:
+		attribute, ok := queryAttributes[e.Field.Name]
+		if !ok {
+			return "", errors.Errorf("field %q is not indexed", e.Field.Name)
+		}
+		return qb.Range(attribute, e.Min, e.Max, e.MinExclusive, e.MaxExclusive)
+	}
+	return "", errors.Errorf("unknown query expression %T", expr)
+}
+
+// compileOperands compiles the operands of an AND or OR expression
+func compileOperands(operands []query.Expr) ([]qb.Clause, error) {
+	compiled := make([]qb.Clause, len(operands))
+	for i, operand := range operands {
+		var err error
+		if compiled[i], err = compileQuery(operand); err != nil {
+			return nil, err
+		}
+	}
+	return compiled, nil
+}
@@ -0,0 +1,217 @@
+// Package queryBuilder builds RediSearch queries from user supplied values.
+// Every value is escaped for the type of the attribute it is matched against, so that user input
+// is always matched literally and can never inject query operators.
+package queryBuilder
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidValue is returned for values that cannot be matched (for example, control characters or invalid UTF-8)
+var ErrInvalidValue = QueryBuilderError("invalid value")
+
+// QueryBuilderError is a custom error type for query builder errors
+type QueryBuilderError string
+
+func (e QueryBuilderError) Error() string { return string(e) }
+
+// maxValueLength is the maximum length (in bytes) of a value
+const maxValueLength = 256
+
+// Clause is a fragment of a RediSearch query. Clauses are only created by this package, so they are always safe.
+type Clause string
+
+// All matches every document
+const All Clause = "*"
+
+// Text matches the documents where the TEXT attribute contains the value
+// The value is split into terms the same way RediSearch tokenizes text. Each term matches as an infix (*term*),
+// except for single characters which must match a whole term. All the terms must match.
+func Text(attribute, value string) (Clause, error) {
+	if err := validate(value); err != nil {
+		return "", err
+	}
+
+	terms := strings.FieldsFunc(value, isSeparator)
+	if len(terms) == 0 {
+		return "", errors.Wrapf(ErrInvalidValue, "%q has no searchable characters", value)
+	}
+
+	for i, term := range terms {
+		if utf8.RuneCountInString(term) < 2 {
+			terms[i] = Escape(term)
+		} else {
+			terms[i] = "*" + Escape(term) + "*"
+		}
+	}
+
+	if len(terms) == 1 {
+		return Clause(fmt.Sprintf("@%s:%s", attribute, terms[0])), nil
+	}
+	return Clause(fmt.Sprintf("@%s:(%s)", attribute, strings.Join(terms, " "))), nil
+}
+
+// Phrase matches the documents where the TEXT attribute contains the terms of the value, in order
+func Phrase(attribute, value string) (Clause, error) {
+	if err := validate(value); err != nil {
+		return "", err
+	}
+
+	terms := strings.FieldsFunc(value, isSeparator)
+	if len(terms) == 0 {
+		return "", errors.Wrapf(ErrInvalidValue, "%q has no searchable characters", value)
+	}
+
+	for i, term := range terms {
+		terms[i] = Escape(term)
+	}
+	return Clause(fmt.Sprintf(`@%s:"%s"`, attribute, strings.Join(terms, " "))), nil
+}
+
+// FullText matches the documents where any of the TEXT attributes contains every term of the value
+// Unlike Text, the terms are matched as whole words, so that RediSearch applies stemming and scores the documents
+// with the weights of the attributes.
+func FullText(attributes []string, value string) (Clause, error) {
+	if err := validate(value); err != nil {
+		return "", err
+	}
+
+	terms := strings.FieldsFunc(value, isSeparator)
+	if len(terms) == 0 {
+		return "", errors.Wrapf(ErrInvalidValue, "%q has no searchable characters", value)
+	}
+
+	for i, term := range terms {
+		terms[i] = Escape(term)
+	}
+
+	return Clause(fmt.Sprintf("@%s:(%s)", strings.Join(attributes, "|"), strings.Join(terms, " "))), nil
+}
+
+// Tag matches the documents where the TAG attribute has a tag equal to the value (case-insensitive)
+func Tag(attribute, value string) (Clause, error) {
+	if err := validate(value); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(value) == "" {
+		return "", errors.Wrapf(ErrInvalidValue, "empty tag")
+	}
+	return Clause(fmt.Sprintf("@%s:{%s}", attribute, Escape(value))), nil
+}
+
+// Bool matches the documents where the TAG attribute holding a boolean equals the value
+func Bool(attribute string, value bool) Clause {
+	return Clause(fmt.Sprintf("@%s:{%t}", attribute, value))
+}
+
+// Range matches the documents where the NUMERIC attribute is within the range. A nil bound is unbounded.
+// The bounds must be finite: NaN and infinite bounds can never be matched.
+func Range(attribute string, min, max *float64, minExclusive, maxExclusive bool) (Clause, error) {
+	for _, bound := range []*float64{min, max} {
+		if bound != nil && (math.IsNaN(*bound) || math.IsInf(*bound, 0)) {
+			return "", errors.Wrapf(ErrInvalidValue, "%v is not a finite bound", *bound)
+		}
+	}
+	return Clause(fmt.Sprintf("@%s:[%s %s]", attribute, formatBound(min, minExclusive, "-inf"), formatBound(max, maxExclusive, "+inf"))), nil
+}
+
+// And matches the documents matching all the clauses
+func And(clauses ...Clause) Clause {
+	return join(clauses, " ")
+}
+
+// Or matches the documents matching any of the clauses
+func Or(clauses ...Clause) Clause {
+	return join(clauses, " | ")
+}
+
+// Not matches the documents not matching the clause
+func Not(clause Clause) Clause {
+	return Clause("-(" + string(clause) + ")")
+}
+
+// join joins the clauses in parentheses. No clauses matches every document.
+func join(clauses []Clause, sep string) Clause {
+	switch len(clauses) {
+	case 0:
+		return All
+	case 1:
+		return clauses[0]
+	}
+	strs := make([]string, len(clauses))
+	for i, c := range clauses {
+		strs[i] = string(c)
+	}
+	return Clause("(" + strings.Join(strs, sep) + ")")
+}
+
+// formatBound formats a bound of a numeric range
+func formatBound(bound *float64, exclusive bool, unbounded string) string {
+	if bound == nil {
+		return unbounded
+	}
+	out := strconv.FormatFloat(*bound, 'f', -1, 64)
+	if exclusive {
+		out = "(" + out
+	}
+	return out
+}
+
+// validate rejects values that can never be matched
+func validate(value string) error {
+	if len(value) > maxValueLength {
+		return errors.Wrapf(ErrInvalidValue, "longer than %d bytes", maxValueLength)
+	}
+	if !utf8.ValidString(value) {
+		return errors.Wrapf(ErrInvalidValue, "%q is not valid UTF-8", value)
+	}
+	for _, r := range value {
+		if unicode.IsControl(r) {
+			return errors.Wrapf(ErrInvalidValue, "%q contains control characters", value)
+		}
+	}
+	return nil
+}
+
+// isSeparator reports whether RediSearch splits text into terms at the rune
+// https://redis.io/docs/latest/develop/interact/search-and-query/advanced-concepts/escaping/
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(",.<>{}[]\"':;!@#$%^&*()-+=~|/\\`?", r)
+}
+
+// Escape escapes every rune that is not a letter, a digit or an underscore with a backslash,
+// so that it is matched literally
+func Escape(value string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		if needsEscape(r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Unescape reverses Escape
+func Unescape(escaped string) string {
+	var sb strings.Builder
+	runes := []rune(escaped)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			i++
+		}
+		sb.WriteRune(runes[i])
+	}
+	return sb.String()
+}
+
+func needsEscape(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
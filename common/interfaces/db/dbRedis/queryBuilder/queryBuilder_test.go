@@ -0,0 +1,198 @@
+package queryBuilder
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestClauses(t *testing.T) {
+	floatPtr := func(v float64) *float64 { return &v }
+	must := func(c Clause, err error) Clause {
+		if err != nil {
+			t.Fatalf("unexpected error = %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name   string
+		clause Clause
+		want   string
+	}{
+		{name: "Text", clause: must(Text("name", "cli")), want: "@name:*cli*"},
+		{name: "Text terms", clause: must(Text("name", "my-cli")), want: "@name:(*my* *cli*)"},
+		{name: "Text short term", clause: must(Text("name", "a")), want: "@name:a"},
+		{name: "Text operators", clause: must(Text("name", "cli)|(@name:*")), want: "@name:(*cli* *name*)"},
+		{name: "Phrase", clause: must(Phrase("license", "MIT License")), want: `@license:"MIT License"`},
+		{name: "FullText", clause: must(FullText([]string{"name", "description"}, "parsing JSON")), want: "@name|description:(parsing JSON)"},
+		{name: "FullText operators", clause: must(FullText([]string{"name"}, "-cli*")), want: "@name:(cli)"},
+		{name: "Tag", clause: must(Tag("all_languages", "C++")), want: `@all_languages:{C\+\+}`},
+		{name: "Tag spaces", clause: must(Tag("all_languages", "Vim Script")), want: `@all_languages:{Vim\ Script}`},
+		{name: "Bool", clause: Bool("allow_forking", true), want: "@allow_forking:{true}"},
+		{name: "Range", clause: must(Range("size", floatPtr(1), floatPtr(2.5), false, true)), want: "@size:[1 (2.5]"},
+		{name: "Range unbounded", clause: must(Range("size", nil, floatPtr(-3), true, false)), want: "@size:[-inf -3]"},
+		{name: "And empty", clause: And(), want: "*"},
+		{name: "And", clause: And("@a:{x}", "@b:{y}"), want: "(@a:{x} @b:{y})"},
+		{name: "Or single", clause: Or("@a:{x}"), want: "@a:{x}"},
+		{name: "Or", clause: Or("@a:{x}", "@b:{y}"), want: "(@a:{x} | @b:{y})"},
+		{name: "Not", clause: Not("@a:{x}"), want: "-(@a:{x})"},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				if string(tt.clause) != tt.want {
+					t.Errorf("got = %s, want %s", tt.clause, tt.want)
+				}
+			},
+		)
+	}
+}
+
+func TestClauses_Invalid(t *testing.T) {
+	floatPtr := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name  string
+		build func() (Clause, error)
+	}{
+		{name: "Text without terms", build: func() (Clause, error) { return Text("name", "--- ()") }},
+		{name: "Text control character", build: func() (Clause, error) { return Text("name", "a\x00b") }},
+		{name: "Text invalid UTF-8", build: func() (Clause, error) { return Text("name", "a\xffb") }},
+		{name: "Text too long", build: func() (Clause, error) { return Text("name", strings.Repeat("a", 257)) }},
+		{name: "FullText without terms", build: func() (Clause, error) { return FullText([]string{"name"}, "*") }},
+		{name: "Phrase without terms", build: func() (Clause, error) { return Phrase("name", " ") }},
+		{name: "Tag empty", build: func() (Clause, error) { return Tag("all_languages", " ") }},
+		{name: "Tag newline", build: func() (Clause, error) { return Tag("all_languages", "go\n") }},
+		{name: "Range NaN", build: func() (Clause, error) { return Range("size", floatPtr(math.NaN()), nil, false, false) }},
+		{name: "Range +Inf", build: func() (Clause, error) { return Range("size", nil, floatPtr(math.Inf(1)), false, false) }},
+		{name: "Range -Inf", build: func() (Clause, error) { return Range("size", floatPtr(math.Inf(-1)), nil, true, false) }},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := tt.build()
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Errorf("error = %v, want ErrInvalidValue", err)
+				}
+			},
+		)
+	}
+}
+
+// FuzzEscape checks that escaped values round-trip and never contain an unescaped special character
+func FuzzEscape(f *testing.F) {
+	for _, seed := range []string{"", "cli", "C++", "my-cli", `a\b`, "cli)|(@name:*", "{go}", `"quoted"`, "été", "a b"} {
+		f.Add(seed)
+	}
+	f.Fuzz(
+		func(t *testing.T, value string) {
+			if !utf8.ValidString(value) {
+				return
+			}
+			escaped := Escape(value)
+			if got := Unescape(escaped); got != value {
+				t.Fatalf("Unescape(Escape(%q)) = %q", value, got)
+			}
+			runes := []rune(escaped)
+			for i := 0; i < len(runes); i++ {
+				if runes[i] == '\\' {
+					i++
+					continue
+				}
+				if needsEscape(runes[i]) {
+					t.Fatalf("Escape(%q) = %q has an unescaped %q", value, escaped, runes[i])
+				}
+			}
+		},
+	)
+}
+
+// FuzzTag checks that a tag clause always holds a single, closed tag expression
+func FuzzTag(f *testing.F) {
+	for _, seed := range []string{"Go", "C++", "}", `\`, "a|b", "{*}"} {
+		f.Add(seed)
+	}
+	f.Fuzz(
+		func(t *testing.T, value string) {
+			clause, err := Tag("attr", value)
+			if err != nil {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Fatalf("Tag(%q) error = %v, want ErrInvalidValue", value, err)
+				}
+				return
+			}
+			s := string(clause)
+			if !strings.HasPrefix(s, "@attr:{") || !strings.HasSuffix(s, "}") {
+				t.Fatalf("Tag(%q) = %q", value, s)
+			}
+			if got := Unescape(s[len("@attr:{") : len(s)-1]); got != value {
+				t.Fatalf("Tag(%q) round-trips to %q", value, got)
+			}
+		},
+	)
+}
+
+// FuzzText checks that a text clause only holds escaped terms of the value
+func FuzzText(f *testing.F) {
+	for _, seed := range []string{"cli", "my-cli", "a", "cli)|(@name:*", "-mit | @name", "été"} {
+		f.Add(seed)
+	}
+	f.Fuzz(
+		func(t *testing.T, value string) {
+			clause, err := Text("attr", value)
+			if err != nil {
+				if !errors.Is(err, ErrInvalidValue) {
+					t.Fatalf("Text(%q) error = %v, want ErrInvalidValue", value, err)
+				}
+				return
+			}
+			s := strings.TrimPrefix(string(clause), "@attr:")
+			s = strings.TrimSuffix(strings.TrimPrefix(s, "("), ")")
+			for _, term := range strings.Split(s, " ") {
+				term = strings.TrimSuffix(strings.TrimPrefix(term, "*"), "*")
+				if term == "" || !strings.Contains(value, Unescape(term)) {
+					t.Fatalf("Text(%q) = %q has the term %q", value, clause, term)
+				}
+			}
+		},
+	)
+}
+
+// FuzzRange checks that a range clause is only built for finite bounds, and that the bounds round-trip
+func FuzzRange(f *testing.F) {
+	for _, seed := range []float64{0, -3, 2.5, 1e300, math.NaN(), math.Inf(1), math.Inf(-1)} {
+		f.Add(seed, seed+1)
+	}
+	f.Fuzz(
+		func(t *testing.T, min, max float64) {
+			clause, err := Range("attr", &min, &max, false, true)
+			finite := !math.IsNaN(min) && !math.IsInf(min, 0) && !math.IsNaN(max) && !math.IsInf(max, 0)
+			if err != nil {
+				if !errors.Is(err, ErrInvalidValue) || finite {
+					t.Fatalf("Range(%v, %v) error = %v", min, max, err)
+				}
+				return
+			}
+			if !finite {
+				t.Fatalf("Range(%v, %v) = %q, want ErrInvalidValue", min, max, clause)
+			}
+			s := string(clause)
+			if !strings.HasPrefix(s, "@attr:[") || !strings.HasSuffix(s, "]") {
+				t.Fatalf("Range(%v, %v) = %q", min, max, s)
+			}
+			bounds := strings.Split(s[len("@attr:["):len(s)-1], " ")
+			if len(bounds) != 2 {
+				t.Fatalf("Range(%v, %v) = %q", min, max, s)
+			}
+			gotMin, errMin := strconv.ParseFloat(bounds[0], 64)
+			gotMax, errMax := strconv.ParseFloat(strings.TrimPrefix(bounds[1], "("), 64)
+			if errMin != nil || errMax != nil || gotMin != min || gotMax != max {
+				t.Fatalf("Range(%v, %v) = %q", min, max, s)
+			}
+		},
+	)
+}
@@ -3,43 +3,86 @@ package dbRedis
 import (
 	"context"
 	"encoding/json"
-	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
 	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	qb "github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db/dbRedis/queryBuilder"
+	"github.com/Scalingo/sclng-backend-test-v1/common/quality"
 	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 )
 
+// repoIndexBaseName is the name of the search index over the repo documents.
+// repoIndex appends the schema version. Bump the version whenever the schema in CreateIndexes changes,
+// so the index is rebuilt over the existing documents on the next startup.
+const repoIndexBaseName = "idx:repo"
+const repoIndex = repoIndexBaseName + ":v11"
+
+// sortAttributes maps the sort fields to the sortable attributes in the index
+var sortAttributes = map[db.SortField]string{
+	db.SortByCreatedAt:  "created_at",
+	db.SortByForks:      "forks_count",
+	db.SortByWatchers:   "watchers_count",
+	db.SortBySize:       "size",
+	db.SortByOpenIssues: "open_issues_count",
+	db.SortByQuality:    "quality",
+}
+
+// textAttributes are the TEXT attributes matched by the full-text search
+var textAttributes = []string{"name", "description"}
+
+// searchPageSize is the number of keys requested per search when paging through all the search results
+const searchPageSize = 1000
+
 // DBServiceRedis is a redis db service
 type DBServiceRedis struct {
 	pool      *redis.Client
 	log       logrus.FieldLogger
 	keyPrefix string
 	hostPort  string
+
+	// qualityWeights are the weights of the quality scores of the items (see SetQualityWeights)
+	qualityWeights quality.Weights
 }
 
 // CreateIndexes creates the indexes for the redis db
+// Indexes left over from previous versions of the schema are dropped (the documents are kept)
 func (c *DBServiceRedis) CreateIndexes(ctx context.Context) error {
 	// Create the indexes
-	//"FT.CREATE idx:repo ON JSON PREFIX 1 repo: SCHEMA $.id as id NUMERIC $.name as name TEXT $.language as language TEXT $.all_languages as all_languages TAG $.license as license TEXT $.size as size NUMERIC $.watchers_count as watchers_count NUMERIC $.forks_count as forks_count NUMERIC $.allow_forking as allow_forking TAG $.open_issues_count as open_issues_count NUMERIC"
+	//"FT.CREATE idx:repo:v11 ON JSON PREFIX 1 repo: SCHEMA $.id as id NUMERIC SORTABLE $.name as name TEXT WEIGHT 3 $.description as description TEXT WEIGHT 1 $.language as language TEXT $.all_languages as all_languages TAG $.language_count as language_count NUMERIC SORTABLE $.dominant_language as dominant_language TAG $.quality.score as quality NUMERIC SORTABLE $.license as license TEXT $.license_key as license_key TAG $.license_family as license_family TAG $.owner as owner TAG $.size as size NUMERIC SORTABLE $.watchers_count as watchers_count NUMERIC SORTABLE $.forks_count as forks_count NUMERIC SORTABLE $.allow_forking as allow_forking TAG $.open_issues_count as open_issues_count NUMERIC SORTABLE $.created_at_unix as created_at NUMERIC SORTABLE $.updated_at_unix as updated_at NUMERIC $.language_vector as language_vector VECTOR FLAT 6 TYPE FLOAT32 DIM <languageVectorDims> DISTANCE_METRIC COSINE"
 
-	err := c.pool.Do(
-		ctx, "FT.CREATE", "idx:repo", "ON", "JSON", "PREFIX", "1", "repo:", "SCHEMA",
-		"$.id", "as", "id", "NUMERIC",
-		"$.name", "as", "name", "TEXT",
+	err := c.dropStaleIndexes(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.pool.Do(
+		ctx, "FT.CREATE", repoIndex, "ON", "JSON", "PREFIX", "1", "repo:", "SCHEMA",
+		"$.id", "as", "id", "NUMERIC", "SORTABLE",
+		"$.name", "as", "name", "TEXT", "WEIGHT", textWeight("name"),
+		"$.description", "as", "description", "TEXT", "WEIGHT", textWeight("description"),
 		"$.language", "as", "language", "TEXT",
 		"$.all_languages", "as", "all_languages", "TAG",
+		"$.language_count", "as", "language_count", "NUMERIC", "SORTABLE",
+		"$.dominant_language", "as", "dominant_language", "TAG",
+		"$.quality.score", "as", "quality", "NUMERIC", "SORTABLE",
 		"$.license", "as", "license", "TEXT",
-		"$.size", "as", "size", "NUMERIC",
-		"$.watchers_count", "as", "watchers_count", "NUMERIC",
-		"$.forks_count", "as", "forks_count", "NUMERIC",
+		"$.license_key", "as", "license_key", "TAG",
+		"$.license_family", "as", "license_family", "TAG",
+		"$.owner", "as", "owner", "TAG",
+		"$.size", "as", "size", "NUMERIC", "SORTABLE",
+		"$.watchers_count", "as", "watchers_count", "NUMERIC", "SORTABLE",
+		"$.forks_count", "as", "forks_count", "NUMERIC", "SORTABLE",
 		"$.allow_forking", "as", "allow_forking", "TAG",
-		"$.open_issues_count", "as", "open_issues_count", "NUMERIC",
+		"$.open_issues_count", "as", "open_issues_count", "NUMERIC", "SORTABLE",
+		"$.created_at_unix", "as", "created_at", "NUMERIC", "SORTABLE",
+		"$.updated_at_unix", "as", "updated_at", "NUMERIC",
+		"$.language_vector", "as", "language_vector", "VECTOR", "FLAT", "6",
+		"TYPE", "FLOAT32", "DIM", languageVectorDims, "DISTANCE_METRIC", "COSINE",
 	).Err()
 	if err != nil && err.Error() != "Index already exists" {
 		return errors.Wrap(err, "Could not create index")
@@ -47,6 +90,31 @@ func (c *DBServiceRedis) CreateIndexes(ctx context.Context) error {
 	return nil
 }
 
+// textWeight returns the WEIGHT of a TEXT attribute in the full-text search (see db.TextWeights)
+func textWeight(attribute string) string {
+	return strconv.FormatFloat(db.TextWeights[attribute], 'f', -1, 64)
+}
+
+// dropStaleIndexes drops the repo indexes created with a previous version of the schema
+func (c *DBServiceRedis) dropStaleIndexes(ctx context.Context) error {
+	indexes, err := c.pool.Do(ctx, "FT._LIST").StringSlice()
+	if err != nil {
+		return errors.Wrap(err, "Could not list indexes")
+	}
+
+	for _, index := range indexes {
+		if index == repoIndex || (index != repoIndexBaseName && !strings.HasPrefix(index, repoIndexBaseName+":")) {
+			continue
+		}
+		c.log.WithField("index", index).Info("dropping stale index")
+		err = c.pool.Do(ctx, "FT.DROPINDEX", index).Err()
+		if err != nil && err.Error() != "Unknown Index name" {
+			return errors.Wrapf(err, "Could not drop index %s", index)
+		}
+	}
+	return nil
+}
+
 // setRepoItem sets a repo item in the db
 func (c *DBServiceRedis) setRepoItem(ctx context.Context, item entities.RepoItem) error {
 
@@ -74,61 +142,33 @@ func (c *DBServiceRedis) setRepoItem(ctx context.Context, item entities.RepoItem
 }
 
 // SetRepoItemLanguages sets the languages field in the repo document
+// The document is stored again, so that the fields derived from the languages (all_languages, language_count,
+// dominant_language, the primary language when GitHub reports none, language_vector and quality) are updated with it.
 func (c *DBServiceRedis) SetRepoItemLanguages(ctx context.Context, repoID int64, langs entities.Languages) error {
 
-	key := getRepoKey(repoID)
-
-	iLangs, err := ConvertLanguagesE2I(langs)
-	if err != nil {
-		return errors.Wrap(err, "Error converting languages")
-	}
-
-	// Marshal the JSON document into a string
-	jsonData, err := json.Marshal(iLangs)
-	if err != nil {
-		return errors.Wrap(err, "Error marshaling JSON:")
-	}
-
-	// Store the JSON document in Redis
-	err = c.pool.JSONMerge(ctx, string(key), "$.languages", string(jsonData)).Err()
-	if err != nil {
-		return errors.Wrap(err, "Error storing document in Redis:")
-	}
-
-	// Set the all_languages field (combines Language and languages fields)
-	err = c.setAllLanguages(ctx, repoID, langs)
-	if err != nil {
-		return errors.Wrap(err, "Error setting all languages")
-	}
-
-	return nil
-}
-
-// SetAllLanguages sets the all_languages field in the repo document
-// Combine repo.language field and the keys of repo.languages field into a single array of all_languages for searching
-func (c *DBServiceRedis) setAllLanguages(ctx context.Context, repoID int64, langs entities.Languages) error {
-
-	key := getRepoKey(repoID)
-
 	// Get the repo document
 	repo, err := c.GetRepoItem(ctx, repoID)
 	if err != nil {
 		return errors.Wrap(err, "Error getting repo")
 	}
 
-	// Combine the languages into a single array
-	allLanguages := append(repo.Languages.Strings(), repo.Language)
+	// Store the document with the languages, and score it with them
+	updated := db.WithQuality(db.WithLanguages(repo, langs), c.qualityWeights)
+	err = c.setRepoItem(ctx, updated)
+	if err != nil {
+		return errors.Wrap(err, "Error storing languages")
+	}
 
-	// Marshal the JSON document into a string
-	jsonData, err := json.Marshal(allLanguages)
+	// The language counts of the languages added to or removed from the item changed
+	err = c.updateSuggestions(ctx, db.SuggestLanguage, repo, updated)
 	if err != nil {
-		return errors.Wrap(err, "Error marshaling JSON:")
+		return errors.Wrap(err, "Error updating suggestions")
 	}
 
-	// Store the JSON document in Redis
-	err = c.pool.JSONMerge(ctx, string(key), "$.all_languages", string(jsonData)).Err()
+	// The number of languages of the item changed, and maybe its primary language
+	err = c.updateLeaderboards(ctx, repo, updated)
 	if err != nil {
-		return errors.Wrap(err, "Error storing document in Redis:")
+		return errors.Wrap(err, "Error updating leaderboards")
 	}
 
 	return nil
@@ -143,7 +183,7 @@ func (c *DBServiceRedis) SetRepoList(ctx context.Context, list entities.RepoList
 		return errors.Wrap(err, "could not get existing items")
 	}
 	existingItemIDs := make(map[int64]entities.RepoItem)
-	for _, item := range existingItems {
+	for _, item := range existingItems.Items {
 		existingItemIDs[item.ID] = item
 	}
 
@@ -153,9 +193,9 @@ func (c *DBServiceRedis) SetRepoList(ctx context.Context, list entities.RepoList
 	for _, item := range list {
 
 		// Copy the languages from the existing item to the new one (so we don't lose the data)
-		if existingItemIDs[item.ID].Languages != nil {
-			item.Languages = existingItemIDs[item.ID].Languages
-		}
+		// and score the item with them
+		item = db.PreserveLanguages(item, existingItemIDs[item.ID])
+		item = db.WithQuality(item, c.qualityWeights)
 
 		// Delete the item from the existingItems map
 		delete(existingItemIDs, item.ID)
@@ -176,101 +216,375 @@ func (c *DBServiceRedis) SetRepoList(ctx context.Context, list entities.RepoList
 		}
 	}
 
+	// Rebuild the suggestion dictionaries for the new list
+	err = c.rebuildSuggestions(ctx, db.SuggestFields...)
+	if err != nil {
+		return errors.Wrap(err, "Error rebuilding suggestions")
+	}
+
+	// Rebuild the leaderboards for the new list
+	err = c.rebuildLeaderboards(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Error rebuilding leaderboards")
+	}
+
 	return nil
 }
 
-// GetRepoList retrieves a list of repo items from the db
-func (c *DBServiceRedis) GetRepoList(ctx context.Context, filters db.GetRepoListFilters) (entities.RepoList, error) {
-	//Get all the repos from redis
+// GetRepoList retrieves a page of repo items from the db
+// A filters.Limit of 0 retrieves all the matching repo items
+func (c *DBServiceRedis) GetRepoList(ctx context.Context, filters db.GetRepoListFilters) (entities.RepoPage, error) {
 
 	// Build the query
-	query := buildQueryFromFilters(filters)
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return entities.RepoPage{}, err
+	}
 
-	// Make the search for the keys
-	// TODO: Factor the call to c.pool.Do out to a function for making a search for keys
-	var err error
-	var list struct {
-		Results []struct {
-			ID string
+	// Build the sort order
+	order, err := buildSortOrderFromFilters(filters)
+	if err != nil {
+		return entities.RepoPage{}, err
+	}
+
+	s := search{query: query, order: order, fields: filters.Fields, withScores: filters.Text != nil}
+
+	// Make the search
+	repoList := RepoList{}
+	scores := map[int64]float64{}
+	var total int
+	if filters.Limit > 0 {
+		repoList, scores, total, err = c.searchRepoItems(ctx, s, filters.Offset, filters.Limit)
+		if err != nil {
+			return entities.RepoPage{}, err
+		}
+	} else {
+		// No limit. Page through the search results until all the items are collected
+		for offset := filters.Offset; ; offset += searchPageSize {
+			pageItems, pageScores, pageTotal, err := c.searchRepoItems(ctx, s, offset, searchPageSize)
+			if err != nil {
+				return entities.RepoPage{}, err
+			}
+			repoList = append(repoList, pageItems...)
+			for id, score := range pageScores {
+				scores[id] = score
+			}
+			total = pageTotal
+			if pageTotal-offset <= searchPageSize {
+				break
+			}
 		}
 	}
-	res, err := c.pool.Do(ctx, "FT.SEARCH", "idx:repo", query, "LIMIT", 0, 100, "NOCONTENT").Result()
-	if err != nil {
-		return nil, errors.Wrap(err, "Error searching for repos")
+
+	// Count the facets over the same query
+	var facets entities.Facets
+	if len(filters.Facets) > 0 {
+		facets, err = c.getFacets(ctx, query, filters.Facets)
+		if err != nil {
+			return entities.RepoPage{}, err
+		}
 	}
 
-	// Decode the returned data
-	err = mapstructure.Decode(res, &list)
+	// The relevance scores of the text search
+	var hits map[int64]entities.SearchHit
+	if s.withScores {
+		hits = make(map[int64]entities.SearchHit, len(scores))
+		for id, score := range scores {
+			hits[id] = entities.SearchHit{Score: score}
+		}
+	}
+
+	// Return the RepoPage
+	return entities.RepoPage{
+		Items:  ConvertRepoListI2E(repoList),
+		Total:  total,
+		Facets: facets,
+		Hits:   hits,
+	}, nil
+}
+
+// search holds the arguments of a FT.SEARCH over the repo index
+//   - query, order: built from the filters (a nil order sorts the results by relevance)
+//   - sortBy: the raw SORTBY arguments, for the searches sorted by a value computed by the query (see GetSimilarRepos)
+//   - fields: the document fields to load (nil loads the whole documents)
+//   - withScores: request the relevance score of each document
+//   - params: the names and values of the parameters of the query (the query is then run with DIALECT 2)
+type search struct {
+	query      string
+	order      *sortOrder
+	sortBy     []interface{}
+	fields     []string
+	withScores bool
+	params     []interface{}
+}
+
+// args returns the arguments of the FT.SEARCH command for the page [offset, offset+limit)
+func (s search) args(offset, limit int) []interface{} {
+	args := []interface{}{"FT.SEARCH", repoIndex, s.query}
+	if s.withScores {
+		args = append(args, "WITHSCORES")
+	}
+	args = append(args, s.sortBy...)
+	if len(s.params) > 0 {
+		args = append(args, "PARAMS", len(s.params))
+		args = append(append(args, s.params...), "DIALECT", 2)
+	}
+	return append(args, "LIMIT", offset, limit)
+}
+
+// searchResults is the decoded result of a FT.SEARCH
+type searchResults struct {
+	Total_Results int
+	Results       []struct {
+		ID               string
+		Score            float64
+		Extra_Attributes map[string]interface{}
+	}
+}
+
+// searchRepoItems searches the index for the documents matching the query, in the order of the search
+// It returns the documents in the page [offset, offset+limit), their scores by ID (when requested), and the total
+// number of matching documents.
+// When fields is set, only those fields are loaded (see searchFields), otherwise the whole documents are loaded.
+func (c *DBServiceRedis) searchRepoItems(ctx context.Context, s search, offset, limit int) (
+	RepoList, map[int64]float64, int, error,
+) {
+	if s.order != nil {
+		return c.searchSorted(ctx, s, offset, limit)
+	}
+	if len(s.fields) > 0 {
+		return c.searchFields(ctx, s, offset, limit)
+	}
+
+	keys, keyScores, total, err := c.searchKeys(ctx, s, offset, limit)
 	if err != nil {
-		return nil, err
+		return nil, nil, 0, err
 	}
 
 	// No results
-	if len(list.Results) == 0 {
-		return entities.RepoList{}, nil
+	if len(keys) == 0 {
+		return RepoList{}, map[int64]float64{}, total, nil
 	}
 
 	// Fetch the full json using the keys
+	repoList, err := c.getRepoItems(ctx, keys)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	scores := make(map[int64]float64, len(repoList))
+	for _, doc := range repoList {
+		scores[doc.ID] = keyScores[string(doc.getKey())]
+	}
+	return repoList, scores, total, nil
+}
+
+// searchKeys searches the index for the keys of the documents matching the query, in the sortBy order (or by relevance)
+// It returns the keys in the page [offset, offset+limit), their scores by key (when requested), and the total number
+// of matching documents
+func (c *DBServiceRedis) searchKeys(ctx context.Context, s search, offset, limit int) (
+	[]string, map[string]float64, int, error,
+) {
+	res, err := c.pool.Do(ctx, append(s.args(offset, limit), "NOCONTENT")...).Result()
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "Error searching for repos")
+	}
+
+	// Decode the returned data
+	var list searchResults
+	err = mapstructure.Decode(res, &list)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
 	keys := make([]string, len(list.Results))
+	scores := make(map[string]float64, len(list.Results))
 	for i := 0; i < len(keys); i++ {
 		keys[i] = list.Results[i].ID
+		scores[keys[i]] = list.Results[i].Score
 	}
 
+	return keys, scores, list.Total_Results, nil
+}
+
+// getRepoItems fetches the JSON documents for the given keys
+// Documents deleted since the keys were found are skipped
+func (c *DBServiceRedis) getRepoItems(ctx context.Context, keys []string) (RepoList, error) {
+
 	jsonList, err := c.pool.JSONMGet(ctx, "$", keys...).Result()
 	if err != nil {
 		return nil, err
 	}
 
-	repoList := make(RepoList, len(jsonList))
+	repoList := make(RepoList, 0, len(jsonList))
 	for i := 0; i < len(jsonList); i++ {
+		data, ok := jsonList[i].(string)
+		if !ok {
+			continue
+		}
 		var doc []RepoItem
-		data := jsonList[i].(string)
 		err = json.Unmarshal([]byte(data), &doc)
 		if err != nil {
 			return nil, errors.Wrap(err, "Error unmarshaling document")
 		}
-		repoList[i] = doc[0]
+		repoList = append(repoList, doc[0])
 	}
 
-	// Return the RepoList
-	return ConvertRepoListI2E(repoList), nil
+	return repoList, nil
 }
 
 // buildQueryFromFilters builds a query string from the filters
-func buildQueryFromFilters(filters db.GetRepoListFilters) string {
+// Every user supplied value is escaped by the query builder. Values that can never be matched return an error
+// wrapping db.ErrInvalidFilter.
+func buildQueryFromFilters(filters db.GetRepoListFilters) (string, error) {
 
 	// Build the filters
-	var filterParams []string
+	var clauses []qb.Clause
+	addClause := func(clause qb.Clause, err error) error {
+		if err != nil {
+			return err
+		}
+		clauses = append(clauses, clause)
+		return nil
+	}
+
 	if filters.Name != nil && *filters.Name != "" {
-		filterParams = append(filterParams, fmt.Sprintf("@name: *%s*", *filters.Name))
+		if err := addClause(qb.Text("name", *filters.Name)); err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "name: %v", err)
+		}
 	}
 
 	if filters.Language != nil && *filters.Language != "" {
-		filterParams = append(filterParams, fmt.Sprintf("@all_languages: {*%s*}", *filters.Language))
+		if err := addClause(qb.Tag("all_languages", *filters.Language)); err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "language: %v", err)
+		}
+	}
+
+	if filters.DominantLanguage != nil && *filters.DominantLanguage != "" {
+		if err := addClause(qb.Tag("dominant_language", *filters.DominantLanguage)); err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "dominant language: %v", err)
+		}
 	}
 
 	if filters.License != nil && *filters.License != "" {
-		filterParams = append(filterParams, fmt.Sprintf("@license: *%s*", *filters.License))
+		if err := addClause(qb.Text("license", *filters.License)); err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "license: %v", err)
+		}
+	}
+
+	if filters.LicenseKey != nil && *filters.LicenseKey != "" {
+		if err := addClause(qb.Tag("license_key", *filters.LicenseKey)); err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "license key: %v", err)
+		}
+	}
+
+	if filters.LicenseFamily != nil && *filters.LicenseFamily != "" {
+		if err := addClause(qb.Tag("license_family", *filters.LicenseFamily)); err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "license family: %v", err)
+		}
+	}
+
+	if filters.Owner != nil && *filters.Owner != "" {
+		if err := addClause(qb.Tag("owner", *filters.Owner)); err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "owner: %v", err)
+		}
 	}
 
 	if filters.AllowForking != nil {
-		filterParams = append(filterParams, fmt.Sprintf("@allow_forking:{%v}", *filters.AllowForking))
+		clauses = append(clauses, qb.Bool("allow_forking", *filters.AllowForking))
 	}
 
 	if filters.HasOpenIssues != nil {
+		var err error
 		if *filters.HasOpenIssues {
-			filterParams = append(filterParams, "@open_issues_count:[1 +inf]")
+			err = addClause(qb.Range("open_issues_count", floatPtr(1), nil, false, false))
 		} else {
-			filterParams = append(filterParams, "@open_issues_count:[0 0]")
+			err = addClause(qb.Range("open_issues_count", floatPtr(0), floatPtr(0), false, false))
+		}
+		if err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "has open issues: %v", err)
+		}
+	}
+
+	intRanges := []struct {
+		attribute string
+		r         db.IntRange
+	}{
+		{"size", filters.Size},
+		{"forks_count", filters.ForksCount},
+		{"watchers_count", filters.WatchersCount},
+		{"open_issues_count", filters.OpenIssuesCount},
+		{"language_count", filters.LanguageCount},
+		{"quality", filters.Quality},
+	}
+	for _, ir := range intRanges {
+		if ir.r.Min != nil || ir.r.Max != nil {
+			if err := addClause(intRange(ir.attribute, ir.r)); err != nil {
+				return "", errors.Wrapf(db.ErrInvalidFilter, "%s: %v", ir.attribute, err)
+			}
+		}
+	}
+
+	timeRanges := []struct {
+		attribute string
+		r         db.TimeRange
+	}{
+		{"created_at", filters.CreatedAt},
+		{"updated_at", filters.UpdatedAt},
+	}
+	for _, tr := range timeRanges {
+		if tr.r.After != nil || tr.r.Before != nil {
+			if err := addClause(timeRange(tr.attribute, tr.r)); err != nil {
+				return "", errors.Wrapf(db.ErrInvalidFilter, "%s: %v", tr.attribute, err)
+			}
+		}
+	}
+
+	if filters.Text != nil {
+		if err := addClause(qb.FullText(textAttributes, *filters.Text)); err != nil {
+			return "", errors.Wrapf(db.ErrInvalidFilter, "text: %v", err)
 		}
 	}
 
-	filter := "*"
-	if len(filterParams) > 0 {
-		filter = strings.Join(filterParams, " ")
+	if filters.Query != nil {
+		if err := addClause(compileQuery(filters.Query)); err != nil {
+			if errors.Is(err, qb.ErrInvalidValue) {
+				return "", errors.Wrapf(db.ErrInvalidFilter, "q: %v", err)
+			}
+			return "", errors.Wrap(err, "could not compile the search query")
+		}
 	}
 
-	return filter
+	// And() of no clauses matches everything
+	return string(qb.And(clauses...)), nil
+}
+
+// intRange returns an inclusive numeric range filter on the attribute
+func intRange(attribute string, r db.IntRange) (qb.Clause, error) {
+	var min, max *float64
+	if r.Min != nil {
+		min = floatPtr(float64(*r.Min))
+	}
+	if r.Max != nil {
+		max = floatPtr(float64(*r.Max))
+	}
+	return qb.Range(attribute, min, max, false, false)
+}
+
+// timeRange returns an exclusive numeric range filter on the (unix time) attribute
+func timeRange(attribute string, r db.TimeRange) (qb.Clause, error) {
+	var min, max *float64
+	if r.After != nil {
+		min = floatPtr(float64(r.After.Unix()))
+	}
+	if r.Before != nil {
+		max = floatPtr(float64(r.Before.Unix()))
+	}
+	return qb.Range(attribute, min, max, true, true)
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
 }
 
 // GetRepoItem retrieves a repo item from the db
@@ -278,8 +592,11 @@ func (c *DBServiceRedis) GetRepoItem(ctx context.Context, repoID int64) (entitie
 	key := getRepoKey(repoID)
 
 	jsonData, err := c.pool.JSONGet(ctx, string(key)).Result()
+	if errors.Is(err, redis.Nil) || (err == nil && jsonData == "") {
+		return entities.RepoItem{}, db.ErrNotFound
+	}
 	if err != nil {
-		return entities.RepoItem{}, err
+		return entities.RepoItem{}, errors.Wrap(err, "Error getting repo")
 	}
 
 	var doc RepoItem
@@ -292,10 +609,16 @@ func (c *DBServiceRedis) GetRepoItem(ctx context.Context, repoID int64) (entitie
 }
 
 // GetAvgNumForksPerRepoByLanguage returns the average number of forks per repo by language
-func (c *DBServiceRedis) GetAvgNumForksPerRepoByLanguage(ctx context.Context) (map[string]float32, error) {
+func (c *DBServiceRedis) GetAvgNumForksPerRepoByLanguage(ctx context.Context, filters db.GetRepoListFilters) (
+	map[string]float32, error,
+) {
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return nil, err
+	}
 
 	res, err := c.pool.Do(
-		ctx, "FT.AGGREGATE", "idx:repo", "*", "LOAD", "1", "@forks_count", "GROUPBY", "1", "@language",
+		ctx, "FT.AGGREGATE", repoIndex, query, "LOAD", "1", "@forks_count", "GROUPBY", "1", "@language",
 		"REDUCE", "AVG", "1", "@forks_count", "AS", "count",
 		"LIMIT", "0", "1000",
 	).Result()
@@ -307,9 +630,16 @@ func (c *DBServiceRedis) GetAvgNumForksPerRepoByLanguage(ctx context.Context) (m
 }
 
 // GetNumReposByLanguage returns the number of repos by language
-func (c *DBServiceRedis) GetNumReposByLanguage(ctx context.Context) (map[string]int, error) {
+func (c *DBServiceRedis) GetNumReposByLanguage(ctx context.Context, filters db.GetRepoListFilters) (
+	map[string]int, error,
+) {
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
 	res, err := c.pool.Do(
-		ctx, "FT.AGGREGATE", "idx:repo", "*", "LOAD", "1", "@name", "GROUPBY", "1", "@language",
+		ctx, "FT.AGGREGATE", repoIndex, query, "LOAD", "1", "@name", "GROUPBY", "1", "@language",
 		"REDUCE", "COUNT_DISTINCT", "1", "@name", "AS", "count",
 		"LIMIT", "0", "1000",
 	).Result()
@@ -321,9 +651,16 @@ func (c *DBServiceRedis) GetNumReposByLanguage(ctx context.Context) (map[string]
 }
 
 // GetAvgNumOpenIssuesByLanguage returns the average number of open issues by language
-func (c *DBServiceRedis) GetAvgNumOpenIssuesByLanguage(ctx context.Context) (map[string]float32, error) {
+func (c *DBServiceRedis) GetAvgNumOpenIssuesByLanguage(ctx context.Context, filters db.GetRepoListFilters) (
+	map[string]float32, error,
+) {
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
 	res, err := c.pool.Do(
-		ctx, "FT.AGGREGATE", "idx:repo", "*", "LOAD", "1", "@open_issues_count", "GROUPBY", "1", "@language",
+		ctx, "FT.AGGREGATE", repoIndex, query, "LOAD", "1", "@open_issues_count", "GROUPBY", "1", "@language",
 		"REDUCE", "AVG", "1", "@open_issues_count", "AS", "count",
 		"LIMIT", "0", "1000",
 	).Result()
@@ -335,9 +672,16 @@ func (c *DBServiceRedis) GetAvgNumOpenIssuesByLanguage(ctx context.Context) (map
 }
 
 // GetAvgSizeByLanguage returns the average size by language
-func (c *DBServiceRedis) GetAvgSizeByLanguage(ctx context.Context) (map[string]float32, error) {
+func (c *DBServiceRedis) GetAvgSizeByLanguage(ctx context.Context, filters db.GetRepoListFilters) (
+	map[string]float32, error,
+) {
+	query, err := buildQueryFromFilters(filters)
+	if err != nil {
+		return nil, err
+	}
+
 	res, err := c.pool.Do(
-		ctx, "FT.AGGREGATE", "idx:repo", "*", "LOAD", "1", "@size", "GROUPBY", "1", "@language",
+		ctx, "FT.AGGREGATE", repoIndex, query, "LOAD", "1", "@size", "GROUPBY", "1", "@language",
 		"REDUCE", "AVG", "1", "@size", "AS", "count",
 		"LIMIT", "0", "1000",
 	).Result()
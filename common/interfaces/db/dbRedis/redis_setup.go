@@ -6,6 +6,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/Scalingo/sclng-backend-test-v1/common/quality"
 	"github.com/Scalingo/sclng-backend-test-v1/common/util"
 	"github.com/pkg/errors"
 	"github.com/redis/go-redis/v9"
@@ -25,12 +26,24 @@ func NewDBServiceRedis(log logrus.FieldLogger, hostPort, keyPrefix string) (*DBS
 	)
 
 	return &DBServiceRedis{
-		log:       log,
-		keyPrefix: keyPrefix,
-		hostPort:  hostPort,
+		log:            log,
+		keyPrefix:      keyPrefix,
+		hostPort:       hostPort,
+		qualityWeights: quality.DefaultWeights,
 	}, nil
 }
 
+// SetQualityWeights sets the weights of the quality scores of the items stored from now on
+// It returns an error if the weights are not valid (see quality.Weights.Validate)
+// It must be called before the service is started.
+func (c *DBServiceRedis) SetQualityWeights(weights quality.Weights) error {
+	if err := weights.Validate(); err != nil {
+		return err
+	}
+	c.qualityWeights = weights
+	return nil
+}
+
 // Start starts the redis service in a goroutine
 // Returns an error if the service fails to start
 // Graceful shutdown when an interrupt signal is received from the OS
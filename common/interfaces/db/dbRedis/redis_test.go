@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/Scalingo/go-utils/logger"
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
 	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
 	"github.com/Scalingo/sclng-backend-test-v1/common/util"
 	"github.com/ory/dockertest/v3"
@@ -107,3 +108,240 @@ func TestSetRepoList_PreserveLanguages(t *testing.T) {
 	}
 	db.SetRepoList_PreserveLanguages(t, redisService, testKey)
 }
+
+func TestDBServiceRedis_GetRepoList_Pagination(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_Pagination(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_Sort(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_Sort(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_SortTies(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_SortTies(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_RangeFilters(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_RangeFilters(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_Query(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_Query(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_SpecialCharacters(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_SpecialCharacters(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoItem_NotFound(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoItem_NotFound(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_Fields(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_Fields(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_Facets(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_Facets(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetSuggestions(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetSuggestions(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_Text(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_Text(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetStatsByLanguage_Filters(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetStatsByLanguage_Filters(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_Aggregate(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.Aggregate(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetLanguageStats(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetLanguageStats(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetLanguageCooccurrence(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetLanguageCooccurrence(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetDistributions(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetDistributions(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetStatsHistory(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetStatsHistory(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetLeaderboard(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetLeaderboard(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetLeaderboard_DerivedLanguage(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetLeaderboard_DerivedLanguage(t, redisService, testKey)
+}
+
+// TestDBServiceRedis_GetLeaderboard_DeletedItem checks that the items deleted since the leaderboards were built are
+// skipped instead of failing the whole leaderboard
+func TestDBServiceRedis_GetLeaderboard_DeletedItem(t *testing.T) {
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", FullName: "a/repo1", Owner: "a", Language: "Go", ForksCount: 5},
+		{ID: 2, Name: "repo2", FullName: "b/repo2", Owner: "b", Language: "Go", ForksCount: 9},
+	}
+	if err := redisService.SetRepoList(context.Background(), list); err != nil {
+		t.Fatalf("SetRepoList() error = %v", err)
+	}
+	if err := redisService.pool.Del(context.Background(), string(getRepoKey(2))).Err(); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+
+	got, err := redisService.GetLeaderboard(
+		context.Background(), db.LeaderboardQuery{Metric: db.LeaderboardForks, Limit: 10},
+	)
+	if err != nil {
+		t.Fatalf("GetLeaderboard() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Item.ID != 1 {
+		t.Errorf("GetLeaderboard() = %+v, want the entry of the item 1 only", got)
+	}
+}
+
+func TestDBServiceRedis_GetOwners(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetOwners(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetLanguages(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetLanguages(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetLicenseStats(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetLicenseStats(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_SetRepoItemLanguages_DerivedFields(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.SetRepoItemLanguages_DerivedFields(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetSimilarRepos(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetSimilarRepos(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetSimilarRepos_UnknownLanguages(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetSimilarRepos_UnknownLanguages(t, redisService, testKey)
+}
+
+func TestDBServiceRedis_GetRepoList_Quality(t *testing.T) {
+	testKey := t.Name()
+	if err := redisService.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	db.GetRepoList_Quality(t, redisService, testKey)
+}
@@ -0,0 +1,150 @@
+package dbRedis
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/Scalingo/sclng-backend-test-v1/common/linguist"
+)
+
+// languageDimensions maps the canonical names of the languages of the Linguist table to their dimension in the
+// language vectors
+var languageDimensions = func() map[string]int {
+	out := map[string]int{}
+	for i, name := range linguist.Names() {
+		out[name] = i
+	}
+	return out
+}()
+
+// unknownLanguageDims is the number of dimensions the languages that are not in the Linguist table are hashed to
+// Sharing a single dimension would make any two unknown languages identical for the KNN query.
+const unknownLanguageDims = 64
+
+// languageVectorDims is the number of dimensions of the language vectors
+// It follows the Linguist table, so the version of the repo index must be bumped when the table changes.
+var languageVectorDims = len(languageDimensions) + unknownLanguageDims
+
+// languageDimension returns the dimension of a language in the language vectors
+func languageDimension(language string) int {
+	if i, ok := languageDimensions[language]; ok {
+		return i
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(language))
+	return len(languageDimensions) + int(h.Sum32()%unknownLanguageDims)
+}
+
+// languageVector returns the dense, normalised vector of a languages breakdown indexed in the language_vector field,
+// or nil when the breakdown has no bytes (the document is then left out of the vector index)
+func languageVector(langs entities.Languages) []float32 {
+	vector := make([]float64, languageVectorDims)
+	for language, dimension := range db.NewLanguageVector(langs) {
+		vector[languageDimension(language)] += dimension
+	}
+
+	var norm float64
+	for _, x := range vector {
+		norm += x * x
+	}
+	if norm == 0 {
+		return nil
+	}
+	norm = math.Sqrt(norm)
+
+	out := make([]float32, languageVectorDims)
+	for i, x := range vector {
+		out[i] = float32(x / norm)
+	}
+	return out
+}
+
+// encodeVector encodes a vector as the blob of a query parameter: the FLOAT32 values, little-endian
+func encodeVector(vector []float32) []byte {
+	out := make([]byte, 4*len(vector))
+	for i, x := range vector {
+		binary.LittleEndian.PutUint32(out[4*i:], math.Float32bits(x))
+	}
+	return out
+}
+
+// knnCandidatesFactor is the number of items read from the KNN query per candidate
+// The unknown languages that hash to the same dimension make the KNN distances approximate, so more items than the
+// candidates are read, and the candidates are then selected with the exact cosine similarity (see nearestCandidates).
+const knnCandidatesFactor = 2
+
+// GetSimilarRepos returns the items most similar to the repository of the query
+// The candidates are the nearest items by language found by a KNN query over the language_vector field, which is
+// computed from the languages breakdown each time a document is stored.
+func (c *DBServiceRedis) GetSimilarRepos(ctx context.Context, query db.SimilarQuery) (
+	[]entities.SimilarRepo, error,
+) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	target, err := c.GetRepoItem(ctx, query.ID)
+	if err != nil {
+		return nil, err
+	}
+	vector := languageVector(target.Languages)
+	if vector == nil {
+		return []entities.SimilarRepo{}, nil
+	}
+
+	// The repository is its own nearest item, so one more item is read
+	k := knnCandidatesFactor*query.Candidates() + 1
+	s := search{
+		query:  "*=>[KNN $k @language_vector $vector AS vector_distance]",
+		sortBy: []interface{}{"SORTBY", "vector_distance"},
+		params: []interface{}{"k", k, "vector", encodeVector(vector)},
+	}
+	docs, _, _, err := c.searchRepoItems(ctx, s, 0, k)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := nearestCandidates(target, ConvertRepoListI2E(docs), query.Candidates())
+	return db.RankSimilar(target, candidates, query), nil
+}
+
+// nearestCandidates returns the n items nearest to the target by the exact cosine similarity of their languages, ties
+// by descending ID, as the memory backend selects them
+func nearestCandidates(target entities.RepoItem, items entities.RepoList, n int) entities.RepoList {
+	type neighbour struct {
+		item       entities.RepoItem
+		similarity float64
+	}
+
+	vector := db.NewLanguageVector(target.Languages)
+	neighbours := make([]neighbour, 0, len(items))
+	for _, item := range items {
+		itemVector := db.NewLanguageVector(item.Languages)
+		if item.ID == target.ID || itemVector == nil {
+			continue
+		}
+		neighbours = append(neighbours, neighbour{item: item, similarity: vector.Cosine(itemVector)})
+	}
+	sort.Slice(
+		neighbours, func(i, j int) bool {
+			if neighbours[i].similarity != neighbours[j].similarity {
+				return neighbours[i].similarity > neighbours[j].similarity
+			}
+			return neighbours[i].item.ID > neighbours[j].item.ID
+		},
+	)
+	if len(neighbours) > n {
+		neighbours = neighbours[:n]
+	}
+
+	out := make(entities.RepoList, len(neighbours))
+	for i, n := range neighbours {
+		out[i] = n.item
+	}
+	return out
+}
@@ -0,0 +1,140 @@
+package dbRedis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+)
+
+// sortOrder is the order of the results of a search: by the sortable attribute, then by ascending id (or descending
+// id with descendingTies).
+// FT.SEARCH only sorts by one attribute, so the items with equal values would come back in an arbitrary order,
+// and the pages over them would not be stable. The id breaks the ties, as in the memory backend.
+type sortOrder struct {
+	attribute      string
+	ascending      bool
+	descendingTies bool
+}
+
+// buildSortOrderFromFilters builds the order of a search from the filters
+// Sorting by relevance returns a nil order: the results of a search are ordered by score
+func buildSortOrderFromFilters(filters db.GetRepoListFilters) (*sortOrder, error) {
+	sortBy := filters.SortBy
+	if sortBy == "" {
+		sortBy = db.SortByCreatedAt
+	}
+	if sortBy == db.SortByRelevance {
+		if filters.Text == nil {
+			return nil, errors.Wrap(db.ErrInvalidFilter, "sorting by relevance requires a text search")
+		}
+		return nil, nil
+	}
+
+	attribute, ok := sortAttributes[sortBy]
+	if !ok {
+		return nil, errors.Errorf("unknown sort field %q", sortBy)
+	}
+
+	return &sortOrder{attribute: attribute, ascending: filters.SortAscending}, nil
+}
+
+// searchSorted searches the index for the documents matching the query, in the order of the search
+// A single FT.AGGREGATE sorts the matching documents by the attribute of the order then by id, and loads the
+// documents of the page [offset, offset+limit) (or only their requested fields, see searchFields). It returns them
+// with their scores by ID (when requested) and the total number of matching documents.
+func (c *DBServiceRedis) searchSorted(ctx context.Context, s search, offset, limit int) (
+	RepoList, map[int64]float64, int, error,
+) {
+	direction, tiesDirection := "DESC", "ASC"
+	if s.order.ascending {
+		direction = "ASC"
+	}
+	if s.order.descendingTies {
+		tiesDirection = "DESC"
+	}
+
+	args := []interface{}{"FT.AGGREGATE", repoIndex, s.query}
+	if s.withScores {
+		args = append(args, "ADDSCORES")
+	}
+
+	// Load the whole JSON documents ($), or the requested fields: LOAD <count> $.<field> AS <field> ...
+	if len(s.fields) == 0 {
+		args = append(args, "LOAD", 1, "$")
+	} else {
+		load := []interface{}{"LOAD", 0}
+		for _, field := range append([]string{"id"}, s.fields...) {
+			if _, ok := docFields[field]; !ok {
+				return nil, nil, 0, errors.Wrapf(db.ErrInvalidFilter, "unknown field %q", field)
+			}
+			load = append(load, "$."+field, "AS", field)
+		}
+		load[1] = len(load) - 2
+		args = append(args, load...)
+	}
+
+	args = append(
+		args, "SORTBY", 4, "@"+s.order.attribute, direction, "@id", tiesDirection,
+		"LIMIT", offset, limit,
+	)
+	if len(s.params) > 0 {
+		args = append(args, "PARAMS", len(s.params))
+		args = append(append(args, s.params...), "DIALECT", 2)
+	}
+
+	res, err := c.pool.Do(ctx, args...).Result()
+	if err != nil {
+		return nil, nil, 0, errors.Wrap(err, "Error searching for repos")
+	}
+
+	// Decode the returned data
+	var list struct {
+		Total_Results int
+		Results       []struct {
+			Extra_Attributes map[string]interface{}
+		}
+	}
+	err = mapstructure.Decode(res, &list)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	repoList := make(RepoList, 0, len(list.Results))
+	scores := make(map[int64]float64, len(list.Results))
+	for _, row := range list.Results {
+		score, _ := row.Extra_Attributes["__score"].(string)
+		delete(row.Extra_Attributes, "__score")
+
+		doc, err := decodeAggregateDoc(row.Extra_Attributes, len(s.fields) == 0)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		repoList = append(repoList, doc)
+		if s.withScores {
+			if scores[doc.ID], err = strconv.ParseFloat(score, 64); err != nil {
+				return nil, nil, 0, errors.Wrapf(err, "Error decoding the score of the repo %d", doc.ID)
+			}
+		}
+	}
+
+	return repoList, scores, list.Total_Results, nil
+}
+
+// decodeAggregateDoc rebuilds a RepoItem document from a row of a FT.AGGREGATE: the whole JSON document loaded as $,
+// or the fields loaded by name (see decodePartialDoc)
+func decodeAggregateDoc(attributes map[string]interface{}, whole bool) (RepoItem, error) {
+	if !whole {
+		return decodePartialDoc(attributes)
+	}
+
+	data, _ := attributes["$"].(string)
+	var doc RepoItem
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return RepoItem{}, errors.Wrap(err, "Error unmarshaling document")
+	}
+	return doc, nil
+}
@@ -0,0 +1,175 @@
+package dbRedis
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// maxSuggestCandidates is the number of candidates read from a suggestion dictionary before ranking them by count
+// FT.SUGGET orders the candidates by its own score, so more candidates than requested are read and ranked in Go
+const maxSuggestCandidates = 1000
+
+// suggestDocFields maps the suggest fields to the document fields needed to count their values
+var suggestDocFields = map[db.SuggestField][]string{
+	db.SuggestName:     {"name"},
+	db.SuggestOwner:    {"owner"},
+	db.SuggestLanguage: {"language", "languages"},
+	db.SuggestLicense:  {"license"},
+}
+
+// maxSuggestUpdateRetries is the number of times an update of the suggestion counts is retried when another writer
+// changed the counts during the update
+const maxSuggestUpdateRetries = 10
+
+// getSuggestKey returns the key of the suggestion dictionary of a field
+func getSuggestKey(field db.SuggestField) string {
+	return "sug:" + string(field)
+}
+
+// getSuggestCountsKey returns the key of the hash holding the number of repositories with each value of a field
+// FT.SUGGET cannot look up a single value, so the counts in the payloads of the dictionary are also kept in the hash
+func getSuggestCountsKey(field db.SuggestField) string {
+	return "sugcount:" + string(field)
+}
+
+// GetSuggestions returns the values of the field starting with the prefix (case-insensitive),
+// ranked by the number of repositories with the value
+func (c *DBServiceRedis) GetSuggestions(ctx context.Context, field db.SuggestField, prefix string, limit int) (
+	[]entities.Suggestion, error,
+) {
+	if _, ok := suggestDocFields[field]; !ok {
+		return nil, errors.Errorf("unknown suggest field %q", field)
+	}
+
+	// The count of each value is stored in the payload of the suggestion
+	res, err := c.pool.Do(
+		ctx, "FT.SUGGET", getSuggestKey(field), prefix, "WITHPAYLOADS", "MAX", maxSuggestCandidates,
+	).StringSlice()
+	if errors.Is(err, redis.Nil) {
+		return []entities.Suggestion{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting suggestions")
+	}
+
+	out := make([]entities.Suggestion, 0, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		count, err := strconv.Atoi(res[i+1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error decoding the count of suggestion %q", res[i])
+		}
+		out = append(out, entities.Suggestion{Value: res[i], Count: count})
+	}
+
+	return db.RankSuggestions(out, limit), nil
+}
+
+// rebuildSuggestions rebuilds the suggestion dictionaries of the fields from the current documents
+// Each value is added with its number of repositories as the score and the payload, and in the counts hash.
+// The dictionaries are replaced in a transaction, so readers never see a partially built dictionary.
+func (c *DBServiceRedis) rebuildSuggestions(ctx context.Context, fields ...db.SuggestField) error {
+
+	// Load only the fields needed to count the values
+	var docFields []string
+	for _, field := range fields {
+		docFields = append(docFields, suggestDocFields[field]...)
+	}
+	page, err := c.GetRepoList(ctx, db.GetRepoListFilters{Fields: docFields})
+	if err != nil {
+		return errors.Wrap(err, "could not get the items")
+	}
+
+	_, err = c.pool.TxPipelined(
+		ctx, func(pipe redis.Pipeliner) error {
+			for _, field := range fields {
+				counts := map[string]int{}
+				for _, item := range page.Items {
+					for _, value := range db.SuggestionValues(item, field) {
+						counts[value]++
+					}
+				}
+
+				key, countsKey := getSuggestKey(field), getSuggestCountsKey(field)
+				pipe.Del(ctx, key, countsKey)
+				for value, count := range counts {
+					pipe.Do(ctx, "FT.SUGADD", key, value, count, "PAYLOAD", strconv.Itoa(count))
+					pipe.HSet(ctx, countsKey, value, count)
+				}
+			}
+			return nil
+		},
+	)
+	if err != nil {
+		return errors.Wrap(err, "Error storing suggestions")
+	}
+
+	return nil
+}
+
+// updateSuggestions updates the suggestion dictionary of the field for an item changed from old to new
+// Only the counts of the values added to or removed from the item change, so the other documents are not read.
+// The counts are read and written in an optimistic transaction on the counts hash, retried when another writer
+// changed them in the meantime. A value no repository has anymore is removed from the dictionary.
+func (c *DBServiceRedis) updateSuggestions(
+	ctx context.Context, field db.SuggestField, old, new entities.RepoItem,
+) error {
+	deltas := map[string]int{}
+	for _, value := range db.SuggestionValues(old, field) {
+		deltas[value]--
+	}
+	for _, value := range db.SuggestionValues(new, field) {
+		deltas[value]++
+	}
+	var values []string
+	for value, delta := range deltas {
+		if delta != 0 {
+			values = append(values, value)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	key, countsKey := getSuggestKey(field), getSuggestCountsKey(field)
+	update := func(tx *redis.Tx) error {
+		counts, err := tx.HMGet(ctx, countsKey, values...).Result()
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(
+			ctx, func(pipe redis.Pipeliner) error {
+				for i, value := range values {
+					str, _ := counts[i].(string)
+					count, _ := strconv.Atoi(str)
+					count += deltas[value]
+					if count > 0 {
+						pipe.Do(ctx, "FT.SUGADD", key, value, count, "PAYLOAD", strconv.Itoa(count))
+						pipe.HSet(ctx, countsKey, value, count)
+					} else {
+						pipe.Do(ctx, "FT.SUGDEL", key, value)
+						pipe.HDel(ctx, countsKey, value)
+					}
+				}
+				return nil
+			},
+		)
+		return err
+	}
+
+	for retries := 0; ; retries++ {
+		err := c.pool.Watch(ctx, update, countsKey)
+		if errors.Is(err, redis.TxFailedErr) && retries < maxSuggestUpdateRetries {
+			continue
+		}
+		if err != nil {
+			return errors.Wrap(err, "Error updating suggestions")
+		}
+		return nil
+	}
+}
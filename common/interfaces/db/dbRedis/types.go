@@ -28,6 +28,7 @@ type RepoItem struct {
 	Language        string    `redis:"language" json:"language"`
 	Languages       *string   `redis:"languages,omitempty" json:"languages,omitempty"`
 	LicenseName     string    `redis:"license" json:"license"`
+	LicenseKey      string    `redis:"license_key" json:"license_key"`
 	ForksCount      int       `redis:"forks_count" json:"forks_count"`
 	OpenIssuesCount int       `redis:"open_issues_count" json:"open_issues_count"`
 	WatchersCount   int       `redis:"watchers_count" json:"watchers_count"`
@@ -38,6 +39,34 @@ type RepoItem struct {
 	HasWiki         bool      `redis:"has_wiki" json:"has_wiki"`
 	HasPages        bool      `redis:"has_pages" json:"has_pages"`
 	HasDiscussions  bool      `redis:"has_discussions" json:"has_discussions"`
+
+	LanguageCount       int                `redis:"language_count" json:"language_count"`
+	DominantLanguage    string             `redis:"dominant_language" json:"dominant_language"`
+	LanguageDerived     bool               `redis:"language_derived" json:"language_derived"`
+	LanguagePercentages map[string]float64 `redis:"language_percentages" json:"language_percentages,omitempty"`
+	Quality             Quality            `redis:"quality" json:"quality"`
+
+	// Fields derived for indexing. They are not converted back to the entities layer.
+	AllLanguages   []string  `redis:"all_languages" json:"all_languages"`
+	LicenseFamily  string    `redis:"license_family" json:"license_family"`
+	CreatedAtUnix  int64     `redis:"created_at_unix" json:"created_at_unix"`
+	UpdatedAtUnix  int64     `redis:"updated_at_unix" json:"updated_at_unix"`
+	LanguageVector []float32 `redis:"language_vector" json:"language_vector,omitempty"`
+}
+
+// Quality is the quality score of a repo document and its breakdown by factor
+// The score is indexed (as quality) to filter and sort the documents by it.
+type Quality struct {
+	Score   int             `json:"score"`
+	Factors []QualityFactor `json:"factors"`
+}
+
+// QualityFactor is the contribution of a factor to the quality score of a repo document
+type QualityFactor struct {
+	Name   string  `json:"name"`
+	Weight float64 `json:"weight"`
+	Value  float64 `json:"value"`
+	Points float64 `json:"points"`
 }
 
 func getRepoKey(id int64) repoKey {
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TimeInterval is the width of the buckets of a time histogram
+type TimeInterval string
+
+const (
+	IntervalHour  TimeInterval = "hour"
+	IntervalDay   TimeInterval = "day"
+	IntervalMonth TimeInterval = "month"
+)
+
+// TimeIntervals lists the valid TimeInterval values
+var TimeIntervals = []TimeInterval{IntervalHour, IntervalDay, IntervalMonth}
+
+// MaxHistogramBuckets is the maximum number of buckets of a histogram
+const MaxHistogramBuckets = 50
+
+// MaxTimeBuckets is the maximum number of buckets of a time histogram
+const MaxTimeBuckets = 10000
+
+// DistributionSpec describes the distributions to compute over the repositories matching the filters
+// (the pagination, sorting, facets and fields of the filters are ignored)
+//   - Fields: the numeric fields to compute the distributions of, by primary language (see AggregateMetricFields)
+//   - Buckets: the ascending lower bounds of the histogram buckets. Bucket i holds the values in
+//     [Buckets[i], Buckets[i+1]), and the last bucket is unbounded. No buckets computes no histogram.
+//   - CreatedInterval: the interval of the created_at histogram (empty for no histogram)
+type DistributionSpec struct {
+	Filters         GetRepoListFilters
+	Fields          []string
+	Buckets         []int
+	CreatedInterval TimeInterval
+}
+
+// Validate checks the fields, buckets and interval of the spec
+// It returns an error wrapping ErrInvalidFilter if the spec cannot be computed
+func (s DistributionSpec) Validate() error {
+	for _, field := range s.Fields {
+		if !contains(AggregateMetricFields, field) {
+			return errors.Wrapf(ErrInvalidFilter, "unknown distribution field %q", field)
+		}
+	}
+	if len(s.Buckets) > MaxHistogramBuckets {
+		return errors.Wrapf(ErrInvalidFilter, "at most %d buckets, got %d", MaxHistogramBuckets, len(s.Buckets))
+	}
+	for i := 1; i < len(s.Buckets); i++ {
+		if s.Buckets[i] <= s.Buckets[i-1] {
+			return errors.Wrapf(ErrInvalidFilter, "the buckets must be ascending, got %v", s.Buckets)
+		}
+	}
+	if s.CreatedInterval != "" && !contains(TimeIntervals, s.CreatedInterval) {
+		return errors.Wrapf(ErrInvalidFilter, "unknown time interval %q", s.CreatedInterval)
+	}
+	return nil
+}
+
+// Quantile returns the q-quantile (0 to 1) of the ascending values, using the nearest rank
+func Quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// TruncateTime returns the start (UTC) of the interval holding the time
+func TruncateTime(t time.Time, interval TimeInterval) time.Time {
+	t = t.UTC()
+	switch interval {
+	case IntervalHour:
+		return t.Truncate(time.Hour)
+	case IntervalDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
@@ -1,11 +1,112 @@
 package db
 
+import (
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/query"
+)
+
 // GetRepoListFilters is a struct to hold the parameters for the GetRepoList db method
 // use pointer values to allow null values
 type GetRepoListFilters struct {
-	Name          *string
-	Language      *string
-	License       *string
-	AllowForking  *bool
-	HasOpenIssues *bool
+	Name             *string
+	Language         *string // the exact primary language or a language of the breakdown (case-insensitive)
+	DominantLanguage *string // the exact language of the breakdown with the most bytes (case-insensitive)
+	License          *string // a substring of the license name (case-insensitive)
+	LicenseKey       *string // the exact SPDX key of the license (case-insensitive)
+	LicenseFamily    *string // the family of the license (see licenses.Families)
+	Owner            *string // the exact owner login (case-insensitive)
+	AllowForking     *bool
+	HasOpenIssues    *bool
+
+	// Ranges
+	Size            IntRange
+	ForksCount      IntRange
+	WatchersCount   IntRange
+	OpenIssuesCount IntRange
+	LanguageCount   IntRange
+	Quality         IntRange // the quality score (see quality.Compute)
+	CreatedAt       TimeRange
+	UpdatedAt       TimeRange
+
+	// Query is a parsed search query (nil matches everything)
+	Query query.Expr
+
+	// Text is a full-text search over the name and description (with stemming, see the fulltext package)
+	// Every term must match. The relevance score of each item is returned in RepoPage.Hits.
+	Text *string
+
+	// Pagination
+	//  - Offset: the number of matching items to skip
+	//  - Limit: the maximum number of items to return (0 returns all the matching items)
+	Offset int
+	Limit  int
+
+	// Sorting
+	//  - SortBy: the field to sort by (defaults to SortByCreatedAt)
+	//  - SortAscending: sort in ascending order (the default is descending)
+	//    The items with equal values are always sorted by ascending ID, so that the pages over them are stable.
+	SortBy        SortField
+	SortAscending bool
+
+	// Facets lists the fields to count the values of, over all the matching items (see RepoPage.Facets)
+	Facets []FacetField
+
+	// Fields lists the json keys of the repo items needed by the caller (nil for all fields)
+	// Backends may return more fields than requested, but must return at least these
+	Fields []string
+}
+
+// SortField is a field the repo list can be sorted by
+type SortField string
+
+const (
+	SortByCreatedAt  SortField = "created_at"
+	SortByForks      SortField = "forks"
+	SortByWatchers   SortField = "watchers"
+	SortBySize       SortField = "size"
+	SortByOpenIssues SortField = "open_issues"
+	SortByQuality    SortField = "quality"
+
+	// SortByRelevance sorts by the score of the full-text search (most relevant first). It requires a Text filter.
+	SortByRelevance SortField = "relevance"
+)
+
+// SortFields lists the SortField values of the item fields (SortByRelevance is not an item field)
+var SortFields = []SortField{
+	SortByCreatedAt, SortByForks, SortByWatchers, SortBySize, SortByOpenIssues, SortByQuality,
+}
+
+// TextWeights is the weight of each text field in the relevance score of the full-text search
+var TextWeights = map[string]float64{
+	"name":        3,
+	"description": 1,
+}
+
+// FacetField is a field the matching repo items can be counted by
+// Items with an empty value are not counted
+type FacetField string
+
+const (
+	FacetByLanguage FacetField = "language" // the primary language
+	FacetByLicense  FacetField = "license"  // the license name
+	FacetByOwner    FacetField = "owner"    // the owner login
+)
+
+// FacetFields lists the valid FacetField values
+var FacetFields = []FacetField{FacetByLanguage, FacetByLicense, FacetByOwner}
+
+// MaxFacetValues is the maximum number of values counted for each facet (the most frequent values are kept)
+const MaxFacetValues = 1000
+
+// IntRange is an inclusive range filter on an integer field. A nil bound is unbounded.
+type IntRange struct {
+	Min *int
+	Max *int
+}
+
+// TimeRange is an exclusive range filter on a time field. A nil bound is unbounded.
+type TimeRange struct {
+	After  *time.Time
+	Before *time.Time
 }
@@ -0,0 +1,134 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/pkg/errors"
+)
+
+// HistoryMetric is a metric of the stats recorded in the history, by primary language
+type HistoryMetric string
+
+const (
+	HistoryNumRepos      HistoryMetric = "num_repos"       // Stats.NumReposByLanguage
+	HistoryAvgForks      HistoryMetric = "avg_forks"       // Stats.AvgNumForksPerRepoByLanguage
+	HistoryAvgOpenIssues HistoryMetric = "avg_open_issues" // Stats.AvgNumOpenIssuesByLanguage
+	HistoryAvgSize       HistoryMetric = "avg_size"        // Stats.AvgSizeByLanguage
+)
+
+// HistoryMetrics lists the valid HistoryMetric values
+var HistoryMetrics = []HistoryMetric{HistoryNumRepos, HistoryAvgForks, HistoryAvgOpenIssues, HistoryAvgSize}
+
+// HistoryRetention is how long the stats snapshots are kept
+const HistoryRetention = 7 * 24 * time.Hour
+
+// MaxHistoryPoints is the maximum number of points of a downsampled series
+const MaxHistoryPoints = 10000
+
+// HistoryQuery selects a series of the stats history
+//   - Metric, Language: the series
+//   - From, To: the time range (inclusive)
+//   - Step: the width of the downsampling buckets (0 returns the recorded points)
+type HistoryQuery struct {
+	Metric   HistoryMetric
+	Language string
+	From     time.Time
+	To       time.Time
+	Step     time.Duration
+}
+
+// Validate checks the query
+// It returns an error wrapping ErrInvalidFilter if the series cannot be queried
+func (q HistoryQuery) Validate() error {
+	if !contains(HistoryMetrics, q.Metric) {
+		return errors.Wrapf(ErrInvalidFilter, "unknown history metric %q", q.Metric)
+	}
+	if q.To.Before(q.From) {
+		return errors.Wrapf(ErrInvalidFilter, "the range ends (%s) before it starts (%s)", q.To, q.From)
+	}
+	if q.Step < 0 {
+		return errors.Wrapf(ErrInvalidFilter, "the step must be positive, got %s", q.Step)
+	}
+	if q.Step > 0 && q.To.Sub(q.From)/q.Step >= MaxHistoryPoints {
+		return errors.Wrapf(ErrInvalidFilter, "the step %s gives more than %d points", q.Step, MaxHistoryPoints)
+	}
+	return nil
+}
+
+// CollectStats computes the stats by language of the repositories matching the filters
+func CollectStats(ctx context.Context, s Service, filters GetRepoListFilters) (entities.Stats, error) {
+	var err error
+	out := entities.Stats{}
+
+	if out.AvgNumForksPerRepoByLanguage, err = s.GetAvgNumForksPerRepoByLanguage(ctx, filters); err != nil {
+		return entities.Stats{}, err
+	}
+
+	if out.NumReposByLanguage, err = s.GetNumReposByLanguage(ctx, filters); err != nil {
+		return entities.Stats{}, err
+	}
+
+	if out.AvgNumOpenIssuesByLanguage, err = s.GetAvgNumOpenIssuesByLanguage(ctx, filters); err != nil {
+		return entities.Stats{}, err
+	}
+
+	if out.AvgSizeByLanguage, err = s.GetAvgSizeByLanguage(ctx, filters); err != nil {
+		return entities.Stats{}, err
+	}
+
+	return out, nil
+}
+
+// StatsValues returns the value of each history metric by language for a stats snapshot
+func StatsValues(stats entities.Stats) map[HistoryMetric]map[string]float64 {
+	out := map[HistoryMetric]map[string]float64{
+		HistoryNumRepos:      {},
+		HistoryAvgForks:      {},
+		HistoryAvgOpenIssues: {},
+		HistoryAvgSize:       {},
+	}
+	for language, v := range stats.NumReposByLanguage {
+		out[HistoryNumRepos][language] = float64(v)
+	}
+	for language, v := range stats.AvgNumForksPerRepoByLanguage {
+		out[HistoryAvgForks][language] = float64(v)
+	}
+	for language, v := range stats.AvgNumOpenIssuesByLanguage {
+		out[HistoryAvgOpenIssues][language] = float64(v)
+	}
+	for language, v := range stats.AvgSizeByLanguage {
+		out[HistoryAvgSize][language] = float64(v)
+	}
+	return out
+}
+
+// Downsample averages the ascending points in buckets of width step starting at from
+// Each point of the result is at the start of its bucket. Buckets without points are omitted.
+func Downsample(points []entities.HistoryPoint, from time.Time, step time.Duration) []entities.HistoryPoint {
+	if step <= 0 {
+		return points
+	}
+
+	out := []entities.HistoryPoint{}
+	var sum float64
+	var count int
+	flush := func() {
+		if count > 0 {
+			out[len(out)-1].Value = sum / float64(count)
+		}
+	}
+	for _, p := range points {
+		start := from.Add(p.Time.Sub(from) / step * step)
+		if len(out) == 0 || !out[len(out)-1].Time.Equal(start) {
+			flush()
+			out = append(out, entities.HistoryPoint{Time: start})
+			sum, count = 0, 0
+		}
+		sum += p.Value
+		count++
+	}
+	flush()
+	return out
+}
@@ -2,21 +2,82 @@ package db
 
 import (
 	"context"
+	"time"
 
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
 )
 
 var ErrNotFound = DBError("not found")
 
+// ErrInvalidFilter is returned when a filter value can never be matched by the backend
+var ErrInvalidFilter = DBError("invalid filter")
+
 type Service interface {
 	SetRepoList(ctx context.Context, list entities.RepoList) error
 	SetRepoItemLanguages(ctx context.Context, repoID int64, langs entities.Languages) error
 
-	GetRepoList(ctx context.Context, filters GetRepoListFilters) (entities.RepoList, error)
+	GetRepoList(ctx context.Context, filters GetRepoListFilters) (entities.RepoPage, error)
 	GetRepoItem(ctx context.Context, repoID int64) (entities.RepoItem, error)
 
-	GetAvgNumForksPerRepoByLanguage(ctx context.Context) (map[string]float32, error)
-	GetAvgNumOpenIssuesByLanguage(ctx context.Context) (map[string]float32, error)
-	GetAvgSizeByLanguage(ctx context.Context) (map[string]float32, error)
-	GetNumReposByLanguage(ctx context.Context) (map[string]int, error)
+	// GetSuggestions returns the values of the field starting with the prefix (case-insensitive),
+	// ranked by the number of repositories with the value
+	GetSuggestions(ctx context.Context, field SuggestField, prefix string, limit int) ([]entities.Suggestion, error)
+
+	// The aggregates by primary language are computed over the repositories matching the filters
+	// (the pagination, sorting, facets and fields of the filters are ignored)
+	GetAvgNumForksPerRepoByLanguage(ctx context.Context, filters GetRepoListFilters) (map[string]float32, error)
+	GetAvgNumOpenIssuesByLanguage(ctx context.Context, filters GetRepoListFilters) (map[string]float32, error)
+	GetAvgSizeByLanguage(ctx context.Context, filters GetRepoListFilters) (map[string]float32, error)
+	GetNumReposByLanguage(ctx context.Context, filters GetRepoListFilters) (map[string]int, error)
+
+	// GetLanguageStats computes the statistics of the languages breakdowns of the repositories matching the filters
+	// (the pagination, sorting, facets and fields of the filters are ignored)
+	GetLanguageStats(ctx context.Context, filters GetRepoListFilters) (entities.LanguageStats, error)
+
+	// GetLanguageCooccurrence counts the repositories matching the filters using each pair of languages together
+	// A language counts when it has at least minBytes bytes in the languages breakdown of a repository
+	GetLanguageCooccurrence(ctx context.Context, filters GetRepoListFilters, minBytes int64) (
+		entities.LanguageCooccurrence, error,
+	)
+
+	// GetDistributions computes the distributions of the spec over the repositories matching its filters
+	// It returns an error wrapping ErrInvalidFilter if the spec is not valid
+	GetDistributions(ctx context.Context, spec DistributionSpec) (entities.Distributions, error)
+
+	// GetLanguages returns the summary of each language of the items matching the filters
+	// (see ComputeLanguageSummaries)
+	GetLanguages(ctx context.Context, filters GetRepoListFilters) ([]entities.LanguageSummary, error)
+
+	// GetLanguage returns the summary of a language (case-insensitive) and the top items using it
+	// (see ComputeLanguageDetail). It returns ErrNotFound if no item uses the language
+	GetLanguage(ctx context.Context, name string, top int) (entities.LanguageDetail, error)
+
+	// GetLicenseStats counts the items matching the filters by license and license family
+	// (see ComputeLicenseStats)
+	GetLicenseStats(ctx context.Context, filters GetRepoListFilters) (entities.LicenseStats, error)
+
+	// GetOwners returns the summary of each owner of the items matching the filters (see ComputeOwners)
+	GetOwners(ctx context.Context, filters GetRepoListFilters) ([]entities.OwnerSummary, error)
+
+	// GetLeaderboard returns the items ranked by the metric of the query, by descending value
+	// It returns an error wrapping ErrInvalidFilter if the query is not valid
+	GetLeaderboard(ctx context.Context, query LeaderboardQuery) ([]entities.LeaderboardEntry, error)
+
+	// GetSimilarRepos returns the items most similar to the repository of the query (see RankSimilar), using the
+	// vector index of the languages breakdowns maintained by SetRepoItemLanguages to find the candidates
+	// It returns ErrNotFound if the repository does not exist, and an error wrapping ErrInvalidFilter if the query is
+	// not valid
+	GetSimilarRepos(ctx context.Context, query SimilarQuery) ([]entities.SimilarRepo, error)
+
+	// RecordStatsSnapshot records the stats by language at a time in the stats history
+	// The snapshots older than HistoryRetention are dropped
+	RecordStatsSnapshot(ctx context.Context, at time.Time, stats entities.Stats) error
+
+	// GetStatsHistory returns the series of the stats history selected by the query, in ascending time order
+	// It returns an error wrapping ErrInvalidFilter if the query is not valid
+	GetStatsHistory(ctx context.Context, query HistoryQuery) ([]entities.HistoryPoint, error)
+
+	// Aggregate groups the repositories matching the filters of the spec and computes the metrics of each group
+	// The groups are ordered by value. It returns an error wrapping ErrInvalidFilter if the spec is not valid.
+	Aggregate(ctx context.Context, spec AggregateSpec) ([]entities.AggregateGroup, error)
 }
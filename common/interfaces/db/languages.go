@@ -0,0 +1,280 @@
+package db
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+)
+
+// WithLanguages returns the item with the languages breakdown and the fields derived from it: the number of languages,
+// the dominant language and the percentage of each language. An item without primary language (GitHub reports none
+// for some repositories) takes the dominant language as primary language (see withDerivedLanguage).
+func WithLanguages(item entities.RepoItem, langs entities.Languages) entities.RepoItem {
+	item.Languages = langs
+	item.LanguageCount = len(langs)
+	item.DominantLanguage = langs.Dominant()
+	item.LanguagePercentages = langs.Percentages()
+	return withDerivedLanguage(item)
+}
+
+// PreserveLanguages returns the new version of an item with the languages breakdown and the derived fields of the
+// existing version, when it has one. The repository list does not include the breakdowns, so they would be lost on
+// every update of the list otherwise.
+func PreserveLanguages(item, existing entities.RepoItem) entities.RepoItem {
+	if existing.Languages == nil {
+		return item
+	}
+	item.Languages = existing.Languages
+	item.LanguageCount = existing.LanguageCount
+	item.DominantLanguage = existing.DominantLanguage
+	item.LanguagePercentages = existing.LanguagePercentages
+	return withDerivedLanguage(item)
+}
+
+// withDerivedLanguage returns the item with its dominant language as primary language when GitHub reports none
+// The primary language is then flagged as derived, so that it follows the dominant language each time the breakdown
+// changes, and is told apart from the language reported by GitHub.
+func withDerivedLanguage(item entities.RepoItem) entities.RepoItem {
+	if item.Language == "" || item.LanguageDerived {
+		item.Language = item.DominantLanguage
+		item.LanguageDerived = item.Language != ""
+	}
+	return item
+}
+
+// ItemGroup is a group of items with the same owner, primary language and languages breakdown, as grouped by the
+// backends to compute the statistics of the breakdowns and the owners: Count is the number of items of the group and
+// Size their total size
+type ItemGroup struct {
+	Owner     string
+	Language  string
+	Languages entities.Languages
+	Count     int
+	Size      int
+}
+
+// GroupItems returns a group of one item for each item
+func GroupItems(list entities.RepoList) []ItemGroup {
+	out := make([]ItemGroup, len(list))
+	for i, item := range list {
+		out[i] = ItemGroup{
+			Owner: item.Owner, Language: item.Language, Languages: item.Languages, Count: 1, Size: item.Size,
+		}
+	}
+	return out
+}
+
+// ComputeLanguageStats computes the statistics of the languages breakdowns of the groups of items
+// Items without a languages breakdown (not fetched yet, or without code) are ignored.
+func ComputeLanguageStats(groups []ItemGroup) entities.LanguageStats {
+	out := entities.LanguageStats{}
+	stats := map[string]*entities.LanguageStat{}
+	shares := map[string]float64{}
+
+	for _, group := range groups {
+		var repoBytes int64
+		for _, bytes := range group.Languages {
+			repoBytes += bytes
+		}
+		if repoBytes == 0 {
+			continue
+		}
+		out.Repos += group.Count
+		out.TotalBytes += repoBytes * int64(group.Count)
+
+		for language, bytes := range group.Languages {
+			stat, ok := stats[language]
+			if !ok {
+				stat = &entities.LanguageStat{Language: language}
+				stats[language] = stat
+			}
+			stat.Bytes += bytes * int64(group.Count)
+			stat.Repos += group.Count
+			shares[language] += float64(group.Count) * float64(bytes) / float64(repoBytes)
+		}
+	}
+
+	out.Languages = make([]entities.LanguageStat, 0, len(stats))
+	for language, stat := range stats {
+		stat.Share = float64(stat.Bytes) / float64(out.TotalBytes)
+		stat.MeanShare = shares[language] / float64(stat.Repos)
+		out.Languages = append(out.Languages, *stat)
+	}
+
+	sort.Slice(
+		out.Languages, func(i, j int) bool {
+			if out.Languages[i].Bytes != out.Languages[j].Bytes {
+				return out.Languages[i].Bytes > out.Languages[j].Bytes
+			}
+			return out.Languages[i].Language < out.Languages[j].Language
+		},
+	)
+	return out
+}
+
+// ComputeLanguageCooccurrence counts the items of the groups using each pair of languages together
+// A language is used by an item when it has at least minBytes bytes in its languages breakdown, so that incidental
+// files are not counted.
+func ComputeLanguageCooccurrence(groups []ItemGroup, minBytes int64) entities.LanguageCooccurrence {
+	out := entities.LanguageCooccurrence{}
+	repos := map[string]int{}
+	pairs := map[[2]string]int{}
+
+	for _, group := range groups {
+		var languages []string
+		for language, bytes := range group.Languages {
+			if bytes > 0 && bytes >= minBytes {
+				languages = append(languages, language)
+			}
+		}
+		if len(languages) == 0 {
+			continue
+		}
+		out.Repos += group.Count
+
+		sort.Strings(languages)
+		for i, a := range languages {
+			repos[a] += group.Count
+			for _, b := range languages[i+1:] {
+				pairs[[2]string{a, b}] += group.Count
+			}
+		}
+	}
+
+	out.Pairs = make([]entities.LanguagePair, 0, len(pairs))
+	for pair, count := range pairs {
+		reposA, reposB := repos[pair[0]], repos[pair[1]]
+		out.Pairs = append(
+			out.Pairs, entities.LanguagePair{
+				A:       pair[0],
+				B:       pair[1],
+				Count:   count,
+				Lift:    float64(count) * float64(out.Repos) / (float64(reposA) * float64(reposB)),
+				Jaccard: float64(count) / float64(reposA+reposB-count),
+			},
+		)
+	}
+
+	sort.Slice(
+		out.Pairs, func(i, j int) bool {
+			pi, pj := out.Pairs[i], out.Pairs[j]
+			if pi.Count != pj.Count {
+				return pi.Count > pj.Count
+			}
+			if pi.A != pj.A {
+				return pi.A < pj.A
+			}
+			return pi.B < pj.B
+		},
+	)
+	return out
+}
+
+// LanguageDetailFields are the fields of the items needed to display the top repositories of a language
+var LanguageDetailFields = []string{
+	"id", "name", "full_name", "owner", "html_url", "language", "languages", "size", "watchers_count",
+}
+
+// ComputeLanguageSummaries computes the summary of each language of the groups of items (primary or in the
+// breakdown), by descending number of repositories (ties by descending bytes, then name)
+func ComputeLanguageSummaries(groups []ItemGroup) []entities.LanguageSummary {
+	summaries := map[string]*entities.LanguageSummary{}
+	sizes := map[string]int{}
+
+	for _, group := range groups {
+		for _, language := range groupLanguages(group) {
+			summary, ok := summaries[language]
+			if !ok {
+				summary = &entities.LanguageSummary{Name: language}
+				summaries[language] = summary
+			}
+			summary.Repos += group.Count
+			if language == group.Language {
+				summary.PrimaryRepos += group.Count
+			}
+			summary.Bytes += group.Languages[language] * int64(group.Count)
+			sizes[language] += group.Size
+		}
+	}
+
+	out := make([]entities.LanguageSummary, 0, len(summaries))
+	for language, summary := range summaries {
+		summary.AvgSize = float64(sizes[language]) / float64(summary.Repos)
+		out = append(out, *summary)
+	}
+
+	sort.Slice(
+		out, func(i, j int) bool {
+			if out[i].Repos != out[j].Repos {
+				return out[i].Repos > out[j].Repos
+			}
+			if out[i].Bytes != out[j].Bytes {
+				return out[i].Bytes > out[j].Bytes
+			}
+			return out[i].Name < out[j].Name
+		},
+	)
+	return out
+}
+
+// FindLanguageSummary returns the summary of a language of the groups of items (see ComputeLanguageSummaries)
+// The name is matched case-insensitively, and the summary has the spelling of the data. It returns false when no item
+// uses the language.
+func FindLanguageSummary(groups []ItemGroup, name string) (entities.LanguageSummary, bool) {
+	for _, summary := range ComputeLanguageSummaries(groups) {
+		if strings.EqualFold(summary.Name, name) {
+			return summary, true
+		}
+	}
+	return entities.LanguageSummary{}, false
+}
+
+// ComputeLanguageDetail computes the detail of a language from the items using it (primary or in the breakdown)
+// The summary is found with FindLanguageSummary. The top repositories are the first top items by descending watchers
+// (ties by descending ID). It returns false when no item uses the language.
+func ComputeLanguageDetail(list entities.RepoList, name string, top int) (entities.LanguageDetail, bool) {
+	groups := GroupItems(list)
+	summary, ok := FindLanguageSummary(groups, name)
+	if !ok {
+		return entities.LanguageDetail{}, false
+	}
+
+	var using entities.RepoList
+	for i, group := range groups {
+		for _, language := range groupLanguages(group) {
+			if language == summary.Name {
+				using = append(using, list[i])
+				break
+			}
+		}
+	}
+
+	sort.Slice(
+		using, func(i, j int) bool {
+			if using[i].WatchersCount != using[j].WatchersCount {
+				return using[i].WatchersCount > using[j].WatchersCount
+			}
+			return using[i].ID > using[j].ID
+		},
+	)
+	if len(using) > top {
+		using = using[:top]
+	}
+	return entities.LanguageDetail{Summary: summary, TopRepos: using}, true
+}
+
+// groupLanguages returns the distinct, non-empty languages of a group of items: the primary language and the
+// breakdown
+func groupLanguages(group ItemGroup) []string {
+	out := make([]string, 0, len(group.Languages)+1)
+	if group.Language != "" {
+		out = append(out, group.Language)
+	}
+	for language := range group.Languages {
+		if language != "" && language != group.Language {
+			out = append(out, language)
+		}
+	}
+	return out
+}
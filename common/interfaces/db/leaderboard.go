@@ -0,0 +1,87 @@
+package db
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/pkg/errors"
+)
+
+// LeaderboardMetric is a metric the repositories can be ranked by
+type LeaderboardMetric string
+
+const (
+	LeaderboardForks         LeaderboardMetric = "forks"          // the number of forks
+	LeaderboardWatchers      LeaderboardMetric = "watchers"       // the number of watchers
+	LeaderboardSize          LeaderboardMetric = "size"           // the size in KB
+	LeaderboardOpenIssues    LeaderboardMetric = "open_issues"    // the number of open issues
+	LeaderboardLanguageCount LeaderboardMetric = "language_count" // the number of languages in the breakdown
+)
+
+// LeaderboardMetrics lists the valid LeaderboardMetric values
+var LeaderboardMetrics = []LeaderboardMetric{
+	LeaderboardForks, LeaderboardWatchers, LeaderboardSize, LeaderboardOpenIssues, LeaderboardLanguageCount,
+}
+
+// LeaderboardFields are the fields of the items of a leaderboard (the fields needed to display them)
+var LeaderboardFields = []string{"id", "name", "full_name", "owner", "html_url", "language"}
+
+// MaxLeaderboardLimit is the maximum number of entries of a leaderboard
+const MaxLeaderboardLimit = 100
+
+// LeaderboardQuery selects a leaderboard
+//   - Metric: the metric the repositories are ranked by
+//   - Language: only rank the repositories with this primary language (case-insensitive, nil for all)
+//   - Limit: the number of entries (1 to MaxLeaderboardLimit)
+type LeaderboardQuery struct {
+	Metric   LeaderboardMetric
+	Language *string
+	Limit    int
+}
+
+// Validate checks the query
+// It returns an error wrapping ErrInvalidFilter if the leaderboard cannot be read
+func (q LeaderboardQuery) Validate() error {
+	if !contains(LeaderboardMetrics, q.Metric) {
+		return errors.Wrapf(ErrInvalidFilter, "unknown leaderboard metric %q", q.Metric)
+	}
+	if q.Limit < 1 || q.Limit > MaxLeaderboardLimit {
+		return errors.Wrapf(ErrInvalidFilter, "the limit must be between 1 and %d, got %d", MaxLeaderboardLimit, q.Limit)
+	}
+	return nil
+}
+
+// LeaderboardValue returns the value of the metric for a repo item
+func LeaderboardValue(item entities.RepoItem, metric LeaderboardMetric) int {
+	switch metric {
+	case LeaderboardForks:
+		return item.ForksCount
+	case LeaderboardWatchers:
+		return item.WatchersCount
+	case LeaderboardSize:
+		return item.Size
+	case LeaderboardOpenIssues:
+		return item.OpenIssuesCount
+	case LeaderboardLanguageCount:
+		return len(item.Languages)
+	}
+	return 0
+}
+
+// LeaderboardLanguage returns the language key of the per-language leaderboards of a primary language
+func LeaderboardLanguage(language string) string {
+	return strings.ToLower(language)
+}
+
+// SortLeaderboard orders the entries by descending value, ties by descending ID (the newest repository first)
+func SortLeaderboard(entries []entities.LeaderboardEntry) {
+	sort.Slice(
+		entries, func(i, j int) bool {
+			if entries[i].Value != entries[j].Value {
+				return entries[i].Value > entries[j].Value
+			}
+			return entries[i].Item.ID > entries[j].Item.ID
+		},
+	)
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/licenses"
+)
+
+// ComputeLicenseStats counts the items by license, by descending count (ties by key), and by family (in
+// licenses.Families order, with zero counts)
+// The name of a license is the first non-empty name found for its key.
+func ComputeLicenseStats(list entities.RepoList) entities.LicenseStats {
+	var counts []entities.LicenseCount
+	byKey := map[string]int{}
+	for _, item := range list {
+		i, ok := byKey[item.LicenseKey]
+		if !ok {
+			i = len(counts)
+			byKey[item.LicenseKey] = i
+			counts = append(counts, entities.LicenseCount{Key: item.LicenseKey})
+		}
+		if counts[i].Name == "" {
+			counts[i].Name = item.LicenseName
+		}
+		counts[i].Count++
+	}
+	return LicenseStatsOf(counts)
+}
+
+// LicenseStatsOf computes the license stats from the counts of items by license key (see ComputeLicenseStats)
+// The keys without a family (no license, or an unknown one) are only counted in the total and the none family.
+func LicenseStatsOf(counts []entities.LicenseCount) entities.LicenseStats {
+	byFamily := map[licenses.Family]int{}
+	out := entities.LicenseStats{
+		Licenses: make([]entities.LicenseCount, 0, len(counts)),
+		Families: make([]entities.FamilyCount, 0, len(licenses.Families)),
+	}
+	for _, count := range counts {
+		family := licenses.FamilyOf(count.Key)
+		byFamily[family] += count.Count
+		out.Total += count.Count
+		if family == licenses.None {
+			continue
+		}
+		count.Family = string(family)
+		out.Licenses = append(out.Licenses, count)
+	}
+
+	sort.Slice(
+		out.Licenses, func(i, j int) bool {
+			if out.Licenses[i].Count != out.Licenses[j].Count {
+				return out.Licenses[i].Count > out.Licenses[j].Count
+			}
+			return out.Licenses[i].Key < out.Licenses[j].Key
+		},
+	)
+	for _, family := range licenses.Families {
+		out.Families = append(out.Families, entities.FamilyCount{Family: string(family), Count: byFamily[family]})
+	}
+	return out
+}
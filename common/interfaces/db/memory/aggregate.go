@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+)
+
+// groupValues extracts the value of each group-by field from a repo item
+var groupValues = map[db.AggregateGroupField]func(item entities.RepoItem) string{
+	db.GroupByLanguage:       func(item entities.RepoItem) string { return item.Language },
+	db.GroupByLicense:        func(item entities.RepoItem) string { return item.LicenseName },
+	db.GroupByOwner:          func(item entities.RepoItem) string { return item.Owner },
+	db.GroupByAllowForking:   func(item entities.RepoItem) string { return strconv.FormatBool(item.AllowForking) },
+	db.GroupByHasIssues:      func(item entities.RepoItem) string { return strconv.FormatBool(item.HasIssues) },
+	db.GroupByHasProjects:    func(item entities.RepoItem) string { return strconv.FormatBool(item.HasProjects) },
+	db.GroupByHasDownloads:   func(item entities.RepoItem) string { return strconv.FormatBool(item.HasDownloads) },
+	db.GroupByHasWiki:        func(item entities.RepoItem) string { return strconv.FormatBool(item.HasWiki) },
+	db.GroupByHasPages:       func(item entities.RepoItem) string { return strconv.FormatBool(item.HasPages) },
+	db.GroupByHasDiscussions: func(item entities.RepoItem) string { return strconv.FormatBool(item.HasDiscussions) },
+}
+
+// metricValues extracts the value of each metric field from a repo item
+var metricValues = map[string]func(item entities.RepoItem) float64{
+	"size":              func(item entities.RepoItem) float64 { return float64(item.Size) },
+	"forks_count":       func(item entities.RepoItem) float64 { return float64(item.ForksCount) },
+	"watchers_count":    func(item entities.RepoItem) float64 { return float64(item.WatchersCount) },
+	"open_issues_count": func(item entities.RepoItem) float64 { return float64(item.OpenIssuesCount) },
+}
+
+// Aggregate groups the repositories matching the filters of the spec and computes the metrics of each group
+// Like the redis implementation, items without a group-by value are not grouped
+func (c *DBServiceMemory) Aggregate(ctx context.Context, spec db.AggregateSpec) ([]entities.AggregateGroup, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	list, _ := c.findRepoItems(spec.Filters)
+
+	// Group the items
+	valueOf := groupValues[spec.GroupBy]
+	groups := map[string]entities.RepoList{}
+	for _, item := range list {
+		if value := valueOf(item); value != "" {
+			groups[value] = append(groups[value], item)
+		}
+	}
+
+	out := make([]entities.AggregateGroup, 0, len(groups))
+	for value, items := range groups {
+		group := entities.AggregateGroup{Value: value, Metrics: make(map[string]float64, len(spec.Metrics))}
+		for _, m := range spec.Metrics {
+			group.Metrics[m.Name()] = computeMetric(items, m)
+		}
+		out = append(out, group)
+	}
+
+	return db.SortAggregateGroups(out), nil
+}
+
+// computeMetric computes the metric over the (non-empty) group of items
+func computeMetric(items entities.RepoList, m db.AggregateMetric) float64 {
+	if m.Func == db.AggregateCount {
+		return float64(len(items))
+	}
+
+	valueOf := metricValues[m.Field]
+	sum, min, max := 0.0, math.Inf(1), math.Inf(-1)
+	for _, item := range items {
+		v := valueOf(item)
+		sum += v
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	switch m.Func {
+	case db.AggregateSum:
+		return sum
+	case db.AggregateAvg:
+		return sum / float64(len(items))
+	case db.AggregateMin:
+		return min
+	default:
+		return max
+	}
+}
@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+)
+
+// GetDistributions computes the distributions of the spec over the repositories matching its filters
+// Like the redis implementation, items without a primary language are not grouped by language
+func (c *DBServiceMemory) GetDistributions(ctx context.Context, spec db.DistributionSpec) (
+	entities.Distributions, error,
+) {
+	if err := spec.Validate(); err != nil {
+		return entities.Distributions{}, err
+	}
+
+	list, _ := c.findRepoItems(spec.Filters)
+
+	// Group the items by language
+	byLanguage := map[string]entities.RepoList{}
+	for _, item := range list {
+		if item.Language != "" {
+			byLanguage[item.Language] = append(byLanguage[item.Language], item)
+		}
+	}
+
+	out := entities.Distributions{ByLanguage: make(map[string]map[string]entities.Distribution, len(byLanguage))}
+	for language, items := range byLanguage {
+		out.ByLanguage[language] = make(map[string]entities.Distribution, len(spec.Fields))
+		for _, field := range spec.Fields {
+			out.ByLanguage[language][field] = computeDistribution(items, metricValues[field], spec.Buckets)
+		}
+	}
+
+	if spec.CreatedInterval != "" {
+		out.CreatedAt = timeHistogram(list, spec.CreatedInterval)
+	}
+
+	return out, nil
+}
+
+// computeDistribution computes the distribution of the values of the (non-empty) group of items
+func computeDistribution(
+	items entities.RepoList, valueOf func(item entities.RepoItem) float64, buckets []int,
+) entities.Distribution {
+	values := make([]float64, len(items))
+	for i, item := range items {
+		values[i] = valueOf(item)
+	}
+	sort.Float64s(values)
+
+	out := entities.Distribution{
+		Count:  len(values),
+		Min:    values[0],
+		Max:    values[len(values)-1],
+		Median: db.Quantile(values, 0.5),
+		P90:    db.Quantile(values, 0.9),
+		P99:    db.Quantile(values, 0.99),
+	}
+
+	if len(buckets) > 0 {
+		out.Histogram = make([]entities.HistogramBucket, len(buckets))
+		for i := range buckets {
+			out.Histogram[i] = entities.HistogramBucket{Min: buckets[i]}
+			if i+1 < len(buckets) {
+				max := buckets[i+1]
+				out.Histogram[i].Max = &max
+			}
+		}
+		for _, v := range values {
+			// The bucket holding v is the last one starting at or before v (values before the first are not counted)
+			i := sort.Search(len(buckets), func(i int) bool { return float64(buckets[i]) > v }) - 1
+			if i >= 0 {
+				out.Histogram[i].Count++
+			}
+		}
+	}
+
+	return out
+}
+
+// timeHistogram counts the items created in each interval, in ascending order
+// Like the redis implementation, only the db.MaxTimeBuckets first intervals are kept
+func timeHistogram(list entities.RepoList, interval db.TimeInterval) []entities.TimeBucket {
+	counts := map[time.Time]int{}
+	for _, item := range list {
+		counts[db.TruncateTime(item.CreatedAt, interval)]++
+	}
+
+	out := make([]entities.TimeBucket, 0, len(counts))
+	for start, count := range counts {
+		out = append(out, entities.TimeBucket{Start: start, Count: count})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	if len(out) > db.MaxTimeBuckets {
+		out = out[:db.MaxTimeBuckets]
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/pkg/errors"
+)
+
+// facetValues extracts the value of each facet field from a repo item
+var facetValues = map[db.FacetField]func(item entities.RepoItem) string{
+	db.FacetByLanguage: func(item entities.RepoItem) string { return item.Language },
+	db.FacetByLicense:  func(item entities.RepoItem) string { return item.LicenseName },
+	db.FacetByOwner:    func(item entities.RepoItem) string { return item.Owner },
+}
+
+// countFacets counts the values of each facet field over the items
+// Like the redis implementation, only the db.MaxFacetValues most frequent values of each field are kept
+func countFacets(list entities.RepoList, fields []db.FacetField) (entities.Facets, error) {
+	out := entities.Facets{}
+	for _, field := range fields {
+		valueOf, ok := facetValues[field]
+		if !ok {
+			return nil, errors.Errorf("unknown facet field %q", field)
+		}
+
+		counts := entities.FacetCounts{}
+		for _, item := range list {
+			if value := valueOf(item); value != "" {
+				counts[value]++
+			}
+		}
+		out[string(field)] = topFacetCounts(counts, db.MaxFacetValues)
+	}
+	return out, nil
+}
+
+// topFacetCounts keeps the max most frequent values (ties are broken by value)
+func topFacetCounts(counts entities.FacetCounts, max int) entities.FacetCounts {
+	if len(counts) <= max {
+		return counts
+	}
+
+	values := make([]string, 0, len(counts))
+	for value := range counts {
+		values = append(values, value)
+	}
+	sort.Slice(
+		values, func(i, j int) bool {
+			if counts[values[i]] != counts[values[j]] {
+				return counts[values[i]] > counts[values[j]]
+			}
+			return values[i] < values[j]
+		},
+	)
+
+	out := make(entities.FacetCounts, max)
+	for _, value := range values[:max] {
+		out[value] = counts[value]
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/fulltext"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/Scalingo/sclng-backend-test-v1/common/licenses"
+)
+
+// matchesFilters reports whether the item matches all the filters
+// The semantics mirror the search queries of the redis implementation:
+//   - text filters (name, license) match when every word of the value is within a word of the text (see matchesText)
+//   - the language filter matches the exact primary language or any language in the breakdown (case-insensitive)
+//   - the owner and license key filters match the exact value (case-insensitive)
+//   - the license family filter matches the family of the license key
+//   - integer ranges are inclusive, time ranges are exclusive
+func matchesFilters(item entities.RepoItem, filters db.GetRepoListFilters) bool {
+
+	if filters.Name != nil && *filters.Name != "" && !matchesText(item.Name, *filters.Name) {
+		return false
+	}
+
+	if filters.Language != nil && *filters.Language != "" && !matchesLanguage(item, *filters.Language) {
+		return false
+	}
+
+	if filters.DominantLanguage != nil && *filters.DominantLanguage != "" &&
+		!strings.EqualFold(item.DominantLanguage, *filters.DominantLanguage) {
+		return false
+	}
+
+	if filters.License != nil && *filters.License != "" && !matchesText(item.LicenseName, *filters.License) {
+		return false
+	}
+
+	if filters.LicenseKey != nil && *filters.LicenseKey != "" && !strings.EqualFold(item.LicenseKey, *filters.LicenseKey) {
+		return false
+	}
+
+	if filters.LicenseFamily != nil && *filters.LicenseFamily != "" &&
+		string(licenses.FamilyOf(item.LicenseKey)) != *filters.LicenseFamily {
+		return false
+	}
+
+	if filters.Owner != nil && *filters.Owner != "" && !strings.EqualFold(item.Owner, *filters.Owner) {
+		return false
+	}
+
+	if filters.AllowForking != nil && item.AllowForking != *filters.AllowForking {
+		return false
+	}
+
+	if filters.HasOpenIssues != nil && (item.OpenIssuesCount > 0) != *filters.HasOpenIssues {
+		return false
+	}
+
+	return inIntRange(item.Size, filters.Size) &&
+		inIntRange(item.ForksCount, filters.ForksCount) &&
+		inIntRange(item.WatchersCount, filters.WatchersCount) &&
+		inIntRange(item.OpenIssuesCount, filters.OpenIssuesCount) &&
+		inIntRange(item.LanguageCount, filters.LanguageCount) &&
+		inIntRange(item.Quality.Score, filters.Quality) &&
+		inTimeRange(item.CreatedAt, filters.CreatedAt) &&
+		inTimeRange(item.UpdatedAt, filters.UpdatedAt) &&
+		matchesQuery(item, filters.Query)
+}
+
+// matchesLanguage reports whether the primary language or any language in the breakdown is the value
+func matchesLanguage(item entities.RepoItem, value string) bool {
+	if strings.EqualFold(item.Language, value) {
+		return true
+	}
+	for lang := range item.Languages {
+		if strings.EqualFold(lang, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesText reports whether every word of the value is within a word of the text, ignoring case, as the infix
+// terms of the redis TEXT queries. A word of a single character must be a whole word of the text.
+func matchesText(text, value string) bool {
+	words, terms := fulltext.Tokenize(text), fulltext.Tokenize(value)
+	if len(terms) == 0 {
+		return false
+	}
+	for _, term := range terms {
+		if !containsTerm(words, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsTerm reports whether the term is within one of the words (or is one of the words, for a single character)
+func containsTerm(words []string, term string) bool {
+	single := utf8.RuneCountInString(term) < 2
+	for _, word := range words {
+		if word == term || (!single && strings.Contains(word, term)) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPhrase reports whether the words of the value are consecutive words of the text, ignoring case, as the
+// phrases of the redis TEXT queries
+func matchesPhrase(text, value string) bool {
+	words, terms := fulltext.Tokenize(text), fulltext.Tokenize(value)
+	if len(terms) == 0 {
+		return false
+	}
+	for i := 0; i+len(terms) <= len(words); i++ {
+		j := 0
+		for j < len(terms) && words[i+j] == terms[j] {
+			j++
+		}
+		if j == len(terms) {
+			return true
+		}
+	}
+	return false
+}
+
+// inIntRange reports whether the value is within the inclusive range
+func inIntRange(value int, r db.IntRange) bool {
+	if r.Min != nil && value < *r.Min {
+		return false
+	}
+	if r.Max != nil && value > *r.Max {
+		return false
+	}
+	return true
+}
+
+// inTimeRange reports whether the value is within the exclusive range (compared at a resolution of seconds)
+func inTimeRange(value time.Time, r db.TimeRange) bool {
+	if r.After != nil && value.Unix() <= r.After.Unix() {
+		return false
+	}
+	if r.Before != nil && value.Unix() >= r.Before.Unix() {
+		return false
+	}
+	return true
+}
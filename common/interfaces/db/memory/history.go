@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+)
+
+// historyKey identifies a series of the stats history
+type historyKey struct {
+	metric   db.HistoryMetric
+	language string
+}
+
+// RecordStatsSnapshot records the stats by language at a time in the stats history
+func (c *DBServiceMemory) RecordStatsSnapshot(ctx context.Context, at time.Time, stats entities.Stats) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for metric, byLanguage := range db.StatsValues(stats) {
+		for language, value := range byLanguage {
+			key := historyKey{metric: metric, language: language}
+			c.history[key] = append(c.history[key], entities.HistoryPoint{Time: at.UTC(), Value: value})
+		}
+	}
+
+	// Drop the expired snapshots (the points are recorded in time order)
+	expiry := at.Add(-db.HistoryRetention)
+	for key, points := range c.history {
+		i := 0
+		for i < len(points) && points[i].Time.Before(expiry) {
+			i++
+		}
+		if i == len(points) {
+			delete(c.history, key)
+		} else if i > 0 {
+			c.history[key] = append([]entities.HistoryPoint(nil), points[i:]...)
+		}
+	}
+	return nil
+}
+
+// GetStatsHistory returns the series of the stats history selected by the query
+func (c *DBServiceMemory) GetStatsHistory(ctx context.Context, query db.HistoryQuery) ([]entities.HistoryPoint, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	points := []entities.HistoryPoint{}
+	for _, p := range c.history[historyKey{metric: query.Metric, language: query.Language}] {
+		if !p.Time.Before(query.From) && !p.Time.After(query.To) {
+			points = append(points, p)
+		}
+	}
+	return db.Downsample(points, query.From, query.Step), nil
+}
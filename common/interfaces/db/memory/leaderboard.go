@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+)
+
+// GetLeaderboard returns the items ranked by the metric of the query
+// The items are ranked on each read: the memory db holds few items, so no sorted index is maintained.
+func (c *DBServiceMemory) GetLeaderboard(ctx context.Context, query db.LeaderboardQuery) (
+	[]entities.LeaderboardEntry, error,
+) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries := make([]entities.LeaderboardEntry, 0, len(c.dataItems))
+	for _, item := range c.dataItems {
+		if query.Language != nil &&
+			db.LeaderboardLanguage(item.Language) != db.LeaderboardLanguage(*query.Language) {
+			continue
+		}
+		entries = append(
+			entries, entities.LeaderboardEntry{
+				Item: entities.RepoItem{
+					ID:       item.ID,
+					Name:     item.Name,
+					FullName: item.FullName,
+					Owner:    item.Owner,
+					HTMLUrl:  item.HTMLUrl,
+					Language: item.Language,
+				},
+				Value: db.LeaderboardValue(item, query.Metric),
+			},
+		)
+	}
+
+	db.SortLeaderboard(entries)
+	if len(entries) > query.Limit {
+		entries = entries[:query.Limit]
+	}
+	return entries, nil
+}
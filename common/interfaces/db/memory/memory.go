@@ -3,11 +3,13 @@ package memory
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
 	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/Scalingo/sclng-backend-test-v1/common/quality"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
@@ -29,6 +31,11 @@ type DBServiceMemory struct {
 	mutex *sync.Mutex
 
 	dataItems map[repoKey]entities.RepoItem
+	vectors   map[repoKey]db.LanguageVector
+	history   map[historyKey][]entities.HistoryPoint
+
+	// qualityWeights are the weights of the quality scores of the items (see SetQualityWeights)
+	qualityWeights quality.Weights
 }
 
 // getRepoKey returns the key for a repo entry
@@ -37,31 +44,35 @@ func getRepoKey(repoID int64) repoKey {
 }
 
 // GetAvgNumForksPerRepoByLanguage returns the average number of forks per repo by language
-func (c *DBServiceMemory) GetAvgNumForksPerRepoByLanguage(ctx context.Context) (map[string]float32, error) {
-	//TODO implement me
-	//  NOTE: I did not have time to implement this
-	panic("implement me")
+func (c *DBServiceMemory) GetAvgNumForksPerRepoByLanguage(ctx context.Context, filters db.GetRepoListFilters) (
+	map[string]float32, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return averageByLanguage(list, func(item entities.RepoItem) int { return item.ForksCount }), nil
 }
 
 // GetNumReposByLanguage returns the number of repos by language
-func (c *DBServiceMemory) GetNumReposByLanguage(ctx context.Context) (map[string]int, error) {
-	//TODO implement me
-	//  NOTE: I did not have time to implement this
-	panic("implement me")
+func (c *DBServiceMemory) GetNumReposByLanguage(ctx context.Context, filters db.GetRepoListFilters) (
+	map[string]int, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return countByLanguage(list), nil
 }
 
 // GetAvgNumOpenIssuesByLanguage returns the average number of open issues by language
-func (c *DBServiceMemory) GetAvgNumOpenIssuesByLanguage(ctx context.Context) (map[string]float32, error) {
-	//TODO implement me
-	//  NOTE: I did not have time to implement this
-	panic("implement me")
+func (c *DBServiceMemory) GetAvgNumOpenIssuesByLanguage(ctx context.Context, filters db.GetRepoListFilters) (
+	map[string]float32, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return averageByLanguage(list, func(item entities.RepoItem) int { return item.OpenIssuesCount }), nil
 }
 
 // GetAvgSizeByLanguage returns the average size by language
-func (c *DBServiceMemory) GetAvgSizeByLanguage(ctx context.Context) (map[string]float32, error) {
-	//TODO implement me
-	//  NOTE: I did not have time to implement this
-	panic("implement me")
+func (c *DBServiceMemory) GetAvgSizeByLanguage(ctx context.Context, filters db.GetRepoListFilters) (
+	map[string]float32, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return averageByLanguage(list, func(item entities.RepoItem) int { return item.Size }), nil
 }
 
 // SetRepoItemLanguages sets the languages for a repo item
@@ -74,14 +85,16 @@ func (c *DBServiceMemory) SetRepoItemLanguages(ctx context.Context, repoID int64
 	if !ok {
 		return db.ErrNotFound
 	}
-	item.Languages = langs
-	c.dataItems[keyRepo] = item
+	c.dataItems[keyRepo] = db.WithQuality(db.WithLanguages(item, langs), c.qualityWeights)
+	c.setLanguageVector(keyRepo, langs)
 
 	return nil
 }
 
 // GetRepoItem returns a repo item
 func (c *DBServiceMemory) GetRepoItem(ctx context.Context, repoID int64) (entities.RepoItem, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
 	keyItem := getRepoKey(repoID)
 
@@ -112,7 +125,7 @@ func (c *DBServiceMemory) SetRepoList(ctx context.Context, list entities.RepoLis
 		return errors.Wrap(err, "could not get existing items")
 	}
 	existingItemIDs := make(map[int64]entities.RepoItem)
-	for _, item := range existingItems {
+	for _, item := range existingItems.Items {
 		existingItemIDs[item.ID] = item
 	}
 
@@ -122,9 +135,9 @@ func (c *DBServiceMemory) SetRepoList(ctx context.Context, list entities.RepoLis
 	for _, item := range list {
 
 		// Copy the languages from the existing item to the new one (so we don't lose the data)
-		if existingItemIDs[item.ID].Languages != nil {
-			item.Languages = existingItemIDs[item.ID].Languages
-		}
+		// and score the item with them
+		item = db.PreserveLanguages(item, existingItemIDs[item.ID])
+		item = db.WithQuality(item, c.qualityWeights)
 
 		// Delete the item from the existingItems map
 		delete(existingItemIDs, item.ID)
@@ -137,20 +150,131 @@ func (c *DBServiceMemory) SetRepoList(ctx context.Context, list entities.RepoLis
 	}
 
 	// existingItems now contains the IDs that are no longer in the list. We can delete them
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 	for id := range existingItemIDs {
 		delete(c.dataItems, getRepoKey(id))
+		delete(c.vectors, getRepoKey(id))
 	}
 
 	return nil
 }
 
-// GetRepoList returns a list of repo items
-func (c *DBServiceMemory) GetRepoList(ctx context.Context, filters db.GetRepoListFilters) (entities.RepoList, error) {
+// GetRepoList returns a page of repo items
+// A filters.Limit of 0 returns all the matching repo items
+func (c *DBServiceMemory) GetRepoList(ctx context.Context, filters db.GetRepoListFilters) (entities.RepoPage, error) {
+	list, scores := c.findRepoItems(filters)
+
+	if filters.SortBy == db.SortByRelevance {
+		if filters.Text == nil {
+			return entities.RepoPage{}, errors.Wrap(db.ErrInvalidFilter, "sorting by relevance requires a text search")
+		}
+		sortByScore(list, scores)
+	} else if err := sortRepoList(list, filters.SortBy, filters.SortAscending); err != nil {
+		return entities.RepoPage{}, err
+	}
+
+	page := paginate(list, filters.Offset, filters.Limit)
+
+	// Count the facets over all the matching items
+	if len(filters.Facets) > 0 {
+		var err error
+		page.Facets, err = countFacets(list, filters.Facets)
+		if err != nil {
+			return entities.RepoPage{}, err
+		}
+	}
+
+	// The relevance scores of the items of the page
+	if filters.Text != nil {
+		page.Hits = make(map[int64]entities.SearchHit, len(page.Items))
+		for _, item := range page.Items {
+			page.Hits[item.ID] = entities.SearchHit{Score: scores[item.ID]}
+		}
+	}
+
+	return page, nil
+}
+
+// findRepoItems returns the items matching the filters, in no particular order
+// For a text search, it also returns the score of each matching item
+func (c *DBServiceMemory) findRepoItems(filters db.GetRepoListFilters) (entities.RepoList, map[int64]float64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var scorer textScorer
+	if filters.Text != nil {
+		scorer = newTextScorer(c.dataItems, *filters.Text)
+	}
 	list := entities.RepoList{}
+	scores := map[int64]float64{}
 	for _, item := range c.dataItems {
+		if !matchesFilters(item, filters) {
+			continue
+		}
+		if filters.Text != nil {
+			score, ok := scorer.score(item)
+			if !ok {
+				continue
+			}
+			scores[item.ID] = score
+		}
 		list = append(list, item)
 	}
-	return list, nil
+	return list, scores
+}
+
+// sortValues extracts the value of each sort field from a repo item
+var sortValues = map[db.SortField]func(item entities.RepoItem) int64{
+	db.SortByCreatedAt:  func(item entities.RepoItem) int64 { return item.CreatedAt.Unix() },
+	db.SortByForks:      func(item entities.RepoItem) int64 { return int64(item.ForksCount) },
+	db.SortByWatchers:   func(item entities.RepoItem) int64 { return int64(item.WatchersCount) },
+	db.SortBySize:       func(item entities.RepoItem) int64 { return int64(item.Size) },
+	db.SortByOpenIssues: func(item entities.RepoItem) int64 { return int64(item.OpenIssuesCount) },
+	db.SortByQuality:    func(item entities.RepoItem) int64 { return int64(item.Quality.Score) },
+}
+
+// sortRepoList sorts the list in place by the sort field (defaults to db.SortByCreatedAt, descending)
+// Ties are broken by ID so that pages are stable between requests (map iteration order is random)
+func sortRepoList(list entities.RepoList, sortBy db.SortField, ascending bool) error {
+	if sortBy == "" {
+		sortBy = db.SortByCreatedAt
+	}
+	value, ok := sortValues[sortBy]
+	if !ok {
+		return errors.Errorf("unknown sort field %q", sortBy)
+	}
+
+	sort.Slice(
+		list, func(i, j int) bool {
+			vi, vj := value(list[i]), value(list[j])
+			if vi == vj {
+				return list[i].ID < list[j].ID
+			}
+			if ascending {
+				return vi < vj
+			}
+			return vi > vj
+		},
+	)
+	return nil
+}
+
+// paginate returns the page [offset, offset+limit) of the list
+// A limit of 0 returns all the items after the offset
+func paginate(list entities.RepoList, offset, limit int) entities.RepoPage {
+	total := len(list)
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+	return entities.RepoPage{
+		Items: list[offset:end],
+		Total: total,
+	}
 }
 
 var _ db.Service = (*DBServiceMemory)(nil)
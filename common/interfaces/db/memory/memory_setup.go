@@ -4,6 +4,8 @@ import (
 	"sync"
 
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/Scalingo/sclng-backend-test-v1/common/quality"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,10 +22,29 @@ func New(log logrus.FieldLogger) (*DBServiceMemory, error) {
 		log:       log,
 		mutex:     &sync.Mutex{},
 		dataItems: map[repoKey]entities.RepoItem{},
+		vectors:   map[repoKey]db.LanguageVector{},
+		history:   map[historyKey][]entities.HistoryPoint{},
+
+		qualityWeights: quality.DefaultWeights,
 	}, nil
 }
 
+// SetQualityWeights sets the weights of the quality scores of the items stored from now on
+// It returns an error if the weights are not valid (see quality.Weights.Validate)
+func (c *DBServiceMemory) SetQualityWeights(weights quality.Weights) error {
+	if err := weights.Validate(); err != nil {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.qualityWeights = weights
+	return nil
+}
+
 // Reset resets the db
 func (c *DBServiceMemory) Reset() {
 	c.dataItems = map[repoKey]entities.RepoItem{}
+	c.vectors = map[repoKey]db.LanguageVector{}
+	c.history = map[historyKey][]entities.HistoryPoint{}
 }
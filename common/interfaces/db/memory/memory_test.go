@@ -47,3 +47,159 @@ func TestSetRepoList_PreserveLanguages(t *testing.T) {
 	memoryService.Reset()
 	db.SetRepoList_PreserveLanguages(t, memoryService, testKey)
 }
+
+func TestDBServiceMemory_GetRepoList_Pagination(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_Pagination(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_Sort(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_Sort(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_SortTies(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_SortTies(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_RangeFilters(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_RangeFilters(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_Query(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_Query(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_SpecialCharacters(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_SpecialCharacters(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoItem_NotFound(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoItem_NotFound(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_Fields(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_Fields(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_Facets(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_Facets(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetSuggestions(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetSuggestions(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_Text(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_Text(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetStatsByLanguage_Filters(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetStatsByLanguage_Filters(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_Aggregate(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.Aggregate(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetLanguageStats(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetLanguageStats(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetLanguageCooccurrence(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetLanguageCooccurrence(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetDistributions(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetDistributions(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetStatsHistory(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetStatsHistory(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetLeaderboard(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetLeaderboard(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetLeaderboard_DerivedLanguage(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetLeaderboard_DerivedLanguage(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetOwners(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetOwners(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetLanguages(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetLanguages(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetLicenseStats(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetLicenseStats(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_SetRepoItemLanguages_DerivedFields(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.SetRepoItemLanguages_DerivedFields(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetSimilarRepos(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetSimilarRepos(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetSimilarRepos_UnknownLanguages(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetSimilarRepos_UnknownLanguages(t, memoryService, testKey)
+}
+
+func TestDBServiceMemory_GetRepoList_Quality(t *testing.T) {
+	testKey := t.Name()
+	memoryService.Reset()
+	db.GetRepoList_Quality(t, memoryService, testKey)
+}
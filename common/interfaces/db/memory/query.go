@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/query"
+)
+
+// matchesQuery evaluates a search query AST against a repo item (a nil query matches everything)
+func matchesQuery(item entities.RepoItem, expr query.Expr) bool {
+	switch e := expr.(type) {
+	case nil:
+		return true
+	case query.AndExpr:
+		for _, operand := range e.Operands {
+			if !matchesQuery(item, operand) {
+				return false
+			}
+		}
+		return true
+	case query.OrExpr:
+		for _, operand := range e.Operands {
+			if matchesQuery(item, operand) {
+				return true
+			}
+		}
+		return false
+	case query.NotExpr:
+		return !matchesQuery(item, e.Operand)
+	case query.MatchExpr:
+		return matchesQueryValue(item, e)
+	case query.RangeExpr:
+		value, ok := queryNumber(item, e.Field)
+		if !ok {
+			return false
+		}
+		if e.Min != nil && (value < *e.Min || (e.MinExclusive && value == *e.Min)) {
+			return false
+		}
+		if e.Max != nil && (value > *e.Max || (e.MaxExclusive && value == *e.Max)) {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// matchesQueryValue evaluates a text, tag or bool match against a repo item
+func matchesQueryValue(item entities.RepoItem, e query.MatchExpr) bool {
+	switch e.Field {
+	case query.FieldName:
+		return matchesQueryText(item.Name, e.Value)
+	case query.FieldLicense:
+		return strings.EqualFold(item.LicenseKey, e.Value)
+	case query.FieldLicenseName:
+		return matchesQueryText(item.LicenseName, e.Value)
+	case query.FieldLanguage:
+		if strings.EqualFold(item.Language, e.Value) {
+			return true
+		}
+		for lang := range item.Languages {
+			if strings.EqualFold(lang, e.Value) {
+				return true
+			}
+		}
+		return false
+	case query.FieldAllowForking:
+		return strconv.FormatBool(item.AllowForking) == e.Value
+	}
+	return false
+}
+
+// matchesQueryText evaluates a text match: values with spaces are matched as a phrase, as in the redis queries
+func matchesQueryText(text, value string) bool {
+	if strings.ContainsAny(value, " \t") {
+		return matchesPhrase(text, value)
+	}
+	return matchesText(text, value)
+}
+
+// queryNumber returns the value of a number or date field (as a unix timestamp)
+func queryNumber(item entities.RepoItem, field query.Field) (float64, bool) {
+	switch field {
+	case query.FieldForks:
+		return float64(item.ForksCount), true
+	case query.FieldWatchers:
+		return float64(item.WatchersCount), true
+	case query.FieldSize:
+		return float64(item.Size), true
+	case query.FieldOpenIssues:
+		return float64(item.OpenIssuesCount), true
+	case query.FieldCreated:
+		return float64(item.CreatedAt.Unix()), true
+	case query.FieldUpdated:
+		return float64(item.UpdatedAt.Unix()), true
+	}
+	return 0, false
+}
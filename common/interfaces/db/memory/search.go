@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/fulltext"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+)
+
+// textValues extracts the value of each TEXT field of the full-text search from a repo item
+var textValues = map[string]func(item entities.RepoItem) string{
+	"name":        func(item entities.RepoItem) string { return item.Name },
+	"description": func(item entities.RepoItem) string { return item.Description },
+}
+
+// textScorer scores the repo items against the terms of a text search
+// Like the redis search, an item matches when every term is found (stemmed) in any of the text fields, and it is
+// scored with TF-IDF weighted by db.TextWeights: sum over the terms and fields of weight * tf * log(1 + N/df).
+type textScorer struct {
+	terms []string
+	idf   map[string]float64
+}
+
+// newTextScorer computes the inverse document frequencies of the terms of the text over all the items
+func newTextScorer(items map[repoKey]entities.RepoItem, text string) textScorer {
+	s := textScorer{terms: fulltext.Terms(text), idf: map[string]float64{}}
+
+	df := map[string]int{}
+	for _, item := range items {
+		counts := termCounts(item)
+		for _, term := range s.terms {
+			if counts[term] > 0 {
+				df[term]++
+			}
+		}
+	}
+	for term, n := range df {
+		s.idf[term] = math.Log(1 + float64(len(items))/float64(n))
+	}
+	return s
+}
+
+// score returns the score of the item, and false if the item does not match every term
+func (s textScorer) score(item entities.RepoItem) (float64, bool) {
+	if len(s.terms) == 0 {
+		return 0, false
+	}
+
+	// Count the terms by field
+	fieldCounts := make(map[string]map[string]int, len(textValues))
+	for field, valueOf := range textValues {
+		fieldCounts[field] = countTerms(valueOf(item))
+	}
+
+	var score float64
+	for _, term := range s.terms {
+		found := false
+		for field, counts := range fieldCounts {
+			if tf := counts[term]; tf > 0 {
+				found = true
+				score += db.TextWeights[field] * float64(tf) * s.idf[term]
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score, true
+}
+
+// termCounts counts the stemmed terms of all the text fields of the item
+func termCounts(item entities.RepoItem) map[string]int {
+	counts := map[string]int{}
+	for _, valueOf := range textValues {
+		for term, n := range countTerms(valueOf(item)) {
+			counts[term] += n
+		}
+	}
+	return counts
+}
+
+// countTerms counts the stemmed terms of the text
+func countTerms(text string) map[string]int {
+	counts := map[string]int{}
+	for _, word := range fulltext.Tokenize(text) {
+		counts[fulltext.Stem(word)]++
+	}
+	return counts
+}
+
+// sortByScore sorts the list in place by descending score
+// Ties are broken by ID so that pages are stable between requests
+func sortByScore(list entities.RepoList, scores map[int64]float64) {
+	sort.Slice(
+		list, func(i, j int) bool {
+			si, sj := scores[list[i].ID], scores[list[j].ID]
+			if si == sj {
+				return list[i].ID < list[j].ID
+			}
+			return si > sj
+		},
+	)
+}
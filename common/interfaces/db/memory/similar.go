@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+)
+
+// setLanguageVector sets the vector of the languages breakdown of an item in the vector index
+// The items without bytes in their breakdown are removed from the index. The caller must hold the mutex.
+func (c *DBServiceMemory) setLanguageVector(key repoKey, langs entities.Languages) {
+	vector := db.NewLanguageVector(langs)
+	if vector == nil {
+		delete(c.vectors, key)
+		return
+	}
+	c.vectors[key] = vector
+}
+
+// GetSimilarRepos returns the items most similar to the repository of the query
+// The candidates are the nearest items by language in the vector index, found by comparing the vector of the
+// repository with every vector: the memory db holds few items, so an exact search is fast enough.
+func (c *DBServiceMemory) GetSimilarRepos(ctx context.Context, query db.SimilarQuery) (
+	[]entities.SimilarRepo, error,
+) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keyRepo := getRepoKey(query.ID)
+	target, ok := c.dataItems[keyRepo]
+	if !ok {
+		return nil, db.ErrNotFound
+	}
+	vector, ok := c.vectors[keyRepo]
+	if !ok {
+		return []entities.SimilarRepo{}, nil
+	}
+
+	type neighbour struct {
+		item       entities.RepoItem
+		similarity float64
+	}
+	neighbours := make([]neighbour, 0, len(c.vectors))
+	for key, itemVector := range c.vectors {
+		if key == keyRepo {
+			continue
+		}
+		neighbours = append(neighbours, neighbour{item: c.dataItems[key], similarity: vector.Cosine(itemVector)})
+	}
+	sort.Slice(
+		neighbours, func(i, j int) bool {
+			if neighbours[i].similarity != neighbours[j].similarity {
+				return neighbours[i].similarity > neighbours[j].similarity
+			}
+			return neighbours[i].item.ID > neighbours[j].item.ID
+		},
+	)
+	if len(neighbours) > query.Candidates() {
+		neighbours = neighbours[:query.Candidates()]
+	}
+
+	candidates := make(entities.RepoList, len(neighbours))
+	for i, n := range neighbours {
+		candidates[i] = n.item
+	}
+	return db.RankSimilar(target, candidates, query), nil
+}
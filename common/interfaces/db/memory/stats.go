@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+)
+
+// countByLanguage counts the items by primary language
+func countByLanguage(list entities.RepoList) map[string]int {
+	out := map[string]int{}
+	for _, item := range list {
+		out[item.Language]++
+	}
+	return out
+}
+
+// averageByLanguage averages the value of the items by primary language
+func averageByLanguage(list entities.RepoList, valueOf func(item entities.RepoItem) int) map[string]float32 {
+	sums := map[string]int{}
+	for _, item := range list {
+		sums[item.Language] += valueOf(item)
+	}
+
+	counts := countByLanguage(list)
+	out := make(map[string]float32, len(sums))
+	for language, sum := range sums {
+		out[language] = float32(sum) / float32(counts[language])
+	}
+	return out
+}
+
+// GetLanguageStats computes the statistics of the languages breakdowns of the repositories matching the filters
+func (c *DBServiceMemory) GetLanguageStats(ctx context.Context, filters db.GetRepoListFilters) (
+	entities.LanguageStats, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return db.ComputeLanguageStats(db.GroupItems(list)), nil
+}
+
+// GetLanguages returns the summary of each language of the repositories matching the filters
+func (c *DBServiceMemory) GetLanguages(ctx context.Context, filters db.GetRepoListFilters) (
+	[]entities.LanguageSummary, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return db.ComputeLanguageSummaries(db.GroupItems(list)), nil
+}
+
+// GetLanguage returns the summary of a language and the top repositories using it
+func (c *DBServiceMemory) GetLanguage(ctx context.Context, name string, top int) (entities.LanguageDetail, error) {
+	list, _ := c.findRepoItems(db.GetRepoListFilters{})
+	detail, ok := db.ComputeLanguageDetail(list, name, top)
+	if !ok {
+		return entities.LanguageDetail{}, db.ErrNotFound
+	}
+	return detail, nil
+}
+
+// GetOwners returns the summary of each owner of the repositories matching the filters
+func (c *DBServiceMemory) GetOwners(ctx context.Context, filters db.GetRepoListFilters) (
+	[]entities.OwnerSummary, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return db.ComputeOwners(db.GroupItems(list)), nil
+}
+
+// GetLicenseStats counts the repositories matching the filters by license and license family
+func (c *DBServiceMemory) GetLicenseStats(ctx context.Context, filters db.GetRepoListFilters) (
+	entities.LicenseStats, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return db.ComputeLicenseStats(list), nil
+}
+
+// GetLanguageCooccurrence counts the repositories matching the filters using each pair of languages together
+func (c *DBServiceMemory) GetLanguageCooccurrence(ctx context.Context, filters db.GetRepoListFilters, minBytes int64) (
+	entities.LanguageCooccurrence, error,
+) {
+	list, _ := c.findRepoItems(filters)
+	return db.ComputeLanguageCooccurrence(db.GroupItems(list), minBytes), nil
+}
@@ -0,0 +1,51 @@
+package memory
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
+	"github.com/pkg/errors"
+)
+
+// GetSuggestions returns the values of the field starting with the prefix (case-insensitive),
+// ranked by the number of repositories with the value
+// The counts are computed from the current items, so they are always up to date with SetRepoList and
+// SetRepoItemLanguages
+func (c *DBServiceMemory) GetSuggestions(ctx context.Context, field db.SuggestField, prefix string, limit int) (
+	[]entities.Suggestion, error,
+) {
+	if !isSuggestField(field) {
+		return nil, errors.Errorf("unknown suggest field %q", field)
+	}
+
+	prefix = strings.ToLower(prefix)
+
+	c.mutex.Lock()
+	counts := map[string]int{}
+	for _, item := range c.dataItems {
+		for _, value := range db.SuggestionValues(item, field) {
+			if strings.HasPrefix(strings.ToLower(value), prefix) {
+				counts[value]++
+			}
+		}
+	}
+	c.mutex.Unlock()
+
+	out := make([]entities.Suggestion, 0, len(counts))
+	for value, count := range counts {
+		out = append(out, entities.Suggestion{Value: value, Count: count})
+	}
+
+	return db.RankSuggestions(out, limit), nil
+}
+
+func isSuggestField(field db.SuggestField) bool {
+	for _, f := range db.SuggestFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
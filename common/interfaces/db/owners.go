@@ -0,0 +1,69 @@
+package db
+
+import (
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+)
+
+// ComputeOwners computes the summary of each owner of the groups of items, by descending number of repositories (ties
+// by descending total size, then login)
+// Items without an owner are ignored.
+func ComputeOwners(groups []ItemGroup) []entities.OwnerSummary {
+	byOwner := map[string][]ItemGroup{}
+	for _, group := range groups {
+		if group.Owner != "" {
+			byOwner[group.Owner] = append(byOwner[group.Owner], group)
+		}
+	}
+
+	out := make([]entities.OwnerSummary, 0, len(byOwner))
+	for login, groups := range byOwner {
+		summary := entities.OwnerSummary{Login: login, DominantLanguage: DominantLanguage(groups)}
+		for _, group := range groups {
+			summary.Repos += group.Count
+			summary.TotalSize += group.Size
+		}
+		out = append(out, summary)
+	}
+
+	sort.Slice(
+		out, func(i, j int) bool {
+			if out[i].Repos != out[j].Repos {
+				return out[i].Repos > out[j].Repos
+			}
+			if out[i].TotalSize != out[j].TotalSize {
+				return out[i].TotalSize > out[j].TotalSize
+			}
+			return out[i].Login < out[j].Login
+		},
+	)
+	return out
+}
+
+// DominantLanguage returns the language with the most bytes of code in the languages breakdowns of the groups of items
+// When no item has a breakdown yet, it returns the most frequent primary language. Ties are broken by name, and it
+// returns an empty string when the items have no language.
+func DominantLanguage(groups []ItemGroup) string {
+	bytes := map[string]int64{}
+	for _, group := range groups {
+		for language, b := range group.Languages {
+			bytes[language] += b * int64(group.Count)
+		}
+	}
+	if len(bytes) == 0 {
+		for _, group := range groups {
+			if group.Language != "" {
+				bytes[group.Language] += int64(group.Count)
+			}
+		}
+	}
+
+	var dominant string
+	for language, b := range bytes {
+		if b > bytes[dominant] || (b == bytes[dominant] && language < dominant) {
+			dominant = language
+		}
+	}
+	return dominant
+}
@@ -0,0 +1,13 @@
+package db
+
+import (
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/quality"
+)
+
+// WithQuality returns the item with its quality score computed with the weights (see quality.Compute)
+// The languages breakdown must be set first.
+func WithQuality(item entities.RepoItem, weights quality.Weights) entities.RepoItem {
+	item.Quality = quality.Compute(item, weights)
+	return item
+}
@@ -0,0 +1,154 @@
+package db
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/pkg/errors"
+)
+
+// MaxSimilarLimit is the maximum number of similar items returned for a repository
+const MaxSimilarLimit = 100
+
+// similarCandidatesFactor is the number of candidates per similar item: the nearest items by language are re-ranked
+// with the license and the size, so more items than the limit are read from the vector index
+const similarCandidatesFactor = 5
+
+// minSimilarCandidates is the minimum number of candidates read from the vector index
+const minSimilarCandidates = 50
+
+// SimilarQuery selects the items similar to a repository
+//   - ID: the ID of the repository
+//   - Limit: the number of similar items (1 to MaxSimilarLimit)
+//   - LicenseWeight: the weight of having the same license in the score (0 to 1)
+//   - SizeWeight: the weight of the ratio of the sizes in the score (0 to 1)
+//
+// The weight of the languages is what is left: 1 - LicenseWeight - SizeWeight.
+type SimilarQuery struct {
+	ID            int64
+	Limit         int
+	LicenseWeight float64
+	SizeWeight    float64
+}
+
+// Validate checks the query
+// It returns an error wrapping ErrInvalidFilter if the similar items cannot be read
+func (q SimilarQuery) Validate() error {
+	if q.Limit < 1 || q.Limit > MaxSimilarLimit {
+		return errors.Wrapf(ErrInvalidFilter, "the limit must be between 1 and %d, got %d", MaxSimilarLimit, q.Limit)
+	}
+	if q.LicenseWeight < 0 || q.LicenseWeight > 1 || q.SizeWeight < 0 || q.SizeWeight > 1 {
+		return errors.Wrapf(
+			ErrInvalidFilter, "the weights must be between 0 and 1, got %g and %g", q.LicenseWeight, q.SizeWeight,
+		)
+	}
+	if q.LicenseWeight+q.SizeWeight > 1 {
+		return errors.Wrapf(
+			ErrInvalidFilter, "the sum of the weights must be at most 1, got %g", q.LicenseWeight+q.SizeWeight,
+		)
+	}
+	return nil
+}
+
+// Candidates returns the number of nearest items by language to re-rank (see RankSimilar)
+func (q SimilarQuery) Candidates() int {
+	if n := q.Limit * similarCandidatesFactor; n > minSimilarCandidates {
+		return n
+	}
+	return minSimilarCandidates
+}
+
+// LanguageVector is a languages breakdown normalised to a unit vector (language -> share of the bytes)
+type LanguageVector map[string]float64
+
+// NewLanguageVector returns the normalised vector of a languages breakdown, or nil when the breakdown has no bytes
+func NewLanguageVector(langs entities.Languages) LanguageVector {
+	var norm float64
+	for _, bytes := range langs {
+		norm += float64(bytes) * float64(bytes)
+	}
+	if norm == 0 {
+		return nil
+	}
+	norm = math.Sqrt(norm)
+
+	out := make(LanguageVector, len(langs))
+	for language, bytes := range langs {
+		if bytes > 0 {
+			out[language] = float64(bytes) / norm
+		}
+	}
+	return out
+}
+
+// Cosine returns the cosine similarity of two normalised vectors, between 0 and 1 (the bytes are never negative)
+func (v LanguageVector) Cosine(w LanguageVector) float64 {
+	if len(w) < len(v) {
+		v, w = w, v
+	}
+	var dot float64
+	for language, x := range v {
+		dot += x * w[language]
+	}
+	return math.Min(dot, 1)
+}
+
+// SimilarityScore mixes the cosine similarity of the languages of two items with the license and the size
+//   - license: 1 when both items have the same license, 0 otherwise (or when the repository has no license)
+//   - size: the ratio of the smallest size to the largest size
+func SimilarityScore(target, item entities.RepoItem, languageSimilarity float64, q SimilarQuery) float64 {
+	var license float64
+	if target.LicenseKey != "" && target.LicenseKey == item.LicenseKey {
+		license = 1
+	}
+	small, large := float64(target.Size+1), float64(item.Size+1)
+	if small > large {
+		small, large = large, small
+	}
+
+	languageWeight := 1 - q.LicenseWeight - q.SizeWeight
+	return languageWeight*languageSimilarity + q.LicenseWeight*license + q.SizeWeight*small/large
+}
+
+// RankSimilar ranks the candidates by descending similarity to the target (see SimilarityScore), ties by descending
+// language similarity then descending ID, and returns the first q.Limit
+// The target and the candidates without languages breakdown are skipped.
+func RankSimilar(target entities.RepoItem, candidates entities.RepoList, q SimilarQuery) []entities.SimilarRepo {
+	out := make([]entities.SimilarRepo, 0, len(candidates))
+	vector := NewLanguageVector(target.Languages)
+	if vector == nil {
+		return out
+	}
+
+	for _, item := range candidates {
+		itemVector := NewLanguageVector(item.Languages)
+		if item.ID == target.ID || itemVector == nil {
+			continue
+		}
+		similarity := vector.Cosine(itemVector)
+		out = append(
+			out, entities.SimilarRepo{
+				Item:               item,
+				Score:              SimilarityScore(target, item, similarity, q),
+				LanguageSimilarity: similarity,
+			},
+		)
+	}
+
+	sort.Slice(
+		out, func(i, j int) bool {
+			if out[i].Score != out[j].Score {
+				return out[i].Score > out[j].Score
+			}
+			if out[i].LanguageSimilarity != out[j].LanguageSimilarity {
+				return out[i].LanguageSimilarity > out[j].LanguageSimilarity
+			}
+			return out[i].Item.ID > out[j].Item.ID
+		},
+	)
+	if len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+)
+
+// SuggestField is a field values can be suggested for (autocomplete)
+type SuggestField string
+
+const (
+	SuggestName     SuggestField = "name"     // the repository name
+	SuggestOwner    SuggestField = "owner"    // the owner login
+	SuggestLanguage SuggestField = "language" // any language of the repository (primary or breakdown)
+	SuggestLicense  SuggestField = "license"  // the license name
+)
+
+// SuggestFields lists the valid SuggestField values
+var SuggestFields = []SuggestField{SuggestName, SuggestOwner, SuggestLanguage, SuggestLicense}
+
+// SuggestionValues returns the distinct, non-empty values of the field for a repo item
+func SuggestionValues(item entities.RepoItem, field SuggestField) []string {
+	var values []string
+	switch field {
+	case SuggestName:
+		values = []string{item.Name}
+	case SuggestOwner:
+		values = []string{item.Owner}
+	case SuggestLicense:
+		values = []string{item.LicenseName}
+	case SuggestLanguage:
+		values = append([]string{item.Language}, item.Languages.Strings()...)
+	}
+
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		out = append(out, value)
+	}
+	return out
+}
+
+// RankSuggestions orders the suggestions by descending count (ties by value) and keeps the first limit
+func RankSuggestions(in []entities.Suggestion, limit int) []entities.Suggestion {
+	sort.Slice(
+		in, func(i, j int) bool {
+			if in[i].Count != in[j].Count {
+				return in[i].Count > in[j].Count
+			}
+			return in[i].Value < in[j].Value
+		},
+	)
+	if limit > 0 && len(in) > limit {
+		in = in[:limit]
+	}
+	return in
+}
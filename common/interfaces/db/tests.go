@@ -2,15 +2,47 @@ package db
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/quality"
+	"github.com/Scalingo/sclng-backend-test-v1/common/query"
 )
 
 var SetRepoList_SetLanguages_GetItem = setRepoList_SetLanguages_GetItem
 var SetRepoList_PreserveLanguages = setRepoList_PreserveLanguages
+var GetRepoList_Pagination = getRepoList_Pagination
+var GetRepoList_Sort = getRepoList_Sort
+var GetRepoList_SortTies = getRepoList_SortTies
+var GetRepoList_RangeFilters = getRepoList_RangeFilters
+var GetRepoList_Query = getRepoList_Query
+var GetRepoList_SpecialCharacters = getRepoList_SpecialCharacters
+var GetRepoItem_NotFound = getRepoItem_NotFound
+var GetRepoList_Fields = getRepoList_Fields
+var GetRepoList_Facets = getRepoList_Facets
+var GetSuggestions = getSuggestions
+var GetRepoList_Text = getRepoList_Text
+var GetStatsByLanguage_Filters = getStatsByLanguage_Filters
+var Aggregate = aggregate
+var GetLanguageStats = getLanguageStats
+var GetLanguageCooccurrence = getLanguageCooccurrence
+var GetDistributions = getDistributions
+var GetStatsHistory = getStatsHistory
+var GetLeaderboard = getLeaderboard
+var GetLeaderboard_DerivedLanguage = getLeaderboard_DerivedLanguage
+var GetOwners = getOwners
+var GetLanguages = getLanguages
+var GetLicenseStats = getLicenseStats
+var SetRepoItemLanguages_DerivedFields = setRepoItemLanguages_DerivedFields
+var GetSimilarRepos = getSimilarRepos
+var GetSimilarRepos_UnknownLanguages = getSimilarRepos_UnknownLanguages
+var GetRepoList_Quality = getRepoList_Quality
 
 func setRepoList_SetLanguages_GetItem(t *testing.T, dbService Service, testKey string) {
 
@@ -46,6 +78,9 @@ func setRepoList_SetLanguages_GetItem(t *testing.T, dbService Service, testKey s
 		return
 	}
 
+	// The item is scored when it is stored
+	item.Quality = quality.Compute(item, quality.DefaultWeights)
+
 	// GET
 	val, err := dbService.GetRepoItem(context.Background(), item.ID)
 	if err != nil {
@@ -68,7 +103,12 @@ func setRepoList_SetLanguages_GetItem(t *testing.T, dbService Service, testKey s
 		return
 	}
 
+	// The fields derived from the languages are set with them
 	item.Languages = languages
+	item.LanguageCount = 2
+	item.DominantLanguage = "B"
+	item.LanguagePercentages = map[string]float64{"A": 100.0 / 3, "B": 200.0 / 3}
+	item.Quality = quality.Compute(item, quality.DefaultWeights)
 
 	// GET
 	val, err = dbService.GetRepoItem(context.Background(), item.ID)
@@ -120,6 +160,9 @@ func setRepoList_PreserveLanguages(t *testing.T, dbService Service, testKey stri
 		return
 	}
 
+	// The item is scored with its languages when it is stored
+	itemV1.Quality = quality.Compute(itemV1, quality.DefaultWeights)
+
 	// GET
 	val, err := dbService.GetRepoItem(context.Background(), itemV1.ID)
 	if err != nil {
@@ -153,3 +196,2048 @@ func setRepoList_PreserveLanguages(t *testing.T, dbService Service, testKey stri
 	}
 
 }
+
+// getRepoList_Pagination checks that paging through the list returns every item exactly once, with the total count
+func getRepoList_Pagination(t *testing.T, dbService Service, testKey string) {
+	const numItems = 5
+	const pageSize = 2
+
+	list := make(entities.RepoList, numItems)
+	for i := range list {
+		list[i] = entities.RepoItem{
+			ID:        int64(i + 1),
+			Name:      fmt.Sprintf("repo%d", i+1),
+			CreatedAt: time.Date(2021, 1, i+1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2021, 1, i+1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	seen := map[int64]bool{}
+	for offset := 0; offset < numItems; offset += pageSize {
+		page, err := dbService.GetRepoList(context.Background(), GetRepoListFilters{Offset: offset, Limit: pageSize})
+		if err != nil {
+			t.Errorf("GetRepoList() error = %v", err)
+			return
+		}
+		if page.Total != numItems {
+			t.Errorf("GetRepoList() offset %d total = %d, want %d", offset, page.Total, numItems)
+			return
+		}
+		wantLen := pageSize
+		if numItems-offset < pageSize {
+			wantLen = numItems - offset
+		}
+		if len(page.Items) != wantLen {
+			t.Errorf("GetRepoList() offset %d got %d items, want %d", offset, len(page.Items), wantLen)
+			return
+		}
+		for _, item := range page.Items {
+			if seen[item.ID] {
+				t.Errorf("GetRepoList() offset %d returned item %d twice", offset, item.ID)
+				return
+			}
+			seen[item.ID] = true
+		}
+	}
+	if len(seen) != numItems {
+		t.Errorf("GetRepoList() paged through %d items, want %d", len(seen), numItems)
+		return
+	}
+
+	// No limit returns everything
+	page, err := dbService.GetRepoList(context.Background(), GetRepoListFilters{})
+	if err != nil {
+		t.Errorf("GetRepoList() error = %v", err)
+		return
+	}
+	if len(page.Items) != numItems {
+		t.Errorf("GetRepoList() without limit got %d items, want %d", len(page.Items), numItems)
+		return
+	}
+
+	// An offset past the end returns an empty page
+	page, err = dbService.GetRepoList(context.Background(), GetRepoListFilters{Offset: numItems, Limit: pageSize})
+	if err != nil {
+		t.Errorf("GetRepoList() error = %v", err)
+		return
+	}
+	if len(page.Items) != 0 || page.Total != numItems {
+		t.Errorf("GetRepoList() past the end got %d items (total %d), want 0 (total %d)", len(page.Items), page.Total, numItems)
+		return
+	}
+}
+
+// getRepoList_Sort checks the order of the list for each sort field and order
+func getRepoList_Sort(t *testing.T, dbService Service, testKey string) {
+
+	// The IDs are in the same order as the values of every sort field (the description and the size order the
+	// quality scores)
+	list := entities.RepoList{}
+	for i := 1; i <= 3; i++ {
+		list = append(
+			list, entities.RepoItem{
+				ID:              int64(i),
+				Name:            fmt.Sprintf("repo%d", i),
+				Description:     strings.Repeat("d", i*10),
+				CreatedAt:       time.Date(2021, 1, i, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:       time.Date(2021, 1, i, 0, 0, 0, 0, time.UTC),
+				Size:            i * 100,
+				ForksCount:      i * 10,
+				WatchersCount:   i * 20,
+				OpenIssuesCount: i,
+			},
+		)
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	ascending := []int64{1, 2, 3}
+	descending := []int64{3, 2, 1}
+
+	type sortTest struct {
+		sortBy    SortField
+		ascending bool
+		want      []int64
+	}
+	tests := []sortTest{
+		{sortBy: "", ascending: false, want: descending},
+		{sortBy: SortByCreatedAt, ascending: true, want: ascending},
+	}
+	for _, sortBy := range SortFields {
+		tests = append(
+			tests,
+			sortTest{sortBy: sortBy, ascending: true, want: ascending},
+			sortTest{sortBy: sortBy, ascending: false, want: descending},
+		)
+	}
+
+	for _, tt := range tests {
+		page, err := dbService.GetRepoList(
+			context.Background(), GetRepoListFilters{SortBy: tt.sortBy, SortAscending: tt.ascending},
+		)
+		if err != nil {
+			t.Errorf("GetRepoList() sort %q error = %v", tt.sortBy, err)
+			return
+		}
+		got := make([]int64, len(page.Items))
+		for i, item := range page.Items {
+			got[i] = item.ID
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetRepoList() sort %q ascending %v got = %v, want %v", tt.sortBy, tt.ascending, got, tt.want)
+		}
+	}
+}
+
+// getRepoList_SortTies checks that the items with equal sort values are sorted by ascending ID in both orders, and
+// that paging through them returns every item exactly once
+func getRepoList_SortTies(t *testing.T, dbService Service, testKey string) {
+
+	// Items 2, 4 and 5 have the same number of forks, as do 1 and 3. The IDs are not stored in order.
+	forks := map[int64]int{4: 10, 1: 20, 5: 10, 3: 20, 2: 10}
+	list := entities.RepoList{}
+	for _, id := range []int64{4, 1, 5, 3, 2} {
+		list = append(
+			list, entities.RepoItem{
+				ID:         id,
+				Name:       fmt.Sprintf("repo%d", id),
+				CreatedAt:  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:  time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+				ForksCount: forks[id],
+			},
+		)
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	tests := []struct {
+		sortBy    SortField
+		ascending bool
+		want      []int64
+	}{
+		{sortBy: SortByForks, ascending: false, want: []int64{1, 3, 2, 4, 5}},
+		{sortBy: SortByForks, ascending: true, want: []int64{2, 4, 5, 1, 3}},
+		{sortBy: SortByCreatedAt, ascending: false, want: []int64{1, 2, 3, 4, 5}},
+		{sortBy: SortByCreatedAt, ascending: true, want: []int64{1, 2, 3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		for _, pageSize := range []int{0, 2} {
+			var got []int64
+			for offset := 0; offset < len(list); offset += pageSize {
+				page, err := dbService.GetRepoList(
+					context.Background(), GetRepoListFilters{
+						SortBy: tt.sortBy, SortAscending: tt.ascending, Offset: offset, Limit: pageSize,
+					},
+				)
+				if err != nil {
+					t.Errorf("GetRepoList() sort %q error = %v", tt.sortBy, err)
+					return
+				}
+				for _, item := range page.Items {
+					got = append(got, item.ID)
+				}
+				if pageSize == 0 {
+					break
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf(
+					"GetRepoList() sort %q ascending %v page size %d got = %v, want %v",
+					tt.sortBy, tt.ascending, pageSize, got, tt.want,
+				)
+			}
+		}
+	}
+}
+
+// getRepoList_RangeFilters checks the numeric and date range filters
+func getRepoList_RangeFilters(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{}
+	for i := 1; i <= 3; i++ {
+		list = append(
+			list, entities.RepoItem{
+				ID:              int64(i),
+				Name:            fmt.Sprintf("repo%d", i),
+				CreatedAt:       time.Date(2021, 1, i, 0, 0, 0, 0, time.UTC),
+				UpdatedAt:       time.Date(2021, 2, i, 0, 0, 0, 0, time.UTC),
+				Size:            i * 100,
+				ForksCount:      i * 10,
+				WatchersCount:   i * 20,
+				OpenIssuesCount: i,
+			},
+		)
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	intPtr := func(v int) *int { return &v }
+	timePtr := func(v time.Time) *time.Time { return &v }
+
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    []int64
+	}{
+		{name: "No filters", filters: GetRepoListFilters{}, want: []int64{1, 2, 3}},
+		{name: "Min size inclusive", filters: GetRepoListFilters{Size: IntRange{Min: intPtr(200)}}, want: []int64{2, 3}},
+		{name: "Max size inclusive", filters: GetRepoListFilters{Size: IntRange{Max: intPtr(200)}}, want: []int64{1, 2}},
+		{
+			name:    "Forks between",
+			filters: GetRepoListFilters{ForksCount: IntRange{Min: intPtr(15), Max: intPtr(25)}},
+			want:    []int64{2},
+		},
+		{name: "Min watchers", filters: GetRepoListFilters{WatchersCount: IntRange{Min: intPtr(60)}}, want: []int64{3}},
+		{
+			name:    "Max open issues",
+			filters: GetRepoListFilters{OpenIssuesCount: IntRange{Max: intPtr(0)}},
+			want:    []int64{},
+		},
+		{
+			name: "Created after exclusive",
+			filters: GetRepoListFilters{
+				CreatedAt: TimeRange{After: timePtr(time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC))},
+			},
+			want: []int64{3},
+		},
+		{
+			name: "Updated before exclusive",
+			filters: GetRepoListFilters{
+				UpdatedAt: TimeRange{Before: timePtr(time.Date(2021, 2, 2, 0, 0, 0, 0, time.UTC))},
+			},
+			want: []int64{1},
+		},
+		{
+			name: "Combined with forks",
+			filters: GetRepoListFilters{
+				CreatedAt:  TimeRange{After: timePtr(time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC))},
+				ForksCount: IntRange{Min: intPtr(20)},
+			},
+			want: []int64{2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		tt.filters.SortBy = SortByCreatedAt
+		tt.filters.SortAscending = true
+		page, err := dbService.GetRepoList(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetRepoList() %s error = %v", tt.name, err)
+			return
+		}
+		got := make([]int64, len(page.Items))
+		for i, item := range page.Items {
+			got[i] = item.ID
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetRepoList() %s got = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// getRepoList_Query checks that search queries are evaluated the same way by every backend
+func getRepoList_Query(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{
+			ID: 1, Name: "alpha", Language: "Go", ForksCount: 5, AllowForking: true,
+			LicenseName: "MIT License", LicenseKey: "mit",
+			CreatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			ID: 2, Name: "bravo", Language: "Rust", ForksCount: 1,
+			LicenseName: "Apache License 2.0", LicenseKey: "apache-2.0",
+			CreatedAt: time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			ID: 3, Name: "charlie", Language: "Python", LicenseName: "GNU Lesser General Public License v3.0",
+			LicenseKey: "lgpl-3.0", ForksCount: 10,
+			CreatedAt: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC),
+		},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	for id, langs := range map[int64]entities.Languages{3: {"Python": 100, "Go": 10}, 2: {"Rust": 100, "C++": 5}} {
+		if err := dbService.SetRepoItemLanguages(context.Background(), id, langs); err != nil {
+			t.Errorf("SetRepoItemLanguages() error = %v", err)
+			return
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []int64
+	}{
+		{query: "language:go", want: []int64{1, 3}},
+		{query: "language:cpp", want: []int64{2}},
+		{query: "language:go -license:mit", want: []int64{3}},
+		{query: "license:lgpl-3.0", want: []int64{3}},
+		{query: "license:gpl", want: []int64{}},
+		{query: "license_name:general", want: []int64{3}},
+		{query: "language:go OR language:rust", want: []int64{1, 2, 3}},
+		{query: "-language:go", want: []int64{2}},
+		{query: "forks:>3", want: []int64{1, 3}},
+		{query: "forks:1..5", want: []int64{1, 2}},
+		{query: "forks:<=1 OR name:charlie", want: []int64{2, 3}},
+		{query: `license_name:"apache license"`, want: []int64{2}},
+		{query: "created:2024-01-02", want: []int64{2}},
+		{query: "created:>2024-01-01", want: []int64{2, 3}},
+		{query: "allow_forking:true", want: []int64{1}},
+		{query: "(language:rust OR language:python) forks:>=1", want: []int64{2, 3}},
+	}
+
+	for _, tt := range tests {
+		expr, err := query.Parse(tt.query)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.query, err)
+			return
+		}
+		page, err := dbService.GetRepoList(
+			context.Background(), GetRepoListFilters{Query: expr, SortBy: SortByCreatedAt, SortAscending: true},
+		)
+		if err != nil {
+			t.Errorf("GetRepoList() %q error = %v", tt.query, err)
+			return
+		}
+		got := make([]int64, len(page.Items))
+		for i, item := range page.Items {
+			got[i] = item.ID
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetRepoList() %q got = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// getRepoList_SpecialCharacters checks that filter values containing query syntax are matched literally
+func getRepoList_SpecialCharacters(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{
+			ID: 1, Name: "my-cli", Language: "C++", LicenseName: "Apache License 2.0",
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{ID: 2, Name: "cli", Language: "Go", CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{ID: 3, Name: "other", Language: "C", CreatedAt: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{ID: 4, Name: "gosu-lib", Language: "Gosu", CreatedAt: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)},
+		{ID: 5, Name: "my-awesome-cli", Language: "Rust", CreatedAt: time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    []int64
+	}{
+		// The words of a text filter are matched separately, within the words of the field
+		{name: "Name with a dash", filters: GetRepoListFilters{Name: strPtr("my-cli")}, want: []int64{1, 5}},
+		{name: "Name with a space", filters: GetRepoListFilters{Name: strPtr("cli my")}, want: []int64{1, 5}},
+		{name: "Name part of a word", filters: GetRepoListFilters{Name: strPtr("awe")}, want: []int64{5}},
+		{name: "License with a space", filters: GetRepoListFilters{License: strPtr("apache 2")}, want: []int64{1}},
+		{name: "License single character", filters: GetRepoListFilters{License: strPtr("lic e")}, want: []int64{}},
+		{name: "Name with operators", filters: GetRepoListFilters{Name: strPtr("cli)|(@name:*")}, want: []int64{}},
+		{name: "Language with symbols", filters: GetRepoListFilters{Language: strPtr("C++")}, want: []int64{1}},
+		{name: "Language with braces", filters: GetRepoListFilters{Language: strPtr("{Go}")}, want: []int64{}},
+		{name: "Language is exact", filters: GetRepoListFilters{Language: strPtr("go")}, want: []int64{2}},
+		{name: "Language prefix", filters: GetRepoListFilters{Language: strPtr("Gos")}, want: []int64{}},
+		{name: "License with operators", filters: GetRepoListFilters{License: strPtr("-mit | @name")}, want: []int64{}},
+	}
+
+	for _, tt := range tests {
+		tt.filters.SortBy = SortByCreatedAt
+		tt.filters.SortAscending = true
+		page, err := dbService.GetRepoList(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetRepoList() %s error = %v", tt.name, err)
+			return
+		}
+		got := make([]int64, len(page.Items))
+		for i, item := range page.Items {
+			got[i] = item.ID
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetRepoList() %s got = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// getRepoItem_NotFound checks that a missing repo item returns ErrNotFound
+func getRepoItem_NotFound(t *testing.T, dbService Service, testKey string) {
+
+	err := dbService.SetRepoList(context.Background(), entities.RepoList{{ID: 1, Name: "repo1"}})
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	_, err = dbService.GetRepoItem(context.Background(), 2)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetRepoItem() error = %v, want ErrNotFound", err)
+	}
+}
+
+// getRepoList_Fields checks that the requested fields are returned when a sparse fieldset is requested
+func getRepoList_Fields(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{
+			ID: 1, Name: "repo1", FullName: "owner/repo1", Language: "Go", ForksCount: 3,
+			CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			ID: 2, Name: "repo2", FullName: "owner/repo2", Language: "C", ForksCount: 5,
+			CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	err = dbService.SetRepoItemLanguages(context.Background(), 1, entities.Languages{"Go": 100, "Shell": 5})
+	if err != nil {
+		t.Errorf("SetRepoItemLanguages() error = %v", err)
+		return
+	}
+
+	page, err := dbService.GetRepoList(
+		context.Background(), GetRepoListFilters{
+			Fields:        []string{"full_name", "created_at", "languages", "forks_count"},
+			SortBy:        SortByCreatedAt,
+			SortAscending: true,
+		},
+	)
+	if err != nil {
+		t.Errorf("GetRepoList() error = %v", err)
+		return
+	}
+	if len(page.Items) != 2 || page.Total != 2 {
+		t.Errorf("GetRepoList() got %d items of %d, want 2 of 2", len(page.Items), page.Total)
+		return
+	}
+
+	for i, want := range list {
+		got := page.Items[i]
+		if got.ID != want.ID || got.FullName != want.FullName || got.ForksCount != want.ForksCount ||
+			!got.CreatedAt.Equal(want.CreatedAt) {
+			t.Errorf("GetRepoList() item %d got = %+v, want %+v", i, got, want)
+		}
+	}
+	if !reflect.DeepEqual(page.Items[0].Languages, entities.Languages{"Go": 100, "Shell": 5}) {
+		t.Errorf("GetRepoList() languages got = %v", page.Items[0].Languages)
+	}
+}
+
+// getRepoList_Facets checks the facet counts over all the items matching the filters
+func getRepoList_Facets(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", Owner: "alice", Language: "Go", LicenseName: "MIT License"},
+		{ID: 2, Name: "repo2", Owner: "alice", Language: "Go", LicenseName: "Apache License 2.0"},
+		{ID: 3, Name: "repo3", Owner: "bob", Language: "Rust", LicenseName: "MIT License"},
+		{ID: 4, Name: "repo4", Owner: "carol", Language: "", LicenseName: ""},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    entities.Facets
+	}{
+		{
+			name:    "All items",
+			filters: GetRepoListFilters{Facets: FacetFields},
+			want: entities.Facets{
+				"language": {"Go": 2, "Rust": 1},
+				"license":  {"MIT License": 2, "Apache License 2.0": 1},
+				"owner":    {"alice": 2, "bob": 1, "carol": 1},
+			},
+		},
+		{
+			name:    "Filtered and paginated",
+			filters: GetRepoListFilters{License: strPtr("mit"), Limit: 1, Facets: []FacetField{FacetByOwner}},
+			want:    entities.Facets{"owner": {"alice": 1, "bob": 1}},
+		},
+		{
+			name:    "No matches",
+			filters: GetRepoListFilters{Name: strPtr("nothing"), Facets: []FacetField{FacetByLanguage}},
+			want:    entities.Facets{"language": {}},
+		},
+		{
+			name:    "No facets",
+			filters: GetRepoListFilters{},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		page, err := dbService.GetRepoList(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetRepoList() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(page.Facets, tt.want) {
+			t.Errorf("GetRepoList() %s facets got = %v, want %v", tt.name, page.Facets, tt.want)
+		}
+	}
+}
+
+// getRepoList_Text checks that the full-text search matches the stemmed terms in the name and description,
+// and that the items are ranked by relevance with the name weighted above the description
+func getRepoList_Text(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "json-parser", Description: "A fast parser for JSON documents", ForksCount: 3},
+		{ID: 2, Name: "toolkit", Description: "Parsing utilities and a JSON lexer", ForksCount: 2},
+		{ID: 3, Name: "webapp", Description: "A web application", ForksCount: 1},
+		{ID: 4, Name: "parse", Description: "", ForksCount: 0},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    []int64
+	}{
+		{
+			name:    "Stemming, name ranked above description",
+			filters: GetRepoListFilters{Text: strPtr("parsing"), SortBy: SortByRelevance},
+			want:    []int64{4, 2},
+		},
+		{
+			name:    "All the terms",
+			filters: GetRepoListFilters{Text: strPtr("json parses"), SortBy: SortByRelevance},
+			want:    []int64{2},
+		},
+		{
+			name:    "Stop words",
+			filters: GetRepoListFilters{Text: strPtr("the web"), SortBy: SortByRelevance},
+			want:    []int64{3},
+		},
+		{
+			name:    "Sorted by a field",
+			filters: GetRepoListFilters{Text: strPtr("json"), SortBy: SortByForks},
+			want:    []int64{1, 2},
+		},
+		{
+			name:    "Sparse fieldset",
+			filters: GetRepoListFilters{Text: strPtr("parse"), SortBy: SortByRelevance, Fields: []string{"name"}},
+			want:    []int64{4, 2},
+		},
+		{
+			name:    "No matches",
+			filters: GetRepoListFilters{Text: strPtr("compiler")},
+			want:    []int64{},
+		},
+	}
+
+	for _, tt := range tests {
+		page, err := dbService.GetRepoList(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetRepoList() %s error = %v", tt.name, err)
+			return
+		}
+		got := make([]int64, len(page.Items))
+		for i, item := range page.Items {
+			got[i] = item.ID
+			if hit, ok := page.Hits[item.ID]; !ok || hit.Score <= 0 {
+				t.Errorf("GetRepoList() %s hit of %d got = %v, want a positive score", tt.name, item.ID, hit)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetRepoList() %s got = %v, want %v", tt.name, got, tt.want)
+		}
+		if page.Total != len(tt.want) {
+			t.Errorf("GetRepoList() %s total got = %d, want %d", tt.name, page.Total, len(tt.want))
+		}
+	}
+
+	// Sorting by relevance requires a text search
+	_, err = dbService.GetRepoList(context.Background(), GetRepoListFilters{SortBy: SortByRelevance})
+	if !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("GetRepoList() relevance without text error = %v, want ErrInvalidFilter", err)
+	}
+}
+
+// getStatsByLanguage_Filters checks that the aggregates by language are computed over the matching items only
+func getStatsByLanguage_Filters(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", Language: "Go", LicenseName: "MIT License", ForksCount: 2, OpenIssuesCount: 1, Size: 10},
+		{ID: 2, Name: "repo2", Language: "Go", LicenseName: "MIT License", ForksCount: 4, OpenIssuesCount: 0, Size: 20},
+		{ID: 3, Name: "repo3", Language: "Go", LicenseName: "Apache License 2.0", ForksCount: 9, OpenIssuesCount: 3},
+		{ID: 4, Name: "repo4", Language: "Rust", LicenseName: "MIT License", ForksCount: 1, OpenIssuesCount: 5, Size: 6},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	strPtr := func(v string) *string { return &v }
+	boolPtr := func(v bool) *bool { return &v }
+
+	tests := []struct {
+		name          string
+		filters       GetRepoListFilters
+		wantNumRepos  map[string]int
+		wantAvgForks  map[string]float32
+		wantAvgIssues map[string]float32
+		wantAvgSize   map[string]float32
+	}{
+		{
+			name:          "All items",
+			filters:       GetRepoListFilters{},
+			wantNumRepos:  map[string]int{"Go": 3, "Rust": 1},
+			wantAvgForks:  map[string]float32{"Go": 5, "Rust": 1},
+			wantAvgIssues: map[string]float32{"Go": float32(4) / 3, "Rust": 5},
+			wantAvgSize:   map[string]float32{"Go": 10, "Rust": 6},
+		},
+		{
+			name:          "Filtered",
+			filters:       GetRepoListFilters{License: strPtr("mit"), HasOpenIssues: boolPtr(true)},
+			wantNumRepos:  map[string]int{"Go": 1, "Rust": 1},
+			wantAvgForks:  map[string]float32{"Go": 2, "Rust": 1},
+			wantAvgIssues: map[string]float32{"Go": 1, "Rust": 5},
+			wantAvgSize:   map[string]float32{"Go": 10, "Rust": 6},
+		},
+		{
+			name:          "No matches",
+			filters:       GetRepoListFilters{Name: strPtr("nothing")},
+			wantNumRepos:  map[string]int{},
+			wantAvgForks:  map[string]float32{},
+			wantAvgIssues: map[string]float32{},
+			wantAvgSize:   map[string]float32{},
+		},
+	}
+
+	// approxEqual compares the averages, which the backends may round differently
+	approxEqual := func(got, want map[string]float32) bool {
+		if len(got) != len(want) {
+			return false
+		}
+		for k, v := range want {
+			if d := got[k] - v; d > 1e-3 || d < -1e-3 {
+				return false
+			}
+		}
+		return true
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		numRepos, err := dbService.GetNumReposByLanguage(ctx, tt.filters)
+		if err != nil {
+			t.Errorf("GetNumReposByLanguage() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(numRepos, tt.wantNumRepos) {
+			t.Errorf("GetNumReposByLanguage() %s got = %v, want %v", tt.name, numRepos, tt.wantNumRepos)
+		}
+
+		averages := []struct {
+			name string
+			get  func(ctx context.Context, filters GetRepoListFilters) (map[string]float32, error)
+			want map[string]float32
+		}{
+			{"GetAvgNumForksPerRepoByLanguage", dbService.GetAvgNumForksPerRepoByLanguage, tt.wantAvgForks},
+			{"GetAvgNumOpenIssuesByLanguage", dbService.GetAvgNumOpenIssuesByLanguage, tt.wantAvgIssues},
+			{"GetAvgSizeByLanguage", dbService.GetAvgSizeByLanguage, tt.wantAvgSize},
+		}
+		for _, avg := range averages {
+			got, err := avg.get(ctx, tt.filters)
+			if err != nil {
+				t.Errorf("%s() %s error = %v", avg.name, tt.name, err)
+				return
+			}
+			if !approxEqual(got, avg.want) {
+				t.Errorf("%s() %s got = %v, want %v", avg.name, tt.name, got, avg.want)
+			}
+		}
+	}
+}
+
+// aggregate checks that the matching items are grouped by the field and that the metrics are computed per group
+func aggregate(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", LicenseName: "MIT License", ForksCount: 2, Size: 10, AllowForking: true},
+		{ID: 2, Name: "repo2", LicenseName: "MIT License", ForksCount: 4, Size: 30, AllowForking: true},
+		{ID: 3, Name: "repo3", LicenseName: "Apache License 2.0", ForksCount: 9, Size: 5},
+		{ID: 4, Name: "repo4", LicenseName: "", ForksCount: 1, Size: 6, AllowForking: true},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	strPtr := func(v string) *string { return &v }
+	metrics := []AggregateMetric{
+		{Func: AggregateCount},
+		{Func: AggregateAvg, Field: "forks_count"},
+		{Func: AggregateSum, Field: "size"},
+		{Func: AggregateMax, Field: "size"},
+		{Func: AggregateMin, Field: "forks_count"},
+	}
+
+	tests := []struct {
+		name string
+		spec AggregateSpec
+		want []entities.AggregateGroup
+	}{
+		{
+			name: "By license",
+			spec: AggregateSpec{GroupBy: GroupByLicense, Metrics: metrics},
+			want: []entities.AggregateGroup{
+				{
+					Value: "Apache License 2.0",
+					Metrics: map[string]float64{
+						"count": 1, "avg:forks_count": 9, "sum:size": 5, "max:size": 5, "min:forks_count": 9,
+					},
+				},
+				{
+					Value: "MIT License",
+					Metrics: map[string]float64{
+						"count": 2, "avg:forks_count": 3, "sum:size": 40, "max:size": 30, "min:forks_count": 2,
+					},
+				},
+			},
+		},
+		{
+			name: "By boolean, filtered",
+			spec: AggregateSpec{
+				Filters: GetRepoListFilters{License: strPtr("mit")},
+				GroupBy: GroupByAllowForking,
+				Metrics: []AggregateMetric{{Func: AggregateCount}},
+			},
+			want: []entities.AggregateGroup{{Value: "true", Metrics: map[string]float64{"count": 2}}},
+		},
+		{
+			name: "No matches",
+			spec: AggregateSpec{
+				Filters: GetRepoListFilters{Name: strPtr("nothing")},
+				GroupBy: GroupByOwner,
+				Metrics: []AggregateMetric{{Func: AggregateCount}},
+			},
+			want: []entities.AggregateGroup{},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.Aggregate(context.Background(), tt.spec)
+		if err != nil {
+			t.Errorf("Aggregate() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Aggregate() %s\ngot =  %v\nwant = %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Invalid specs
+	invalid := []AggregateSpec{
+		{GroupBy: "name", Metrics: metrics},
+		{GroupBy: GroupByLicense},
+		{GroupBy: GroupByLicense, Metrics: []AggregateMetric{{Func: AggregateAvg, Field: "name"}}},
+		{GroupBy: GroupByLicense, Metrics: []AggregateMetric{{Func: AggregateCount, Field: "size"}}},
+	}
+	for _, spec := range invalid {
+		if _, err := dbService.Aggregate(context.Background(), spec); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("Aggregate(%v) error = %v, want ErrInvalidFilter", spec, err)
+		}
+	}
+}
+
+// getLanguageStats checks the statistics of the languages breakdowns of the matching items
+func getLanguageStats(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", LicenseName: "MIT License", Languages: entities.Languages{"Go": 300, "Shell": 100}},
+		{ID: 2, Name: "repo2", LicenseName: "MIT License", Languages: entities.Languages{"Go": 100}},
+		{ID: 3, Name: "repo3", LicenseName: "Apache License 2.0", Languages: entities.Languages{"Shell": 500}},
+		{ID: 4, Name: "repo4", LicenseName: "MIT License"},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    entities.LanguageStats
+	}{
+		{
+			name:    "All items",
+			filters: GetRepoListFilters{},
+			want: entities.LanguageStats{
+				TotalBytes: 1000,
+				Repos:      3,
+				Languages: []entities.LanguageStat{
+					{Language: "Shell", Bytes: 600, Share: 0.6, Repos: 2, MeanShare: 0.625},
+					{Language: "Go", Bytes: 400, Share: 0.4, Repos: 2, MeanShare: 0.875},
+				},
+			},
+		},
+		{
+			name:    "Filtered",
+			filters: GetRepoListFilters{License: strPtr("mit")},
+			want: entities.LanguageStats{
+				TotalBytes: 500,
+				Repos:      2,
+				Languages: []entities.LanguageStat{
+					{Language: "Go", Bytes: 400, Share: 0.8, Repos: 2, MeanShare: 0.875},
+					{Language: "Shell", Bytes: 100, Share: 0.2, Repos: 1, MeanShare: 0.25},
+				},
+			},
+		},
+		{
+			name:    "No matches",
+			filters: GetRepoListFilters{Name: strPtr("nothing")},
+			want:    entities.LanguageStats{Languages: []entities.LanguageStat{}},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.GetLanguageStats(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetLanguageStats() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetLanguageStats() %s\ngot =  %+v\nwant = %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// getLanguageCooccurrence checks the pairs of languages used together by the matching items
+func getLanguageCooccurrence(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", LicenseName: "MIT License", Languages: entities.Languages{"Go": 900, "Shell": 100}},
+		{ID: 2, Name: "repo2", LicenseName: "MIT License", Languages: entities.Languages{"Go": 500, "Shell": 20}},
+		{ID: 3, Name: "repo3", LicenseName: "MIT License", Languages: entities.Languages{"CSS": 50, "Go": 10}},
+		{ID: 4, Name: "repo4", LicenseName: "Apache License 2.0", Languages: entities.Languages{"Shell": 500}},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name     string
+		filters  GetRepoListFilters
+		minBytes int64
+		want     entities.LanguageCooccurrence
+	}{
+		{
+			name: "All items",
+			want: entities.LanguageCooccurrence{
+				Repos: 4,
+				Pairs: []entities.LanguagePair{
+					{A: "Go", B: "Shell", Count: 2, Lift: 8.0 / 9, Jaccard: 0.5},
+					{A: "CSS", B: "Go", Count: 1, Lift: 4.0 / 3, Jaccard: 1.0 / 3},
+				},
+			},
+		},
+		{
+			name:     "Minimum bytes",
+			minBytes: 50,
+			want: entities.LanguageCooccurrence{
+				Repos: 4,
+				Pairs: []entities.LanguagePair{{A: "Go", B: "Shell", Count: 1, Lift: 1, Jaccard: 1.0 / 3}},
+			},
+		},
+		{
+			name:    "Filtered",
+			filters: GetRepoListFilters{License: strPtr("mit")},
+			want: entities.LanguageCooccurrence{
+				Repos: 3,
+				Pairs: []entities.LanguagePair{
+					{A: "Go", B: "Shell", Count: 2, Lift: 1, Jaccard: 2.0 / 3},
+					{A: "CSS", B: "Go", Count: 1, Lift: 1, Jaccard: 1.0 / 3},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.GetLanguageCooccurrence(context.Background(), tt.filters, tt.minBytes)
+		if err != nil {
+			t.Errorf("GetLanguageCooccurrence() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetLanguageCooccurrence() %s\ngot =  %+v\nwant = %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// getDistributions checks the percentiles and histograms by language and the created_at histogram
+func getDistributions(t *testing.T, dbService Service, testKey string) {
+
+	day := func(d, h int) time.Time { return time.Date(2023, 1, d, h, 0, 0, 0, time.UTC) }
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", Language: "Go", LicenseName: "MIT License", Size: 5, CreatedAt: day(1, 1)},
+		{ID: 2, Name: "repo2", Language: "Go", LicenseName: "MIT License", Size: 50, CreatedAt: day(1, 5)},
+		{ID: 3, Name: "repo3", Language: "Go", LicenseName: "Apache License 2.0", Size: 500, CreatedAt: day(2, 1)},
+		{ID: 4, Name: "repo4", Language: "Rust", LicenseName: "MIT License", Size: 20, CreatedAt: day(3, 1)},
+		{ID: 5, Name: "repo5", Language: "", LicenseName: "MIT License", Size: 7, CreatedAt: day(3, 2)},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	intPtr := func(v int) *int { return &v }
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name string
+		spec DistributionSpec
+		want entities.Distributions
+	}{
+		{
+			name: "Percentiles, histogram and created_at",
+			spec: DistributionSpec{Fields: []string{"size"}, Buckets: []int{0, 10, 100}, CreatedInterval: IntervalDay},
+			want: entities.Distributions{
+				ByLanguage: map[string]map[string]entities.Distribution{
+					"Go": {
+						"size": {
+							Count: 3, Min: 5, Max: 500, Median: 50, P90: 500, P99: 500,
+							Histogram: []entities.HistogramBucket{
+								{Min: 0, Max: intPtr(10), Count: 1},
+								{Min: 10, Max: intPtr(100), Count: 1},
+								{Min: 100, Count: 1},
+							},
+						},
+					},
+					"Rust": {
+						"size": {
+							Count: 1, Min: 20, Max: 20, Median: 20, P90: 20, P99: 20,
+							Histogram: []entities.HistogramBucket{
+								{Min: 0, Max: intPtr(10), Count: 0},
+								{Min: 10, Max: intPtr(100), Count: 1},
+								{Min: 100, Count: 0},
+							},
+						},
+					},
+				},
+				CreatedAt: []entities.TimeBucket{
+					{Start: day(1, 0), Count: 2}, {Start: day(2, 0), Count: 1}, {Start: day(3, 0), Count: 2},
+				},
+			},
+		},
+		{
+			name: "Filtered, without histograms",
+			spec: DistributionSpec{Filters: GetRepoListFilters{License: strPtr("mit")}, Fields: []string{"forks_count"}},
+			want: entities.Distributions{
+				ByLanguage: map[string]map[string]entities.Distribution{
+					"Go":   {"forks_count": {Count: 2}},
+					"Rust": {"forks_count": {Count: 1}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.GetDistributions(context.Background(), tt.spec)
+		if err != nil {
+			t.Errorf("GetDistributions() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetDistributions() %s\ngot =  %+v\nwant = %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// Invalid specs
+	invalid := []DistributionSpec{
+		{Fields: []string{"name"}},
+		{Fields: []string{"size"}, Buckets: []int{10, 10}},
+		{Fields: []string{"size"}, CreatedInterval: "week"},
+	}
+	for _, spec := range invalid {
+		if _, err := dbService.GetDistributions(context.Background(), spec); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("GetDistributions(%+v) error = %v, want ErrInvalidFilter", spec, err)
+		}
+	}
+}
+
+func getStatsHistory(t *testing.T, dbService Service, testKey string) {
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	point := func(minutes int, value float64) entities.HistoryPoint {
+		return entities.HistoryPoint{Time: at(minutes), Value: value}
+	}
+	snapshot := func(goRepos int, goForks float32) entities.Stats {
+		return entities.Stats{
+			NumReposByLanguage:           map[string]int{"Go": goRepos, "Rust": 1},
+			AvgNumForksPerRepoByLanguage: map[string]float32{"Go": goForks, "Rust": 0},
+			AvgNumOpenIssuesByLanguage:   map[string]float32{"Go": 0, "Rust": 0},
+			AvgSizeByLanguage:            map[string]float32{"Go": 0, "Rust": 0},
+		}
+	}
+
+	// A snapshot every 10 minutes, then one after the retention of the first
+	snapshots := []struct {
+		at    time.Time
+		stats entities.Stats
+	}{
+		{at(0), snapshot(2, 1)},
+		{at(10), snapshot(4, 1.5)},
+		{at(20), snapshot(6, 2)},
+		{at(60), snapshot(8, 2.5)},
+	}
+	for _, s := range snapshots {
+		if err := dbService.RecordStatsSnapshot(context.Background(), s.at, s.stats); err != nil {
+			t.Errorf("RecordStatsSnapshot() error = %v", err)
+			return
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query HistoryQuery
+		want  []entities.HistoryPoint
+	}{
+		{
+			name:  "Recorded points",
+			query: HistoryQuery{Metric: HistoryNumRepos, Language: "Go", From: at(0), To: at(60)},
+			want:  []entities.HistoryPoint{point(0, 2), point(10, 4), point(20, 6), point(60, 8)},
+		},
+		{
+			name:  "Time range",
+			query: HistoryQuery{Metric: HistoryAvgForks, Language: "Go", From: at(5), To: at(20)},
+			want:  []entities.HistoryPoint{point(10, 1.5), point(20, 2)},
+		},
+		{
+			name:  "Downsampled",
+			query: HistoryQuery{Metric: HistoryNumRepos, Language: "Go", From: at(0), To: at(60), Step: 30 * time.Minute},
+			want:  []entities.HistoryPoint{point(0, 4), point(60, 8)},
+		},
+		{
+			name:  "Unknown language",
+			query: HistoryQuery{Metric: HistoryNumRepos, Language: "Java", From: at(0), To: at(60)},
+			want:  []entities.HistoryPoint{},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.GetStatsHistory(context.Background(), tt.query)
+		if err != nil {
+			t.Errorf("GetStatsHistory() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetStatsHistory() %s\ngot =  %+v\nwant = %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// The snapshots older than the retention are dropped
+	err := dbService.RecordStatsSnapshot(context.Background(), at(5).Add(HistoryRetention), snapshot(10, 3))
+	if err != nil {
+		t.Errorf("RecordStatsSnapshot() error = %v", err)
+		return
+	}
+	got, err := dbService.GetStatsHistory(
+		context.Background(),
+		HistoryQuery{Metric: HistoryNumRepos, Language: "Go", From: at(0), To: at(30)},
+	)
+	if err != nil {
+		t.Errorf("GetStatsHistory() error = %v", err)
+		return
+	}
+	if want := []entities.HistoryPoint{point(10, 4), point(20, 6)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetStatsHistory() after retention\ngot =  %+v\nwant = %+v", got, want)
+	}
+
+	// Invalid queries
+	invalid := []HistoryQuery{
+		{Metric: "stars", Language: "Go", From: at(0), To: at(60)},
+		{Metric: HistoryNumRepos, Language: "Go", From: at(60), To: at(0)},
+		{Metric: HistoryNumRepos, Language: "Go", From: at(0), To: at(60), Step: time.Millisecond},
+	}
+	for _, query := range invalid {
+		if _, err := dbService.GetStatsHistory(context.Background(), query); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("GetStatsHistory(%+v) error = %v, want ErrInvalidFilter", query, err)
+		}
+	}
+}
+
+func getLeaderboard(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", FullName: "a/repo1", Owner: "a", Language: "Go", Description: "d1", ForksCount: 5, Size: 10},
+		{ID: 2, Name: "repo2", FullName: "b/repo2", Owner: "b", Language: "Go", Description: "d2", ForksCount: 9, Size: 30},
+		{ID: 3, Name: "repo3", FullName: "c/repo3", Owner: "c", Language: "Rust", ForksCount: 7, Size: 20},
+		{ID: 4, Name: "repo4", FullName: "d/repo4", Owner: "d", Language: "Go", ForksCount: 5, Size: 5},
+	}
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	for id, langs := range map[int64]entities.Languages{1: {"Go": 10, "Shell": 2, "Makefile": 1}, 3: {"Rust": 10}} {
+		if err := dbService.SetRepoItemLanguages(context.Background(), id, langs); err != nil {
+			t.Errorf("SetRepoItemLanguages() error = %v", err)
+			return
+		}
+	}
+
+	// entry returns the leaderboard entry of an item of the list (only the display fields are returned)
+	entry := func(i int, value int) entities.LeaderboardEntry {
+		item := list[i-1]
+		return entities.LeaderboardEntry{
+			Item: entities.RepoItem{
+				ID: item.ID, Name: item.Name, FullName: item.FullName, Owner: item.Owner, Language: item.Language,
+			},
+			Value: value,
+		}
+	}
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name  string
+		query LeaderboardQuery
+		want  []entities.LeaderboardEntry
+	}{
+		{
+			name:  "Forks, ties by descending ID",
+			query: LeaderboardQuery{Metric: LeaderboardForks, Limit: 10},
+			want:  []entities.LeaderboardEntry{entry(2, 9), entry(3, 7), entry(4, 5), entry(1, 5)},
+		},
+		{
+			name:  "Size, limited",
+			query: LeaderboardQuery{Metric: LeaderboardSize, Limit: 2},
+			want:  []entities.LeaderboardEntry{entry(2, 30), entry(3, 20)},
+		},
+		{
+			name:  "Language count, by primary language (case-insensitive)",
+			query: LeaderboardQuery{Metric: LeaderboardLanguageCount, Language: strPtr("go"), Limit: 10},
+			want:  []entities.LeaderboardEntry{entry(1, 3), entry(4, 0), entry(2, 0)},
+		},
+		{
+			name:  "Unknown language",
+			query: LeaderboardQuery{Metric: LeaderboardWatchers, Language: strPtr("Java"), Limit: 10},
+			want:  []entities.LeaderboardEntry{},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.GetLeaderboard(context.Background(), tt.query)
+		if err != nil {
+			t.Errorf("GetLeaderboard() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetLeaderboard() %s\ngot =  %+v\nwant = %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// The leaderboards follow the new list
+	err = dbService.SetRepoList(context.Background(), list[2:])
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	got, err := dbService.GetLeaderboard(
+		context.Background(), LeaderboardQuery{Metric: LeaderboardForks, Language: strPtr("Go"), Limit: 10},
+	)
+	if err != nil {
+		t.Errorf("GetLeaderboard() error = %v", err)
+		return
+	}
+	if want := []entities.LeaderboardEntry{entry(4, 5)}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetLeaderboard() after SetRepoList\ngot =  %+v\nwant = %+v", got, want)
+	}
+
+	// Invalid queries
+	invalid := []LeaderboardQuery{
+		{Metric: "stars", Limit: 10},
+		{Metric: LeaderboardForks, Limit: 0},
+		{Metric: LeaderboardForks, Limit: MaxLeaderboardLimit + 1},
+	}
+	for _, query := range invalid {
+		if _, err := dbService.GetLeaderboard(context.Background(), query); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("GetLeaderboard(%+v) error = %v, want ErrInvalidFilter", query, err)
+		}
+	}
+}
+
+func getLeaderboard_DerivedLanguage(t *testing.T, dbService Service, testKey string) {
+
+	// The item has no language: its primary language is derived from its breakdown
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", FullName: "a/repo1", Owner: "a", ForksCount: 5, WatchersCount: 3, Size: 10, OpenIssuesCount: 2},
+		{ID: 2, Name: "repo2", FullName: "b/repo2", Owner: "b", Language: "Go", ForksCount: 1},
+	}
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	// ids returns the IDs of the entries of every metric board of a language
+	ids := func(language string) map[LeaderboardMetric][]int64 {
+		out := map[LeaderboardMetric][]int64{}
+		for _, metric := range LeaderboardMetrics {
+			got, err := dbService.GetLeaderboard(
+				context.Background(), LeaderboardQuery{Metric: metric, Language: &language, Limit: 10},
+			)
+			if err != nil {
+				t.Errorf("GetLeaderboard() %s %s error = %v", metric, language, err)
+				return nil
+			}
+			out[metric] = []int64{}
+			for _, entry := range got {
+				out[metric] = append(out[metric], entry.Item.ID)
+			}
+		}
+		return out
+	}
+	// every returns the same IDs for every metric board
+	every := func(v ...int64) map[LeaderboardMetric][]int64 {
+		out := map[LeaderboardMetric][]int64{}
+		for _, metric := range LeaderboardMetrics {
+			out[metric] = append([]int64{}, v...)
+		}
+		return out
+	}
+
+	steps := []struct {
+		name      string
+		languages entities.Languages
+		want      map[string]map[LeaderboardMetric][]int64
+	}{
+		{
+			name:      "Derived language",
+			languages: entities.Languages{"Go": 100, "Rust": 10},
+			want:      map[string]map[LeaderboardMetric][]int64{"go": every(1, 2), "rust": every()},
+		},
+		{
+			name:      "Derived language changed",
+			languages: entities.Languages{"Go": 10, "Rust": 100},
+			want:      map[string]map[LeaderboardMetric][]int64{"go": every(2), "rust": every(1)},
+		},
+	}
+	for _, step := range steps {
+		if err := dbService.SetRepoItemLanguages(context.Background(), 1, step.languages); err != nil {
+			t.Errorf("SetRepoItemLanguages() %s error = %v", step.name, err)
+			return
+		}
+		for language, want := range step.want {
+			if got := ids(language); !reflect.DeepEqual(got, want) {
+				t.Errorf("GetLeaderboard() %s %s\ngot =  %v\nwant = %v", step.name, language, got, want)
+			}
+		}
+	}
+}
+
+func getOwners(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", Owner: "alice", Language: "Go", Size: 10},
+		{ID: 2, Name: "repo2", Owner: "alice", Language: "Go", Size: 20},
+		{ID: 3, Name: "repo3", Owner: "bob", Language: "Rust", Size: 50},
+		{ID: 4, Name: "repo4", Owner: "alicia", Language: "C", Size: 5},
+		{ID: 5, Name: "repo5", Owner: "carol", Language: "", Size: 5},
+	}
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+
+	// alice writes more Shell than Go, in a single repository
+	err = dbService.SetRepoItemLanguages(context.Background(), 2, entities.Languages{"Go": 10, "Shell": 30})
+	if err != nil {
+		t.Errorf("SetRepoItemLanguages() error = %v", err)
+		return
+	}
+	strPtr := func(v string) *string { return &v }
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    []entities.OwnerSummary
+	}{
+		{
+			name: "All owners",
+			want: []entities.OwnerSummary{
+				{Login: "alice", Repos: 2, TotalSize: 30, DominantLanguage: "Shell"},
+				{Login: "bob", Repos: 1, TotalSize: 50, DominantLanguage: "Rust"},
+				{Login: "alicia", Repos: 1, TotalSize: 5, DominantLanguage: "C"},
+				{Login: "carol", Repos: 1, TotalSize: 5},
+			},
+		},
+		{
+			name:    "Exact owner (case-insensitive)",
+			filters: GetRepoListFilters{Owner: strPtr("ALICE")},
+			want:    []entities.OwnerSummary{{Login: "alice", Repos: 2, TotalSize: 30, DominantLanguage: "Shell"}},
+		},
+		{
+			name:    "Filtered",
+			filters: GetRepoListFilters{Size: IntRange{Min: intPtr(20)}},
+			want: []entities.OwnerSummary{
+				{Login: "bob", Repos: 1, TotalSize: 50, DominantLanguage: "Rust"},
+				{Login: "alice", Repos: 1, TotalSize: 20, DominantLanguage: "Shell"},
+			},
+		},
+		{
+			name:    "Unknown owner",
+			filters: GetRepoListFilters{Owner: strPtr("ali")},
+			want:    []entities.OwnerSummary{},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.GetOwners(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetOwners() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetOwners() %s\ngot =  %+v\nwant = %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// The owner filter applies to the repository list too
+	page, err := dbService.GetRepoList(context.Background(), GetRepoListFilters{Owner: strPtr("Alice")})
+	if err != nil {
+		t.Errorf("GetRepoList() error = %v", err)
+		return
+	}
+	if len(page.Items) != 2 || page.Items[0].Owner != "alice" || page.Items[1].Owner != "alice" {
+		t.Errorf("GetRepoList() owner filter got = %+v", page.Items)
+	}
+}
+
+func getLanguages(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", Language: "Go", Size: 10, WatchersCount: 3},
+		{ID: 2, Name: "repo2", Language: "Go", Size: 30, WatchersCount: 8},
+		{ID: 3, Name: "repo3", Language: "Rust", Size: 20, WatchersCount: 8},
+		{ID: 4, Name: "repo4", Language: "", Size: 5},
+	}
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	breakdowns := map[int64]entities.Languages{
+		1: {"Go": 100, "Shell": 10},
+		3: {"Rust": 200, "Shell": 30, "Go": 5},
+	}
+	for id, langs := range breakdowns {
+		if err := dbService.SetRepoItemLanguages(context.Background(), id, langs); err != nil {
+			t.Errorf("SetRepoItemLanguages() error = %v", err)
+			return
+		}
+	}
+
+	// The summaries of all the languages, then of the languages of the small repositories
+	intPtr := func(v int) *int { return &v }
+	summaries := []struct {
+		filters GetRepoListFilters
+		want    []entities.LanguageSummary
+	}{
+		{
+			want: []entities.LanguageSummary{
+				{Name: "Go", PrimaryRepos: 2, Repos: 3, Bytes: 105, AvgSize: 20},
+				{Name: "Shell", PrimaryRepos: 0, Repos: 2, Bytes: 40, AvgSize: 15},
+				{Name: "Rust", PrimaryRepos: 1, Repos: 1, Bytes: 200, AvgSize: 20},
+			},
+		},
+		{
+			filters: GetRepoListFilters{Size: IntRange{Max: intPtr(10)}},
+			want: []entities.LanguageSummary{
+				{Name: "Go", PrimaryRepos: 1, Repos: 1, Bytes: 100, AvgSize: 10},
+				{Name: "Shell", PrimaryRepos: 0, Repos: 1, Bytes: 10, AvgSize: 10},
+			},
+		},
+	}
+	for _, tt := range summaries {
+		got, err := dbService.GetLanguages(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetLanguages() error = %v", err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetLanguages(%+v)\ngot =  %+v\nwant = %+v", tt.filters, got, tt.want)
+		}
+	}
+
+	// The detail of a language, matched exactly and case-insensitively
+	got, err := dbService.GetLanguage(context.Background(), "go", 2)
+	if err != nil {
+		t.Errorf("GetLanguage() error = %v", err)
+		return
+	}
+	wantSummary := entities.LanguageSummary{Name: "Go", PrimaryRepos: 2, Repos: 3, Bytes: 105, AvgSize: 20}
+	if got.Summary != wantSummary {
+		t.Errorf("GetLanguage() summary\ngot =  %+v\nwant = %+v", got.Summary, wantSummary)
+	}
+	var gotIDs []int64
+	for _, item := range got.TopRepos {
+		gotIDs = append(gotIDs, item.ID)
+	}
+	if want := []int64{3, 2}; !reflect.DeepEqual(gotIDs, want) {
+		t.Errorf("GetLanguage() top repos = %v, want %v", gotIDs, want)
+	}
+
+	for _, name := range []string{"G", "Java"} {
+		if _, err := dbService.GetLanguage(context.Background(), name, 2); !errors.Is(err, ErrNotFound) {
+			t.Errorf("GetLanguage(%q) error = %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+// getLicenseStats checks the license counts and the exact license key and family filters
+func getLicenseStats(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", LicenseKey: "mit", LicenseName: "MIT License"},
+		{ID: 2, Name: "repo2", LicenseKey: "mit", LicenseName: "MIT License"},
+		{ID: 3, Name: "repo3", LicenseKey: "gpl-3.0", LicenseName: "GNU General Public License v3.0"},
+		{ID: 4, Name: "repo4", LicenseKey: "lgpl-3.0", LicenseName: "GNU Lesser General Public License v3.0"},
+		{ID: 5, Name: "repo5", LicenseKey: "apache-2.0", LicenseName: "Apache License 2.0"},
+		{ID: 6, Name: "repo6", LicenseKey: "other", LicenseName: "Other"},
+		{ID: 7, Name: "repo7"},
+	}
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	strPtr := func(v string) *string { return &v }
+	families := func(permissive, weak, strong, other, none int) []entities.FamilyCount {
+		return []entities.FamilyCount{
+			{Family: "permissive", Count: permissive},
+			{Family: "weak-copyleft", Count: weak},
+			{Family: "strong-copyleft", Count: strong},
+			{Family: "other", Count: other},
+			{Family: "none", Count: none},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    entities.LicenseStats
+	}{
+		{
+			name: "All licenses",
+			want: entities.LicenseStats{
+				Total: 7,
+				Licenses: []entities.LicenseCount{
+					{Key: "mit", Name: "MIT License", Family: "permissive", Count: 2},
+					{Key: "apache-2.0", Name: "Apache License 2.0", Family: "permissive", Count: 1},
+					{Key: "gpl-3.0", Name: "GNU General Public License v3.0", Family: "strong-copyleft", Count: 1},
+					{Key: "lgpl-3.0", Name: "GNU Lesser General Public License v3.0", Family: "weak-copyleft", Count: 1},
+					{Key: "other", Name: "Other", Family: "other", Count: 1},
+				},
+				Families: families(3, 1, 1, 1, 1),
+			},
+		},
+		{
+			name:    "Exact license key",
+			filters: GetRepoListFilters{LicenseKey: strPtr("GPL-3.0")},
+			want: entities.LicenseStats{
+				Total: 1,
+				Licenses: []entities.LicenseCount{
+					{Key: "gpl-3.0", Name: "GNU General Public License v3.0", Family: "strong-copyleft", Count: 1},
+				},
+				Families: families(0, 0, 1, 0, 0),
+			},
+		},
+		{
+			name:    "License family",
+			filters: GetRepoListFilters{LicenseFamily: strPtr("permissive")},
+			want: entities.LicenseStats{
+				Total: 3,
+				Licenses: []entities.LicenseCount{
+					{Key: "mit", Name: "MIT License", Family: "permissive", Count: 2},
+					{Key: "apache-2.0", Name: "Apache License 2.0", Family: "permissive", Count: 1},
+				},
+				Families: families(3, 0, 0, 0, 0),
+			},
+		},
+		{
+			name:    "No license",
+			filters: GetRepoListFilters{LicenseFamily: strPtr("none")},
+			want:    entities.LicenseStats{Total: 1, Licenses: []entities.LicenseCount{}, Families: families(0, 0, 0, 0, 1)},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.GetLicenseStats(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetLicenseStats() %s error = %v", tt.name, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetLicenseStats() %s\ngot =  %+v\nwant = %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// The key filter is exact: gpl does not match the LGPL nor the GPL
+	page, err := dbService.GetRepoList(context.Background(), GetRepoListFilters{LicenseKey: strPtr("gpl")})
+	if err != nil {
+		t.Errorf("GetRepoList() error = %v", err)
+		return
+	}
+	if len(page.Items) != 0 {
+		t.Errorf("GetRepoList() license key filter got = %+v, want no items", page.Items)
+	}
+
+	// The key is kept through the db
+	item, err := dbService.GetRepoItem(context.Background(), 5)
+	if err != nil {
+		t.Errorf("GetRepoItem() error = %v", err)
+		return
+	}
+	if item.LicenseKey != "apache-2.0" {
+		t.Errorf("GetRepoItem() LicenseKey = %q, want %q", item.LicenseKey, "apache-2.0")
+	}
+}
+
+// setRepoItemLanguages_DerivedFields checks the fields derived from the languages breakdowns, their filters, and that
+// they are kept when the repository list is stored again
+func setRepoItemLanguages_DerivedFields(t *testing.T, dbService Service, testKey string) {
+
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", Language: "", CreatedAt: day(1)},
+		{ID: 2, Name: "repo2", Language: "Go", CreatedAt: day(2)},
+		{ID: 3, Name: "repo3", Language: "Rust", CreatedAt: day(3)},
+		{ID: 4, Name: "repo4", Language: "", CreatedAt: day(4)},
+	}
+	breakdowns := map[int64]entities.Languages{
+		1: {"Go": 10, "Shell": 30},
+		2: {"Go": 50},
+		3: {"Rust": 5, "C": 5, "Shell": 1},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	for id, langs := range breakdowns {
+		err = dbService.SetRepoItemLanguages(context.Background(), id, langs)
+		if err != nil {
+			t.Errorf("SetRepoItemLanguages() error = %v", err)
+			return
+		}
+	}
+
+	// The primary language falls back to the dominant language, ties are broken by name, and is flagged as derived
+	type derived struct {
+		language, dominantLanguage string
+		languageCount              int
+		languageDerived            bool
+	}
+	want := map[int64]derived{
+		1: {language: "Shell", dominantLanguage: "Shell", languageCount: 2, languageDerived: true},
+		2: {language: "Go", dominantLanguage: "Go", languageCount: 1},
+		3: {language: "Rust", dominantLanguage: "C", languageCount: 3},
+		4: {},
+	}
+	check := func(step string) {
+		for id, fields := range want {
+			item, err := dbService.GetRepoItem(context.Background(), id)
+			if err != nil {
+				t.Errorf("GetRepoItem() %s error = %v", step, err)
+				return
+			}
+			got := derived{item.Language, item.DominantLanguage, item.LanguageCount, item.LanguageDerived}
+			if got != fields {
+				t.Errorf("GetRepoItem(%d) %s\ngot =  %+v\nwant = %+v", id, step, got, fields)
+			}
+		}
+	}
+	check("after SetRepoItemLanguages")
+
+	strPtr := func(v string) *string { return &v }
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    []int64
+	}{
+		{name: "Min count", filters: GetRepoListFilters{LanguageCount: IntRange{Min: intPtr(2)}}, want: []int64{1, 3}},
+		{name: "Max count", filters: GetRepoListFilters{LanguageCount: IntRange{Max: intPtr(0)}}, want: []int64{4}},
+		{name: "Dominant language", filters: GetRepoListFilters{DominantLanguage: strPtr("shell")}, want: []int64{1}},
+	}
+	for _, tt := range tests {
+		tt.filters.SortBy = SortByCreatedAt
+		tt.filters.SortAscending = true
+		page, err := dbService.GetRepoList(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetRepoList() %s error = %v", tt.name, err)
+			return
+		}
+		got := make([]int64, len(page.Items))
+		for i, item := range page.Items {
+			got[i] = item.ID
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetRepoList() %s got = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// The next cycle stores the list again, still without primary language for repo1
+	err = dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	check("after SetRepoList")
+
+	// The derived primary language follows the breakdown, the languages reported by GitHub are kept
+	for id, langs := range map[int64]entities.Languages{1: {"Go": 40, "Shell": 30}, 3: {"C": 1, "Rust": 2}} {
+		err = dbService.SetRepoItemLanguages(context.Background(), id, langs)
+		if err != nil {
+			t.Errorf("SetRepoItemLanguages() error = %v", err)
+			return
+		}
+	}
+	want[1] = derived{language: "Go", dominantLanguage: "Go", languageCount: 2, languageDerived: true}
+	want[3] = derived{language: "Rust", dominantLanguage: "Rust", languageCount: 2}
+	check("after the breakdowns changed")
+
+	// GitHub now reports a primary language for repo1
+	list[0].Language = "Python"
+	err = dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	want[1] = derived{language: "Python", dominantLanguage: "Go", languageCount: 2}
+	check("after GitHub reported a language")
+}
+
+// getSimilarRepos checks the ranking of the similar items by language, license and size, and that the vector index
+// follows the languages breakdowns and the deleted items
+func getSimilarRepos(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "repo1", LicenseKey: "mit", Size: 100},
+		{ID: 2, Name: "repo2", LicenseKey: "mit", Size: 100},
+		{ID: 3, Name: "repo3", LicenseKey: "gpl-3.0", Size: 100},
+		{ID: 4, Name: "repo4", LicenseKey: "mit", Size: 5000},
+		{ID: 5, Name: "repo5", LicenseKey: "mit", Size: 100},
+		{ID: 6, Name: "repo6", LicenseKey: "gpl-3.0", Size: 5000},
+	}
+	breakdowns := map[int64]entities.Languages{
+		1: {"Go": 80, "Shell": 20},
+		2: {"Go": 90, "Shell": 10},
+		3: {"Go": 10, "Shell": 90},
+		4: {"Python": 100},
+		6: {"Go": 100},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	for id, langs := range breakdowns {
+		err = dbService.SetRepoItemLanguages(context.Background(), id, langs)
+		if err != nil {
+			t.Errorf("SetRepoItemLanguages() error = %v", err)
+			return
+		}
+	}
+
+	similar := func(query SimilarQuery) ([]int64, []entities.SimilarRepo) {
+		repos, err := dbService.GetSimilarRepos(context.Background(), query)
+		if err != nil {
+			t.Errorf("GetSimilarRepos(%+v) error = %v", query, err)
+			return nil, nil
+		}
+		ids := make([]int64, len(repos))
+		for i, repo := range repos {
+			ids[i] = repo.Item.ID
+		}
+		return ids, repos
+	}
+
+	// The items without languages breakdown (repo5) and the repository itself are never similar
+	tests := []struct {
+		name  string
+		query SimilarQuery
+		want  []int64
+	}{
+		{name: "Languages", query: SimilarQuery{ID: 1, Limit: 10}, want: []int64{2, 6, 3, 4}},
+		{name: "Limit", query: SimilarQuery{ID: 1, Limit: 2}, want: []int64{2, 6}},
+		{name: "License", query: SimilarQuery{ID: 1, Limit: 10, LicenseWeight: 0.5}, want: []int64{2, 4, 6, 3}},
+		{name: "Size", query: SimilarQuery{ID: 1, Limit: 10, SizeWeight: 0.5}, want: []int64{2, 3, 6, 4}},
+		{name: "No languages", query: SimilarQuery{ID: 5, Limit: 10}, want: []int64{}},
+	}
+	for _, tt := range tests {
+		got, _ := similar(tt.query)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetSimilarRepos() %s got = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// The scores are those of the languages alone without weights
+	_, repos := similar(SimilarQuery{ID: 1, Limit: 1})
+	if len(repos) != 1 || math.Abs(repos[0].Score-0.991) > 0.001 || repos[0].Score != repos[0].LanguageSimilarity {
+		t.Errorf("GetSimilarRepos() scores got = %+v, want a score and a language similarity of 0.991", repos)
+	}
+
+	// Errors
+	_, err = dbService.GetSimilarRepos(context.Background(), SimilarQuery{ID: 99, Limit: 10})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetSimilarRepos() unknown repository error = %v, want ErrNotFound", err)
+	}
+	_, err = dbService.GetSimilarRepos(
+		context.Background(), SimilarQuery{ID: 1, Limit: 10, LicenseWeight: 0.6, SizeWeight: 0.6},
+	)
+	if !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("GetSimilarRepos() invalid weights error = %v, want ErrInvalidFilter", err)
+	}
+
+	// The vectors follow the new breakdowns: repo3 now has the breakdown of repo2 (ties by descending ID)
+	err = dbService.SetRepoItemLanguages(context.Background(), 3, breakdowns[2])
+	if err != nil {
+		t.Errorf("SetRepoItemLanguages() error = %v", err)
+		return
+	}
+	if got, _ := similar(SimilarQuery{ID: 1, Limit: 10}); !reflect.DeepEqual(got, []int64{3, 2, 6, 4}) {
+		t.Errorf("GetSimilarRepos() after SetRepoItemLanguages got = %v, want %v", got, []int64{3, 2, 6, 4})
+	}
+
+	// The items deleted from the list are removed from the vectors
+	err = dbService.SetRepoList(context.Background(), list[:5])
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	if got, _ := similar(SimilarQuery{ID: 1, Limit: 10}); !reflect.DeepEqual(got, []int64{3, 2, 4}) {
+		t.Errorf("GetSimilarRepos() after SetRepoList got = %v, want %v", got, []int64{3, 2, 4})
+	}
+}
+
+// getSimilarRepos_UnknownLanguages checks that the languages missing from the Linguist table are distinct languages
+// for every backend: more items than the candidates use another unknown language, and must not hide the nearest item
+func getSimilarRepos_UnknownLanguages(t *testing.T, dbService Service, testKey string) {
+
+	breakdowns := map[int64]entities.Languages{
+		1: {"Odin": 100},
+		2: {"Odin": 50, "Go": 50},
+	}
+	for id := int64(3); id <= 62; id++ {
+		breakdowns[id] = entities.Languages{"Hare": 100}
+	}
+	list := make(entities.RepoList, 0, len(breakdowns))
+	for id := int64(1); id <= 62; id++ {
+		list = append(list, entities.RepoItem{ID: id, Name: fmt.Sprintf("repo%d", id)})
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	for id, langs := range breakdowns {
+		err = dbService.SetRepoItemLanguages(context.Background(), id, langs)
+		if err != nil {
+			t.Errorf("SetRepoItemLanguages() error = %v", err)
+			return
+		}
+	}
+
+	repos, err := dbService.GetSimilarRepos(context.Background(), SimilarQuery{ID: 1, Limit: 3})
+	if err != nil {
+		t.Errorf("GetSimilarRepos() error = %v", err)
+		return
+	}
+	got := make([]int64, len(repos))
+	for i, repo := range repos {
+		got[i] = repo.Item.ID
+	}
+	if want := []int64{2, 62, 61}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSimilarRepos() got = %v, want %v", got, want)
+		return
+	}
+	if math.Abs(repos[0].LanguageSimilarity-math.Sqrt(0.5)) > 0.001 || repos[1].LanguageSimilarity != 0 {
+		t.Errorf("GetSimilarRepos() got = %+v, want language similarities of 0.707 and 0", repos)
+	}
+}
+
+// getRepoList_Quality checks the quality scores of the items with the default weights, that they follow the languages
+// breakdowns, and the quality filter and sort
+func getRepoList_Quality(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{
+			ID: 1, Name: "repo1", LicenseKey: "mit", Description: strings.Repeat("d", 40), HasIssues: true,
+			HasWiki: true, HasPages: true, HasDiscussions: true, Size: 10000,
+		},
+		{ID: 2, Name: "repo2", LicenseKey: "mit"},
+		{ID: 3, Name: "repo3", HasWiki: true},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	for id, langs := range map[int64]entities.Languages{1: {"Go": 1}} {
+		err = dbService.SetRepoItemLanguages(context.Background(), id, langs)
+		if err != nil {
+			t.Errorf("SetRepoItemLanguages() error = %v", err)
+			return
+		}
+	}
+
+	scores := func(step string, want map[int64]int) {
+		for id, score := range want {
+			item, err := dbService.GetRepoItem(context.Background(), id)
+			if err != nil {
+				t.Errorf("GetRepoItem() %s error = %v", step, err)
+				return
+			}
+			if item.Quality.Score != score {
+				t.Errorf("GetRepoItem(%d) %s quality = %+v, want a score of %d", id, step, item.Quality, score)
+			}
+		}
+	}
+	scores("before the languages of repo3", map[int64]int{1: 100, 2: 20, 3: 5})
+
+	// The breakdown has every factor, in quality.Factors order
+	item, err := dbService.GetRepoItem(context.Background(), 1)
+	if err != nil {
+		t.Errorf("GetRepoItem() error = %v", err)
+		return
+	}
+	license := entities.QualityFactor{Name: "license", Weight: 20, Value: 1, Points: 20}
+	if len(item.Quality.Factors) != len(quality.Factors) || item.Quality.Factors[0] != license {
+		t.Errorf("GetRepoItem() quality factors = %+v, want %d factors starting with %+v",
+			item.Quality.Factors, len(quality.Factors), license)
+	}
+
+	// The score follows the languages breakdown
+	err = dbService.SetRepoItemLanguages(context.Background(), 3, entities.Languages{"Go": 10})
+	if err != nil {
+		t.Errorf("SetRepoItemLanguages() error = %v", err)
+		return
+	}
+	scores("after the languages of repo3", map[int64]int{1: 100, 2: 20, 3: 25})
+
+	intPtr := func(v int) *int { return &v }
+	tests := []struct {
+		name    string
+		filters GetRepoListFilters
+		want    []int64
+	}{
+		{name: "Sort", filters: GetRepoListFilters{SortBy: SortByQuality}, want: []int64{1, 3, 2}},
+		{
+			name:    "Sort ascending",
+			filters: GetRepoListFilters{SortBy: SortByQuality, SortAscending: true},
+			want:    []int64{2, 3, 1},
+		},
+		{
+			name:    "Min quality",
+			filters: GetRepoListFilters{Quality: IntRange{Min: intPtr(21)}, SortBy: SortByQuality},
+			want:    []int64{1, 3},
+		},
+		{
+			name:    "Max quality",
+			filters: GetRepoListFilters{Quality: IntRange{Max: intPtr(20)}, SortBy: SortByQuality},
+			want:    []int64{2},
+		},
+	}
+	for _, tt := range tests {
+		page, err := dbService.GetRepoList(context.Background(), tt.filters)
+		if err != nil {
+			t.Errorf("GetRepoList() %s error = %v", tt.name, err)
+			return
+		}
+		got := make([]int64, len(page.Items))
+		for i, item := range page.Items {
+			got[i] = item.ID
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetRepoList() %s got = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// getSuggestions checks that the suggestions match the prefix and are ranked by frequency, and that the language
+// counts follow the languages breakdowns
+func getSuggestions(t *testing.T, dbService Service, testKey string) {
+
+	list := entities.RepoList{
+		{ID: 1, Name: "rust-cli", Owner: "rust-lang", Language: "Rust", LicenseName: "MIT License"},
+		{ID: 2, Name: "ruby-gem", Owner: "rubyist", Language: "Ruby", LicenseName: "MIT License"},
+		{ID: 3, Name: "go-tool", Owner: "rust-lang", Language: "Go", LicenseName: "Apache License 2.0"},
+	}
+
+	err := dbService.SetRepoList(context.Background(), list)
+	if err != nil {
+		t.Errorf("SetRepoList() error = %v", err)
+		return
+	}
+	err = dbService.SetRepoItemLanguages(context.Background(), 3, entities.Languages{"Go": 100, "Rust": 10})
+	if err != nil {
+		t.Errorf("SetRepoItemLanguages() error = %v", err)
+		return
+	}
+
+	tests := []struct {
+		field  SuggestField
+		prefix string
+		limit  int
+		want   []entities.Suggestion
+	}{
+		{
+			field: SuggestLanguage, prefix: "ru", limit: 10,
+			want: []entities.Suggestion{{Value: "Rust", Count: 2}, {Value: "Ruby", Count: 1}},
+		},
+		{
+			field: SuggestLanguage, prefix: "RU", limit: 10,
+			want: []entities.Suggestion{{Value: "Rust", Count: 2}, {Value: "Ruby", Count: 1}},
+		},
+		{field: SuggestOwner, prefix: "rust", limit: 10, want: []entities.Suggestion{{Value: "rust-lang", Count: 2}}},
+		{field: SuggestName, prefix: "r", limit: 1, want: []entities.Suggestion{{Value: "ruby-gem", Count: 1}}},
+		{
+			field: SuggestLicense, prefix: "mit", limit: 10,
+			want: []entities.Suggestion{{Value: "MIT License", Count: 2}},
+		},
+		{field: SuggestLicense, prefix: "zzz", limit: 10, want: []entities.Suggestion{}},
+	}
+
+	for _, tt := range tests {
+		got, err := dbService.GetSuggestions(context.Background(), tt.field, tt.prefix, tt.limit)
+		if err != nil {
+			t.Errorf("GetSuggestions() %s %q error = %v", tt.field, tt.prefix, err)
+			return
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("GetSuggestions() %s %q got = %v, want %v", tt.field, tt.prefix, got, tt.want)
+		}
+	}
+
+	// Languages removed from a breakdown are no longer counted for the item
+	err = dbService.SetRepoItemLanguages(context.Background(), 3, entities.Languages{"Go": 100, "Ruby": 10})
+	if err != nil {
+		t.Errorf("SetRepoItemLanguages() error = %v", err)
+		return
+	}
+	got, err := dbService.GetSuggestions(context.Background(), SuggestLanguage, "ru", 10)
+	if err != nil {
+		t.Errorf("GetSuggestions() error = %v", err)
+		return
+	}
+	want := []entities.Suggestion{{Value: "Ruby", Count: 2}, {Value: "Rust", Count: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetSuggestions() after the languages changed got = %v, want %v", got, want)
+	}
+}
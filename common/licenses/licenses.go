@@ -0,0 +1,86 @@
+package licenses
+
+import (
+	"strings"
+)
+
+// Family is a class of licenses with the same obligations for the code reusing the licensed code
+type Family string
+
+const (
+	Permissive     Family = "permissive"      // reuse with attribution (MIT, Apache-2.0, BSD, ...)
+	WeakCopyleft   Family = "weak-copyleft"   // changes to the licensed files stay open (LGPL, MPL, EPL, ...)
+	StrongCopyleft Family = "strong-copyleft" // derived works stay open (GPL, AGPL, EUPL, ...)
+	Other          Family = "other"           // a license that is not classified (including GitHub's "other")
+	None           Family = "none"            // no license
+)
+
+// Families lists the families in the order of the API
+var Families = []Family{Permissive, WeakCopyleft, StrongCopyleft, Other, None}
+
+// families maps the SPDX keys of the licenses detected by GitHub (lowercase) to their family
+var families = map[string]Family{
+	"0bsd":               Permissive,
+	"afl-3.0":            Permissive,
+	"apache-2.0":         Permissive,
+	"artistic-2.0":       Permissive,
+	"bsd-2-clause":       Permissive,
+	"bsd-3-clause":       Permissive,
+	"bsd-3-clause-clear": Permissive,
+	"bsd-4-clause":       Permissive,
+	"bsl-1.0":            Permissive,
+	"cc-by-4.0":          Permissive,
+	"cc0-1.0":            Permissive,
+	"ecl-2.0":            Permissive,
+	"isc":                Permissive,
+	"mit":                Permissive,
+	"mit-0":              Permissive,
+	"ms-pl":              Permissive,
+	"ncsa":               Permissive,
+	"postgresql":         Permissive,
+	"unlicense":          Permissive,
+	"upl-1.0":            Permissive,
+	"wtfpl":              Permissive,
+	"zlib":               Permissive,
+
+	"cc-by-sa-4.0": WeakCopyleft,
+	"cddl-1.0":     WeakCopyleft,
+	"epl-1.0":      WeakCopyleft,
+	"epl-2.0":      WeakCopyleft,
+	"lgpl-2.1":     WeakCopyleft,
+	"lgpl-3.0":     WeakCopyleft,
+	"lppl-1.3c":    WeakCopyleft,
+	"mpl-2.0":      WeakCopyleft,
+	"ms-rl":        WeakCopyleft,
+	"ofl-1.1":      WeakCopyleft,
+
+	"agpl-3.0": StrongCopyleft,
+	"eupl-1.1": StrongCopyleft,
+	"eupl-1.2": StrongCopyleft,
+	"gpl-2.0":  StrongCopyleft,
+	"gpl-3.0":  StrongCopyleft,
+	"osl-3.0":  StrongCopyleft,
+}
+
+// FamilyOf returns the family of the license with the given SPDX key (case-insensitive)
+// An empty key returns None and an unknown key returns Other.
+func FamilyOf(key string) Family {
+	key = strings.ToLower(strings.TrimSpace(key))
+	if key == "" {
+		return None
+	}
+	if family, ok := families[key]; ok {
+		return family
+	}
+	return Other
+}
+
+// IsFamily reports whether the value is one of Families
+func IsFamily(value string) bool {
+	for _, family := range Families {
+		if string(family) == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package licenses
+
+import (
+	"testing"
+)
+
+func TestFamilyOf(t *testing.T) {
+	tests := []struct {
+		key  string
+		want Family
+	}{
+		{key: "mit", want: Permissive},
+		{key: "Apache-2.0", want: Permissive},
+		{key: "bsd-3-clause", want: Permissive},
+		{key: "lgpl-3.0", want: WeakCopyleft},
+		{key: "mpl-2.0", want: WeakCopyleft},
+		{key: "gpl-3.0", want: StrongCopyleft},
+		{key: "agpl-3.0", want: StrongCopyleft},
+		{key: "other", want: Other},
+		{key: "not-a-license", want: Other},
+		{key: "", want: None},
+		{key: " ", want: None},
+	}
+	for _, tt := range tests {
+		if got := FamilyOf(tt.key); got != tt.want {
+			t.Errorf("FamilyOf(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestIsFamily(t *testing.T) {
+	for _, family := range Families {
+		if !IsFamily(string(family)) {
+			t.Errorf("IsFamily(%q) = false, want true", family)
+		}
+	}
+	if IsFamily("copyleft") {
+		t.Errorf("IsFamily(%q) = true, want false", "copyleft")
+	}
+}
@@ -0,0 +1,88 @@
+package linguist
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Type is the kind of a language, as classified by GitHub Linguist
+type Type string
+
+const (
+	Programming Type = "programming"
+	Markup      Type = "markup"
+	Data        Type = "data"
+	Prose       Type = "prose"
+)
+
+// Language is the metadata of a language
+//   - Name: the canonical name, as reported by the GitHub API (e.g. C++)
+//   - Color: the colour GitHub displays the language with (empty when it has none)
+//   - Aliases: the other names of the language, in lowercase (e.g. cpp)
+//   - Family: the language the statistics of the language are grouped with (e.g. TSX is a TypeScript), or the name
+type Language struct {
+	Name    string   `json:"name"`
+	Type    Type     `json:"type"`
+	Color   string   `json:"color"`
+	Aliases []string `json:"aliases"`
+	Family  string   `json:"family"`
+}
+
+// languagesJSON is a subset of the languages.yml of GitHub Linguist
+//
+//go:embed languages.json
+var languagesJSON []byte
+
+// byName maps the lowercase names and aliases of the languages to their metadata, and names lists the canonical
+// names in the order of the table
+var byName, names = mustLoad(languagesJSON)
+
+// mustLoad indexes the languages of the table by name and aliases. It panics if the table is not valid, which the
+// tests of this package catch before a release.
+func mustLoad(data []byte) (map[string]Language, []string) {
+	var languages []Language
+	if err := json.Unmarshal(data, &languages); err != nil {
+		panic(fmt.Sprintf("linguist: invalid language table: %v", err))
+	}
+
+	out := make(map[string]Language, 2*len(languages))
+	canonical := make([]string, 0, len(languages))
+	for _, language := range languages {
+		canonical = append(canonical, language.Name)
+		if language.Family == "" {
+			language.Family = language.Name
+		}
+		for _, name := range append([]string{language.Name}, language.Aliases...) {
+			key := strings.ToLower(name)
+			if _, ok := out[key]; ok {
+				panic(fmt.Sprintf("linguist: duplicate language name or alias %q", name))
+			}
+			out[key] = language
+		}
+	}
+	return out, canonical
+}
+
+// Lookup returns the metadata of a language from its name or one of its aliases (case-insensitive)
+func Lookup(name string) (Language, bool) {
+	language, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+	return language, ok
+}
+
+// Canonical returns the canonical name of a language from its name or one of its aliases (case-insensitive)
+// The names that are not in the table are returned trimmed, so new languages are kept as GitHub reports them.
+func Canonical(name string) string {
+	if language, ok := Lookup(name); ok {
+		return language.Name
+	}
+	return strings.TrimSpace(name)
+}
+
+// Names returns the canonical names of the languages of the table, in a stable order
+func Names() []string {
+	out := make([]string, len(names))
+	copy(out, names)
+	return out
+}
@@ -0,0 +1,82 @@
+package linguist
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "Go", want: "Go"},
+		{name: "go", want: "Go"},
+		{name: "golang", want: "Go"},
+		{name: "Gosu", want: "Gosu"},
+		{name: "cpp", want: "C++"},
+		{name: "c++", want: "C++"},
+		{name: "CSharp", want: "C#"},
+		{name: " js ", want: "JavaScript"},
+		{name: "bash", want: "Shell"},
+		{name: "Brainfuck++", want: "Brainfuck++"},
+		{name: "", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Canonical(tt.name); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	got, ok := Lookup("tsx")
+	if !ok || got.Name != "TSX" || got.Type != Programming || got.Color != "#3178c6" || got.Family != "TypeScript" {
+		t.Errorf("Lookup(tsx) = %+v, %t", got, ok)
+	}
+
+	got, ok = Lookup("markdown")
+	if !ok || got.Type != Prose || got.Family != "Markdown" {
+		t.Errorf("Lookup(markdown) = %+v, %t", got, ok)
+	}
+
+	if _, ok := Lookup("unknown"); ok {
+		t.Errorf("Lookup(unknown) found a language")
+	}
+}
+
+func TestNames(t *testing.T) {
+	got := Names()
+	if len(got) == 0 || got[0] != "ABAP" {
+		t.Fatalf("Names() = %v, want the languages of the table in order", got)
+	}
+	seen := map[string]bool{}
+	for _, name := range got {
+		if seen[name] || Canonical(name) != name {
+			t.Errorf("Names() has a duplicate or non-canonical name %q", name)
+		}
+		seen[name] = true
+	}
+
+	// The result is a copy
+	got[0] = "changed"
+	if Names()[0] != "ABAP" {
+		t.Errorf("Names() returned the table itself")
+	}
+}
+
+// TestTable checks that every language of the table has a known type and a valid colour
+func TestTable(t *testing.T) {
+	types := map[Type]bool{Programming: true, Markup: true, Data: true, Prose: true}
+	for name, language := range byName {
+		if !types[language.Type] {
+			t.Errorf("%s has an unknown type %q", name, language.Type)
+		}
+		if language.Color != "" && (len(language.Color) != 7 || language.Color[0] != '#') {
+			t.Errorf("%s has an invalid colour %q", name, language.Color)
+		}
+		if _, ok := byName[strings.ToLower(language.Family)]; !ok {
+			t.Errorf("%s has an unknown family %q", name, language.Family)
+		}
+	}
+}
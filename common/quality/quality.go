@@ -0,0 +1,194 @@
+package quality
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/pkg/errors"
+)
+
+// Factor is a signal of the quality (or completeness) of a repository
+type Factor string
+
+const (
+	License     Factor = "license"     // the repository has a license
+	Description Factor = "description" // the repository has a description, of at least fullDescriptionLength characters
+	Issues      Factor = "issues"      // the issues are enabled
+	Wiki        Factor = "wiki"        // the wiki is enabled
+	Pages       Factor = "pages"       // the repository has a GitHub Pages site
+	Discussions Factor = "discussions" // the discussions are enabled
+	Languages   Factor = "languages"   // the repository has a languages breakdown (it has code)
+	Size        Factor = "size"        // the size of the repository, on a log scale up to fullSizeKB
+)
+
+// Factors lists the factors in the order of the breakdowns
+var Factors = []Factor{License, Description, Issues, Wiki, Pages, Discussions, Languages, Size}
+
+// fullDescriptionLength is the number of characters from which a description gets the full value
+const fullDescriptionLength = 40
+
+// fullSizeKB is the size in KB from which a repository gets the full value of the size factor
+const fullSizeKB = 10000
+
+// Weights maps the factors to their weight in the score
+// The weights are relative: each factor gets its share of the sum of the weights of 100 points.
+type Weights map[Factor]float64
+
+// DefaultWeights are the weights used when none are configured
+var DefaultWeights = Weights{
+	License:     20,
+	Description: 15,
+	Issues:      10,
+	Wiki:        5,
+	Pages:       5,
+	Discussions: 5,
+	Languages:   20,
+	Size:        20,
+}
+
+// Validate checks that the weights are of known factors, are not negative, and do not add up to 0
+func (w Weights) Validate() error {
+	var total float64
+	for factor, weight := range w {
+		if !isFactor(factor) {
+			return errors.Errorf("unknown quality factor %q", factor)
+		}
+		if !(weight >= 0) || math.IsInf(weight, 1) {
+			return errors.Errorf("the weight of %s must be a non-negative number, got %g", factor, weight)
+		}
+		total += weight
+	}
+	if total == 0 {
+		return errors.New("the quality weights add up to 0")
+	}
+	return nil
+}
+
+// String returns the weights in the format of ParseWeights, in Factors order
+func (w Weights) String() string {
+	out := make([]string, 0, len(w))
+	for _, factor := range Factors {
+		if weight, ok := w[factor]; ok {
+			out = append(out, string(factor)+"="+strconv.FormatFloat(weight, 'f', -1, 64))
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// ParseWeights parses a comma separated list of factor=weight pairs (e.g. "license=30,size=0")
+// The factors that are not listed keep their DefaultWeights value, so an empty string returns the default weights.
+func ParseWeights(in string) (Weights, error) {
+	out := make(Weights, len(DefaultWeights))
+	for factor, weight := range DefaultWeights {
+		out[factor] = weight
+	}
+
+	for _, pair := range strings.Split(in, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid quality weight %q, expected factor=weight", pair)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid quality weight %q, expected factor=weight", pair)
+		}
+		out[Factor(strings.ToLower(strings.TrimSpace(key)))] = weight
+	}
+
+	if err := out.Validate(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Compute returns the quality score of an item and its breakdown by factor
+// The score is the sum of the points of the factors: each factor scores its share of 100 points (see Weights)
+// times the value of the item for the factor. The weights must be valid (see Weights.Validate).
+func Compute(item entities.RepoItem, weights Weights) entities.Quality {
+	var total float64
+	for _, factor := range Factors {
+		total += weights[factor]
+	}
+
+	out := entities.Quality{Factors: make([]entities.QualityFactor, 0, len(Factors))}
+	var points float64
+	for _, factor := range Factors {
+		var weight float64
+		if total > 0 {
+			weight = 100 * weights[factor] / total
+		}
+		value := Value(item, factor)
+		points += weight * value
+		out.Factors = append(
+			out.Factors, entities.QualityFactor{
+				Name:   string(factor),
+				Weight: round(weight),
+				Value:  round(value),
+				Points: round(weight * value),
+			},
+		)
+	}
+	out.Score = int(math.Round(points))
+	return out
+}
+
+// Value returns how well an item meets a factor, from 0 to 1
+func Value(item entities.RepoItem, factor Factor) float64 {
+	switch factor {
+	case License:
+		return boolValue(item.LicenseKey != "" || item.LicenseName != "")
+	case Description:
+		length := utf8.RuneCountInString(strings.TrimSpace(item.Description))
+		return math.Min(float64(length)/fullDescriptionLength, 1)
+	case Issues:
+		return boolValue(item.HasIssues)
+	case Wiki:
+		return boolValue(item.HasWiki)
+	case Pages:
+		return boolValue(item.HasPages)
+	case Discussions:
+		return boolValue(item.HasDiscussions)
+	case Languages:
+		var bytes int64
+		for _, b := range item.Languages {
+			bytes += b
+		}
+		return boolValue(bytes > 0)
+	case Size:
+		if item.Size <= 0 {
+			return 0
+		}
+		return math.Min(math.Log10(float64(item.Size)+1)/math.Log10(fullSizeKB+1), 1)
+	}
+	return 0
+}
+
+// isFactor reports whether the factor is one of Factors
+func isFactor(factor Factor) bool {
+	for _, f := range Factors {
+		if f == factor {
+			return true
+		}
+	}
+	return false
+}
+
+// boolValue returns 1 for true and 0 for false
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// round rounds the values of the breakdowns to 2 decimals
+func round(x float64) float64 {
+	return math.Round(x*100) / 100
+}
@@ -0,0 +1,89 @@
+package quality
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+)
+
+func TestCompute(t *testing.T) {
+	complete := entities.RepoItem{
+		LicenseKey:     "mit",
+		Description:    "A description long enough to get the full value",
+		HasIssues:      true,
+		HasWiki:        true,
+		HasPages:       true,
+		HasDiscussions: true,
+		Languages:      entities.Languages{"Go": 100},
+		Size:           20000,
+	}
+	tests := []struct {
+		name    string
+		item    entities.RepoItem
+		weights Weights
+		want    int
+	}{
+		{name: "Empty", item: entities.RepoItem{}, weights: DefaultWeights, want: 0},
+		{name: "Complete", item: complete, weights: DefaultWeights, want: 100},
+		{name: "License only", item: entities.RepoItem{LicenseKey: "mit"}, weights: DefaultWeights, want: 20},
+		{
+			name: "Half description and size", item: entities.RepoItem{Description: "twenty characters...", Size: 100},
+			weights: DefaultWeights, want: 18, // 15 * 0.5 + 20 * log10(101) / log10(10001)
+		},
+		{
+			name: "Custom weights", item: entities.RepoItem{LicenseKey: "mit", HasWiki: true},
+			weights: Weights{License: 1, Wiki: 3}, want: 100,
+		},
+		{
+			name: "Custom weights partial", item: entities.RepoItem{LicenseKey: "mit"},
+			weights: Weights{License: 1, Wiki: 3}, want: 25,
+		},
+	}
+	for _, tt := range tests {
+		got := Compute(tt.item, tt.weights)
+		if got.Score != tt.want {
+			t.Errorf("Compute() %s score = %d, want %d (%+v)", tt.name, got.Score, tt.want, got.Factors)
+		}
+		if len(got.Factors) != len(Factors) {
+			t.Errorf("Compute() %s has %d factors, want %d", tt.name, len(got.Factors), len(Factors))
+			continue
+		}
+		var weights float64
+		for i, factor := range got.Factors {
+			if factor.Name != string(Factors[i]) {
+				t.Errorf("Compute() %s factor %d = %q, want %q", tt.name, i, factor.Name, Factors[i])
+			}
+			weights += factor.Weight
+		}
+		if weights < 99.9 || weights > 100.1 {
+			t.Errorf("Compute() %s weights add up to %g, want 100", tt.name, weights)
+		}
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	got, err := ParseWeights("")
+	if err != nil || got.String() != DefaultWeights.String() {
+		t.Errorf("ParseWeights(\"\") = %v, %v, want the default weights", got, err)
+	}
+
+	got, err = ParseWeights(" License=30 , size=0")
+	if err != nil || got[License] != 30 || got[Size] != 0 || got[Description] != DefaultWeights[Description] {
+		t.Errorf("ParseWeights() = %v, %v", got, err)
+	}
+
+	for _, in := range []string{"colour=1", "license", "license=abc", "license=-1", "license=NaN", zeroWeights()} {
+		if _, err := ParseWeights(in); err == nil {
+			t.Errorf("ParseWeights(%q) error = nil, want an error", in)
+		}
+	}
+}
+
+// zeroWeights returns the weights setting every factor to 0
+func zeroWeights() string {
+	out := ""
+	for _, factor := range Factors {
+		out += string(factor) + "=0,"
+	}
+	return out
+}
@@ -0,0 +1,136 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The types in this file represent the abstract syntax tree (AST) of a parsed search query.
+// Backends walk the tree to evaluate it (memory) or to compile it into their own query language (redis).
+
+// Expr is a node of the AST
+type Expr interface {
+	// String returns the canonical form of the expression (suitable as a cache key)
+	String() string
+	expr()
+}
+
+// AndExpr matches when all the operands match
+type AndExpr struct {
+	Operands []Expr
+}
+
+// OrExpr matches when any of the operands match
+type OrExpr struct {
+	Operands []Expr
+}
+
+// NotExpr matches when the operand does not match
+type NotExpr struct {
+	Operand Expr
+}
+
+// MatchExpr matches a text, tag or bool field against a value
+//   - text fields match when the field contains the value (case-insensitive)
+//   - tag fields match when any tag equals the value (case-insensitive)
+//   - bool fields match when the field equals the value ("true" or "false")
+type MatchExpr struct {
+	Field Field
+	Value string
+}
+
+// RangeExpr matches a number or date field against a range. A nil bound is unbounded.
+// Dates are represented as unix timestamps (seconds).
+type RangeExpr struct {
+	Field        Field
+	Min          *float64
+	Max          *float64
+	MinExclusive bool
+	MaxExclusive bool
+}
+
+func (AndExpr) expr()   {}
+func (OrExpr) expr()    {}
+func (NotExpr) expr()   {}
+func (MatchExpr) expr() {}
+func (RangeExpr) expr() {}
+
+func (e AndExpr) String() string { return "(" + joinExprs(e.Operands, " ") + ")" }
+func (e OrExpr) String() string  { return "(" + joinExprs(e.Operands, " OR ") + ")" }
+func (e NotExpr) String() string { return "-" + e.Operand.String() }
+func (e MatchExpr) String() string {
+	return fmt.Sprintf("%s:%s", e.Field.Name, strconv.Quote(e.Value))
+}
+func (e RangeExpr) String() string {
+	min, max := "*", "*"
+	if e.Min != nil {
+		min = strconv.FormatFloat(*e.Min, 'f', -1, 64)
+	}
+	if e.Max != nil {
+		max = strconv.FormatFloat(*e.Max, 'f', -1, 64)
+	}
+	open, closed := "[", "]"
+	if e.MinExclusive {
+		open = "("
+	}
+	if e.MaxExclusive {
+		closed = ")"
+	}
+	return fmt.Sprintf("%s:%s%s..%s%s", e.Field.Name, open, min, max, closed)
+}
+
+func joinExprs(exprs []Expr, sep string) string {
+	strs := make([]string, len(exprs))
+	for i, e := range exprs {
+		strs[i] = e.String()
+	}
+	return strings.Join(strs, sep)
+}
+
+// Kind is the type of a searchable field. It determines the values and operators a field accepts.
+type Kind int
+
+const (
+	KindText Kind = iota
+	KindTag
+	KindBool
+	KindNumber
+	KindDate
+)
+
+// Field is a searchable field
+type Field struct {
+	Name string
+	Kind Kind
+}
+
+// The searchable fields
+var (
+	FieldName         = Field{Name: "name", Kind: KindText}
+	FieldLicense      = Field{Name: "license", Kind: KindTag}
+	FieldLicenseName  = Field{Name: "license_name", Kind: KindText}
+	FieldLanguage     = Field{Name: "language", Kind: KindTag}
+	FieldAllowForking = Field{Name: "allow_forking", Kind: KindBool}
+	FieldForks        = Field{Name: "forks", Kind: KindNumber}
+	FieldWatchers     = Field{Name: "watchers", Kind: KindNumber}
+	FieldSize         = Field{Name: "size", Kind: KindNumber}
+	FieldOpenIssues   = Field{Name: "open_issues", Kind: KindNumber}
+	FieldCreated      = Field{Name: "created", Kind: KindDate}
+	FieldUpdated      = Field{Name: "updated", Kind: KindDate}
+)
+
+// DefaultField is the field matched by values without a field prefix
+var DefaultField = FieldName
+
+// Fields maps the name of each searchable field to the field
+var Fields = map[string]Field{}
+
+func init() {
+	for _, f := range []Field{
+		FieldName, FieldLicense, FieldLicenseName, FieldLanguage, FieldAllowForking, FieldForks, FieldWatchers,
+		FieldSize, FieldOpenIssues, FieldCreated, FieldUpdated,
+	} {
+		Fields[f.Name] = f
+	}
+}
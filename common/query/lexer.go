@@ -0,0 +1,88 @@
+package query
+
+import (
+	"strings"
+)
+
+// tokenKind is the type of a lexical token
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokWord
+	tokQuoted
+	tokLParen
+	tokRParen
+	tokMinus
+	tokOr
+)
+
+// token is a lexical token of a query
+//   - text: the text of the token (unquoted and unescaped for tokQuoted)
+//   - pos, end: the byte offsets of the token in the query [pos, end)
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+	end  int
+}
+
+// lex splits the query into tokens. The last token is always tokEOF.
+func lex(in string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for i < len(in) {
+		c := in[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, text: "(", pos: i, end: i + 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, text: ")", pos: i, end: i + 1})
+			i++
+		case c == '-':
+			tokens = append(tokens, token{kind: tokMinus, text: "-", pos: i, end: i + 1})
+			i++
+		case c == '"':
+			tok, err := lexQuoted(in, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = tok.end
+		default:
+			start := i
+			for i < len(in) && !strings.ContainsRune(" \t\n\r()\"", rune(in[i])) {
+				i++
+			}
+			tok := token{kind: tokWord, text: in[start:i], pos: start, end: i}
+			if tok.text == "OR" {
+				tok.kind = tokOr
+			}
+			tokens = append(tokens, tok)
+		}
+	}
+	return append(tokens, token{kind: tokEOF, pos: len(in), end: len(in)}), nil
+}
+
+// lexQuoted reads a quoted string starting at in[start] == '"'
+// A backslash escapes the next character (\" and \\)
+func lexQuoted(in string, start int) (token, error) {
+	var sb strings.Builder
+	for i := start + 1; i < len(in); i++ {
+		switch in[i] {
+		case '\\':
+			if i+1 < len(in) {
+				i++
+				sb.WriteByte(in[i])
+			}
+		case '"':
+			return token{kind: tokQuoted, text: sb.String(), pos: start, end: i + 1}, nil
+		default:
+			sb.WriteByte(in[i])
+		}
+	}
+	return token{}, newSyntaxError(start, "unterminated quoted string")
+}
@@ -0,0 +1,322 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/linguist"
+)
+
+// The grammar of a query (GitHub search style)
+//
+//	query   = or
+//	or      = and { "OR" and }
+//	and     = unary { unary }               (terms separated by whitespace are ANDed)
+//	unary   = "-" unary | primary
+//	primary = "(" or ")" | term | quoted
+//	term    = field ":" value | word        (a word without a field matches the DefaultField)
+//	value   = word | quoted | comparison | range
+//	comparison = (">" | ">=" | "<" | "<=") bound
+//	range   = bound ".." bound              (use * for an unbounded side)
+//
+// Number and date fields accept comparisons and ranges. Dates are YYYY-MM-DD (the whole day) or RFC3339.
+
+// SyntaxError is returned when a query cannot be parsed
+//   - Position: the byte offset in the query where the error was detected
+type SyntaxError struct {
+	Position int
+	Message  string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at position %d: %s", e.Position, e.Message)
+}
+
+func newSyntaxError(pos int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{Position: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// Parse parses a query into an AST
+// It returns a nil Expr for an empty query, and a *SyntaxError when the query is invalid
+func Parse(in string) (Expr, error) {
+	tokens, err := lex(in)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser{tokens: tokens}
+	if p.peek().kind == tokEOF {
+		return nil, nil
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, newSyntaxError(tok.pos, "unexpected %q", tok.text)
+	}
+	return expr, nil
+}
+
+// parser is a recursive descent parser over the tokens of a query
+type parser struct {
+	tokens []token
+	i      int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	var operands []Expr
+	for {
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+
+		if p.peek().kind != tokOr {
+			break
+		}
+		p.next()
+	}
+
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return OrExpr{Operands: operands}, nil
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	var operands []Expr
+	for {
+		switch p.peek().kind {
+		case tokEOF, tokRParen, tokOr:
+			if len(operands) == 0 {
+				tok := p.peek()
+				if tok.kind == tokEOF {
+					return nil, newSyntaxError(tok.pos, "expected a search term")
+				}
+				return nil, newSyntaxError(tok.pos, "expected a search term before %q", tok.text)
+			}
+			if len(operands) == 1 {
+				return operands[0], nil
+			}
+			return AndExpr{Operands: operands}, nil
+		}
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	if p.peek().kind == tokMinus {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NotExpr{Operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokLParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokRParen {
+			return nil, newSyntaxError(tok.pos, "missing closing parenthesis")
+		}
+		p.next()
+		return expr, nil
+	case tokQuoted:
+		return MatchExpr{Field: DefaultField, Value: tok.text}, nil
+	case tokWord:
+		return p.parseTerm(tok)
+	case tokEOF:
+		return nil, newSyntaxError(tok.pos, "expected a search term")
+	}
+	return nil, newSyntaxError(tok.pos, "unexpected %q", tok.text)
+}
+
+// parseTerm parses a word token into a field:value term, or a match on the DefaultField
+func (p *parser) parseTerm(tok token) (Expr, error) {
+	idx := strings.IndexByte(tok.text, ':')
+	if idx < 0 {
+		return MatchExpr{Field: DefaultField, Value: tok.text}, nil
+	}
+	if idx == 0 {
+		return nil, newSyntaxError(tok.pos, "missing field name")
+	}
+
+	field, ok := Fields[strings.ToLower(tok.text[:idx])]
+	if !ok {
+		return nil, newSyntaxError(tok.pos, "unknown field %q", tok.text[:idx])
+	}
+
+	value, valuePos := tok.text[idx+1:], tok.pos+idx+1
+	if value == "" {
+		// The value may be a quoted string immediately following the colon
+		if next := p.peek(); next.kind == tokQuoted && next.pos == tok.end {
+			p.next()
+			value = next.text
+		}
+		if value == "" {
+			return nil, newSyntaxError(valuePos, "missing value for field %q", field.Name)
+		}
+	}
+
+	switch field.Kind {
+	case KindBool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return nil, newSyntaxError(valuePos, "field %q expects true or false, got %q", field.Name, value)
+		}
+		return MatchExpr{Field: field, Value: strings.ToLower(value)}, nil
+	case KindNumber, KindDate:
+		return parseRange(field, value, valuePos)
+	}
+	switch field {
+	case FieldLanguage:
+		// The aliases match the same language as the language filter (e.g. language:cpp is language:C++)
+		value = linguist.Canonical(value)
+	case FieldLicense:
+		// The license keys are lowercase, as for the license filter
+		value = strings.ToLower(value)
+	}
+	return MatchExpr{Field: field, Value: value}, nil
+}
+
+// parseRange parses the value of a number or date field into a RangeExpr
+func parseRange(field Field, value string, pos int) (Expr, error) {
+	out := RangeExpr{Field: field}
+
+	// Comparisons
+	for _, op := range []string{">=", "<=", ">", "<"} {
+		if !strings.HasPrefix(value, op) {
+			continue
+		}
+		b, err := parseBound(field, value[len(op):], pos+len(op))
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case ">=":
+			out.Min = &b.lo
+		case ">":
+			// After the whole day for a date, otherwise strictly greater
+			if b.span {
+				out.Min = &b.hi
+			} else {
+				out.Min, out.MinExclusive = &b.lo, true
+			}
+		case "<=":
+			// Up to the end of the whole day for a date, otherwise less or equal
+			if b.span {
+				out.Max, out.MaxExclusive = &b.hi, true
+			} else {
+				out.Max = &b.lo
+			}
+		case "<":
+			out.Max, out.MaxExclusive = &b.lo, true
+		}
+		return out, nil
+	}
+
+	// Ranges
+	if idx := strings.Index(value, ".."); idx >= 0 {
+		loStr, hiStr := value[:idx], value[idx+2:]
+		if (loStr == "*" || loStr == "") && (hiStr == "*" || hiStr == "") {
+			return nil, newSyntaxError(pos, "range for field %q needs at least one bound", field.Name)
+		}
+		if loStr != "*" {
+			lo, err := parseBound(field, loStr, pos)
+			if err != nil {
+				return nil, err
+			}
+			out.Min = &lo.lo
+		}
+		if hiStr != "*" {
+			hi, err := parseBound(field, hiStr, pos+idx+2)
+			if err != nil {
+				return nil, err
+			}
+			if hi.span {
+				out.Max, out.MaxExclusive = &hi.hi, true
+			} else {
+				out.Max = &hi.lo
+			}
+		}
+		return out, nil
+	}
+
+	// Equality
+	b, err := parseBound(field, value, pos)
+	if err != nil {
+		return nil, err
+	}
+	out.Min = &b.lo
+	if b.span {
+		out.Max, out.MaxExclusive = &b.hi, true
+	} else {
+		out.Max = &b.lo
+	}
+	return out, nil
+}
+
+// bound is a parsed number or date
+//   - lo: the value (the start of the day for a date without a time)
+//   - hi: the end of the day (exclusive) for a date without a time
+//   - span: the bound is a whole day, [lo, hi)
+type bound struct {
+	lo   float64
+	hi   float64
+	span bool
+}
+
+// parseBound parses a number or a date (as a unix timestamp)
+// Numbers must be finite: NaN, Inf and out of range values are rejected.
+func parseBound(field Field, value string, pos int) (bound, error) {
+	if field.Kind == KindNumber {
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			return bound{}, newSyntaxError(pos, "field %q expects a number, got %q", field.Name, value)
+		}
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return bound{}, newSyntaxError(pos, "field %q expects a finite number, got %q", field.Name, value)
+		}
+		return bound{lo: v, hi: v}, nil
+	}
+
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return bound{lo: float64(t.Unix()), hi: float64(t.AddDate(0, 0, 1).Unix()), span: true}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return bound{lo: float64(t.Unix()), hi: float64(t.Unix())}, nil
+	}
+	return bound{}, newSyntaxError(pos, "field %q expects a date (YYYY-MM-DD or RFC3339), got %q", field.Name, value)
+}
@@ -0,0 +1,117 @@
+package query
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestParse checks the canonical form of parsed queries
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "Empty", query: "   ", want: ""},
+		{name: "Bare word", query: "cli", want: `name:"cli"`},
+		{name: "Quoted phrase", query: `"hello world"`, want: `name:"hello world"`},
+		{name: "Field value", query: "language:go", want: `language:"Go"`},
+		{name: "Field quoted", query: `license_name:"apache license"`, want: `license_name:"apache license"`},
+		{name: "License key", query: "license:GPL-3.0", want: `license:"gpl-3.0"`},
+		{name: "Field case-insensitive", query: "Language:go", want: `language:"Go"`},
+		{name: "Language alias", query: "language:cpp", want: `language:"C++"`},
+		{name: "Unknown language", query: "language:Zig2", want: `language:"Zig2"`},
+		{name: "Implicit AND", query: "language:go license:mit", want: `(language:"Go" license:"mit")`},
+		{name: "OR", query: "language:go OR language:rust", want: `(language:"Go" OR language:"Rust")`},
+		{
+			name:  "AND binds tighter than OR",
+			query: "a b OR c",
+			want:  `((name:"a" name:"b") OR name:"c")`,
+		},
+		{name: "Parentheses", query: "a (b OR c)", want: `(name:"a" (name:"b" OR name:"c"))`},
+		{name: "Negation", query: "-license:mit", want: `-license:"mit"`},
+		{name: "Negated group", query: "-(a OR b)", want: `-(name:"a" OR name:"b")`},
+		{name: "Bool", query: "allow_forking:TRUE", want: `allow_forking:"true"`},
+		{name: "Greater than", query: "forks:>3", want: "forks:(3..*]"},
+		{name: "Greater or equal", query: "forks:>=3", want: "forks:[3..*]"},
+		{name: "Less than", query: "size:<100", want: "size:[*..100)"},
+		{name: "Less or equal", query: "size:<=100", want: "size:[*..100]"},
+		{name: "Number equality", query: "open_issues:0", want: "open_issues:[0..0]"},
+		{name: "Number range", query: "watchers:10..20", want: "watchers:[10..20]"},
+		{name: "Open range", query: "watchers:10..*", want: "watchers:[10..*]"},
+		{name: "Date day", query: "created:2024-01-01", want: "created:[1704067200..1704153600)"},
+		{name: "Date after day", query: "created:>2024-01-01", want: "created:[1704153600..*]"},
+		{name: "Date up to day", query: "created:<=2024-01-01", want: "created:[*..1704153600)"},
+		{
+			name:  "Date range",
+			query: "updated:2024-01-01..2024-01-02",
+			want:  "updated:[1704067200..1704240000)",
+		},
+		{name: "Date time", query: "created:>=2024-01-01T12:00:00Z", want: "created:[1704110400..*]"},
+		{
+			name:  "Everything",
+			query: `language:go forks:>3 -license:mit`,
+			want:  `(language:"Go" forks:(3..*] -license:"mit")`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				got, err := Parse(tt.query)
+				if err != nil {
+					t.Errorf("Parse() error = %v", err)
+					return
+				}
+				gotStr := ""
+				if got != nil {
+					gotStr = got.String()
+				}
+				if gotStr != tt.want {
+					t.Errorf("Parse()\ngot =  %s\nwant = %s", gotStr, tt.want)
+				}
+			},
+		)
+	}
+}
+
+// TestParse_Errors checks the position of syntax errors
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		position int
+	}{
+		{name: "Unknown field", query: "language:go colour:red", position: 12},
+		{name: "Missing value", query: "language:", position: 9},
+		{name: "Missing field", query: ":go", position: 0},
+		{name: "Unterminated quote", query: `name:"abc`, position: 5},
+		{name: "Bad number", query: "forks:>abc", position: 7},
+		{name: "NaN", query: "forks:NaN", position: 6},
+		{name: "Infinite lower bound", query: "forks:>Inf", position: 7},
+		{name: "Infinite range bound", query: "size:1..-inf", position: 8},
+		{name: "Out of range number", query: "watchers:<=1e999", position: 11},
+		{name: "Bad date", query: "created:yesterday", position: 8},
+		{name: "Bad bool", query: "allow_forking:maybe", position: 14},
+		{name: "Unbounded range", query: "size:*..*", position: 5},
+		{name: "Missing closing parenthesis", query: "a (b OR c", position: 2},
+		{name: "Unexpected closing parenthesis", query: "a)", position: 1},
+		{name: "Trailing OR", query: "a OR", position: 4},
+		{name: "Leading OR", query: "OR a", position: 0},
+		{name: "Dangling negation", query: "a -", position: 3},
+	}
+	for _, tt := range tests {
+		t.Run(
+			tt.name, func(t *testing.T) {
+				_, err := Parse(tt.query)
+				var syntaxErr *SyntaxError
+				if !errors.As(err, &syntaxErr) {
+					t.Errorf("Parse() error = %v, want a *SyntaxError", err)
+					return
+				}
+				if syntaxErr.Position != tt.position {
+					t.Errorf("Parse() error %q position = %d, want %d", syntaxErr, syntaxErr.Position, tt.position)
+				}
+			},
+		)
+	}
+}
@@ -14,6 +14,10 @@ type Config struct {
 	UseFetcher          string  `envconfig:"USE_FETCHER" default:"mock"`
 	FetchTimeoutSeconds float32 `envconfig:"FETCH_TIMEOUT_SECONDS" default:"4"`
 
+	// QualityWeights are the weights of the quality score factors, as factor=weight pairs (see quality.ParseWeights)
+	// The factors that are not listed keep their default weight.
+	QualityWeights string `envconfig:"QUALITY_WEIGHTS" default:""`
+
 	// RateLimiting
 	SleepoverDurationSeconds int `envconfig:"SLEEPOVER_DURATION_SECONDS" default:"4"`
 
@@ -1,6 +1,11 @@
 package fetcher
 
-import "github.com/Scalingo/sclng-backend-test-v1/common/entities"
+import (
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/linguist"
+)
 
 // Conversions
 //  Conversion E2I represents the conversion of types from entities to interfaces layers.
@@ -26,9 +31,10 @@ func ConvertLatest100ItemI2E(i Repo) entities.RepoItem {
 		CreatedAt:       i.CreatedAt,
 		UpdatedAt:       i.UpdatedAt,
 		Size:            i.Size,
-		Language:        i.Language,
+		Language:        linguist.Canonical(i.Language),
 		Languages:       nil,
 		LicenseName:     i.License.Name,
+		LicenseKey:      strings.ToLower(i.License.Key),
 		ForksCount:      i.ForksCount,
 		OpenIssuesCount: i.OpenIssuesCount,
 		WatchersCount:   i.WatchersCount,
@@ -42,10 +48,12 @@ func ConvertLatest100ItemI2E(i Repo) entities.RepoItem {
 	}
 }
 
+// ConvertLanguagesI2E converts a languages breakdown, with the canonical names of the languages
+// The bytes of the names that are aliases of the same language are added up.
 func ConvertLanguagesI2E(i Languages) entities.Languages {
 	out := entities.Languages{}
 	for lang, count := range i {
-		out[lang] = count
+		out[linguist.Canonical(lang)] += count
 	}
 	return out
 }
@@ -0,0 +1,23 @@
+package fetcher
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+)
+
+func TestConvertLanguagesI2E(t *testing.T) {
+	got := ConvertLanguagesI2E(Languages{"C++": 100, "cpp": 20, "golang": 5, "Brainfuck++": 1})
+	want := entities.Languages{"C++": 120, "Go": 5, "Brainfuck++": 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ConvertLanguagesI2E() = %v, want %v", got, want)
+	}
+}
+
+func TestConvertLatest100ItemI2E(t *testing.T) {
+	got := ConvertLatest100ItemI2E(Repo{Language: "cpp", License: License{Key: "MIT", Name: "MIT License"}})
+	if got.Language != "C++" || got.LicenseKey != "mit" || got.LicenseName != "MIT License" {
+		t.Errorf("ConvertLatest100ItemI2E() = %+v", got)
+	}
+}
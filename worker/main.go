@@ -11,6 +11,7 @@ import (
 
 	"github.com/Scalingo/go-utils/logger"
 	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db/dbRedis"
+	"github.com/Scalingo/sclng-backend-test-v1/common/quality"
 	"github.com/Scalingo/sclng-backend-test-v1/worker/config"
 	"github.com/Scalingo/sclng-backend-test-v1/worker/interfaces/fetcher"
 	fetcherLive "github.com/Scalingo/sclng-backend-test-v1/worker/interfaces/fetcher/live"
@@ -78,6 +79,14 @@ func startup(ctx context.Context, wg *sync.WaitGroup, log logrus.FieldLogger, co
 	if err != nil {
 		return fmt.Errorf("error creating new redis service: %w", err)
 	}
+	qualityWeights, err := quality.ParseWeights(config.QualityWeights)
+	if err != nil {
+		return fmt.Errorf("error parsing QUALITY_WEIGHTS: %w", err)
+	}
+	if err = dbService.SetQualityWeights(qualityWeights); err != nil {
+		return fmt.Errorf("error setting the quality weights: %w", err)
+	}
+	log.WithField("weights", qualityWeights.String()).Info("configured quality weights")
 	if err = dbService.Start(ctx, wg); err != nil {
 		return fmt.Errorf("error starting redis service: %w", err)
 	}
@@ -3,8 +3,10 @@ package standard
 import (
 	"context"
 	"runtime"
+	"time"
 
 	"github.com/Scalingo/sclng-backend-test-v1/common/entities"
+	"github.com/Scalingo/sclng-backend-test-v1/common/interfaces/db"
 	"github.com/Scalingo/sclng-backend-test-v1/worker/interfaces/fetcher"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -43,6 +45,7 @@ func (s *Standard) doWork(ctx context.Context) error {
 	if err != nil {
 		s.log.Errorf("error fetching repoList: %v", err)
 	}
+	cycleOK := err == nil
 
 	// **********************************************************************
 	// 2. Fetch the languages for each repository
@@ -113,16 +116,42 @@ func (s *Standard) doWork(ctx context.Context) error {
 	}
 
 	// Wait for all fetches to complete
+	// A failed fetch leaves the languages of its repository from the previous cycle, so the cycle is not complete
+	failedLanguages := 0
 	for i := 0; i < len(repoList); i++ {
 		select {
-		case _ = <-errs:
-			//if err != nil {
-			//	s.log.Errorf("error fetching languages: %v", err)
-			//}
+		case err := <-errs:
+			if err != nil {
+				failedLanguages++
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
+	if failedLanguages > 0 {
+		s.log.Warnf("could not store the languages of %d repositories", failedLanguages)
+		cycleOK = false
+	}
+
+	// **********************************************************************
+	// 3. Record a snapshot of the stats in the stats history
+	//    - Only after a successful cycle (the repoList and every languages breakdown stored), so the history has no
+	//      point computed from a partially updated dataset
+	// **********************************************************************
+
+	if !cycleOK {
+		s.log.Warn("skipped the stats snapshot of an incomplete cycle")
+	} else {
+		stats, err := db.CollectStats(ctx, s.db, db.GetRepoListFilters{})
+		if err == nil {
+			err = s.db.RecordStatsSnapshot(ctx, time.Now(), stats)
+		}
+		if err != nil {
+			s.log.Errorf("error recording the stats snapshot: %v", err)
+		} else {
+			s.log.Info("recorded stats snapshot")
+		}
+	}
 
 	s.log.Info("Work complete")
 